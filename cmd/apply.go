@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/reconcile"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+const (
+	applyFlagFile   = "file"
+	applyFlagActor  = "actor"
+	applyFlagDryRun = "apply-dry-run"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "reconcile v2 roles and role-bindings with a YAML document",
+	Long: `Reconciles the v2 roles and role-bindings of the resources listed in a YAML document.
+
+Roles and role-bindings in the document which do not exist are created, those which differ
+are updated, and those on the listed resources with the document's manager which are not in
+the document are deleted. Roles and role-bindings with other managers are left untouched.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		apply(cmd.Context(), globalCfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	flags := applyCmd.Flags()
+	flags.StringP(applyFlagFile, "f", "", "path to the YAML document to apply")
+	flags.String(applyFlagActor, "", "subject ID recorded as the creator and updater of changes")
+	flags.Bool("dry-run", false, "dry run: print the changes instead of applying them")
+
+	v := viper.GetViper()
+
+	viperx.MustBindFlag(v, applyFlagFile, flags.Lookup(applyFlagFile))
+	viperx.MustBindFlag(v, applyFlagActor, flags.Lookup(applyFlagActor))
+	viperx.MustBindFlag(v, applyFlagDryRun, flags.Lookup("dry-run"))
+}
+
+func apply(ctx context.Context, cfg *config.AppConfig) {
+	path := viper.GetString(applyFlagFile)
+	actorIDStr := viper.GetString(applyFlagActor)
+	dryRun := viper.GetBool(applyFlagDryRun)
+
+	if path == "" || actorIDStr == "" {
+		logger.Fatal("invalid config: --file and --actor are required")
+	}
+
+	doc, err := reconcile.LoadFile(path)
+	if err != nil {
+		logger.Fatalw("unable to load document", "file", path, "error", err)
+	}
+
+	actorID, err := gidx.Parse(actorIDStr)
+	if err != nil {
+		logger.Fatalw("error parsing actor ID", "error", err)
+	}
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := newDBFromConfig(cfg)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	actor, err := engine.NewResourceFromID(actorID)
+	if err != nil {
+		logger.Fatalw("error creating actor resource", "error", err)
+	}
+
+	reconciler := reconcile.New(engine, reconcile.WithLogger(logger), reconcile.WithDryRun(dryRun))
+
+	changes, err := reconciler.Apply(ctx, actor, doc)
+	if err != nil {
+		logger.Fatalw("error applying document", "manager", doc.Manager, "changes", len(changes), "error", err)
+	}
+
+	if dryRun {
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+
+		return
+	}
+
+	logger.Infof("applied %d changes for manager %s", len(changes), doc.Manager)
+}
@@ -97,7 +97,12 @@ func createRole(ctx context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("error parsing subject ID", "error", err)
 	}
 
-	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	engine, err := query.NewEngine(
+		"infratographer", spiceClient, store,
+		query.WithPolicy(policy),
+		query.WithLogger(logger),
+		query.WithoutRoleBindingApprovals(),
+	)
 	if err != nil {
 		logger.Fatalw("error creating engine", "error", err)
 	}
@@ -5,13 +5,16 @@ import (
 	"fmt"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/authzed-go/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/otelx"
 
 	"go.infratographer.com/permissions-api/internal/config"
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
 )
 
 var (
@@ -33,6 +36,7 @@ func init() {
 
 	schemaCmd.Flags().Bool("mermaid", false, "outputs the policy as a mermaid chart definition")
 	schemaCmd.Flags().Bool("mermaid-markdown", false, "outputs the policy as a markdown mermaid chart definition")
+	schemaCmd.Flags().Bool("builtin-roles", false, "materialize the policy's built-in roles for all role owners after applying the schema")
 
 	if err := viper.BindPFlag("mermaid", schemaCmd.Flags().Lookup("mermaid")); err != nil {
 		panic(err)
@@ -41,9 +45,13 @@ func init() {
 	if err := viper.BindPFlag("mermaid-markdown", schemaCmd.Flags().Lookup("mermaid-markdown")); err != nil {
 		panic(err)
 	}
+
+	if err := viper.BindPFlag("schema-builtin-roles", schemaCmd.Flags().Lookup("builtin-roles")); err != nil {
+		panic(err)
+	}
 }
 
-func writeSchema(_ context.Context, dryRun bool, cfg *config.AppConfig) {
+func writeSchema(ctx context.Context, dryRun bool, cfg *config.AppConfig) {
 	var (
 		err    error
 		policy iapl.Policy
@@ -100,4 +108,30 @@ func writeSchema(_ context.Context, dryRun bool, cfg *config.AppConfig) {
 	}
 
 	logger.Info("schema applied to SpiceDB")
+
+	if viper.GetBool("schema-builtin-roles") {
+		materializeBuiltinRoles(ctx, cfg, client, policy)
+	}
+}
+
+// materializeBuiltinRoles creates or updates the policy's built-in roles for all role owners.
+func materializeBuiltinRoles(ctx context.Context, cfg *config.AppConfig, client *authzed.Client, policy iapl.Policy) {
+	db, err := newDBFromConfig(cfg)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger))
+
+	engine, err := query.NewEngine("infratographer", client, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	owners, err := engine.MaterializeAllBuiltinRoles(ctx)
+	if err != nil {
+		logger.Fatalw("error materializing built-in roles", "owners", owners, "error", err)
+	}
+
+	logger.Infof("built-in roles materialized for %d role owners", owners)
 }
@@ -2,17 +2,23 @@ package cmd
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/echojwtx"
 	"go.infratographer.com/x/echox"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
 	"go.infratographer.com/x/otelx"
 	"go.infratographer.com/x/versionx"
+	"go.infratographer.com/x/viperx"
 	"go.uber.org/zap"
 
 	"go.infratographer.com/permissions-api/internal/api"
 	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/decisionlog"
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/spicedbx"
@@ -37,9 +43,55 @@ func init() {
 	echox.MustViperFlags(v, serverCmd.Flags(), apiDefaultListen)
 	otelx.MustViperFlags(v, serverCmd.Flags())
 	echojwtx.MustViperFlags(v, serverCmd.Flags())
+	events.MustViperFlags(v, serverCmd.Flags(), appName)
+
+	serverCmd.Flags().StringSlice("api-check-delegates", []string{}, "subject IDs permitted to check permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "api.checkDelegates", serverCmd.Flags().Lookup("api-check-delegates"))
+
+	serverCmd.Flags().Bool("api-privilege-escalation-guard", true, "reject role-bindings and role updates granting actions the actor does not hold")
+	viperx.MustBindFlag(v, "api.privilegeEscalationGuard", serverCmd.Flags().Lookup("api-privilege-escalation-guard"))
+
+	serverCmd.Flags().String("api-access-request-approver-action", "iam_rolebinding_create", "action subjects must hold on a resource to review access requests on it")
+	viperx.MustBindFlag(v, "api.accessRequestApproverAction", serverCmd.Flags().Lookup("api-access-request-approver-action"))
+
+	serverCmd.Flags().String("api-relationship-list-action", "iam_rolebinding_list", "action subjects must hold on a resource to list its relationships")
+	viperx.MustBindFlag(v, "api.relationshipListAction", serverCmd.Flags().Lookup("api-relationship-list-action"))
+
+	serverCmd.Flags().StringToString("api-relationship-list-actions", map[string]string{}, "action subjects must hold to list relationships by resource type, e.g. tenant=tenant_get")
+	viperx.MustBindFlag(v, "api.relationshipListActions", serverCmd.Flags().Lookup("api-relationship-list-actions"))
+
+	serverCmd.Flags().Duration("api-access-request-expiry-interval", time.Minute, "how often role-bindings of expired access requests are removed")
+	viperx.MustBindFlag(v, "api.accessRequestExpiryInterval", serverCmd.Flags().Lookup("api-access-request-expiry-interval"))
+
+	serverCmd.Flags().String("api-break-glass-role", "", "ID of the emergency role subjects may bind themselves to, break-glass access is disabled if empty")
+	viperx.MustBindFlag(v, "api.breakGlassRole", serverCmd.Flags().Lookup("api-break-glass-role"))
+
+	serverCmd.Flags().Duration("api-break-glass-duration", time.Hour, "how long break-glass role-bindings are kept")
+	viperx.MustBindFlag(v, "api.breakGlassDuration", serverCmd.Flags().Lookup("api-break-glass-duration"))
+
+	serverCmd.Flags().Float64("api-usage-sample-rate", 0.01, "fraction of allowed permission checks whose role-bindings are recorded as used, 0 disables usage recording")
+	viperx.MustBindFlag(v, "api.usageSampleRate", serverCmd.Flags().Lookup("api-usage-sample-rate"))
+
+	serverCmd.Flags().Duration("api-usage-flush-interval", time.Minute, "how often recorded role-binding usage is written to the database")
+	viperx.MustBindFlag(v, "api.usageFlushInterval", serverCmd.Flags().Lookup("api-usage-flush-interval"))
+
+	serverCmd.Flags().String("api-decision-log-output", "", "where decisions of permission checks are logged (stdout, file, events), decisions are not logged if empty")
+	viperx.MustBindFlag(v, "api.decisionLogOutput", serverCmd.Flags().Lookup("api-decision-log-output"))
+
+	serverCmd.Flags().String("api-decision-log-file", "", "file decisions are appended to when the decision log output is file")
+	viperx.MustBindFlag(v, "api.decisionLogFile", serverCmd.Flags().Lookup("api-decision-log-file"))
+
+	serverCmd.Flags().Float64("api-decision-log-sample-rate", 1, "fraction of decisions logged whose outcome has no rate of its own")
+	viperx.MustBindFlag(v, "api.decisionLogSampleRate", serverCmd.Flags().Lookup("api-decision-log-sample-rate"))
+
+	serverCmd.Flags().StringToString("api-decision-log-outcome-sample-rates", map[string]string{}, "fraction of decisions logged by outcome (allowed, denied, invalid, error), e.g. allowed=0.01")
+	viperx.MustBindFlag(v, "api.decisionLogOutcomeSampleRates", serverCmd.Flags().Lookup("api-decision-log-outcome-sample-rates"))
+
+	serverCmd.Flags().Bool("api-builtin-roles", false, "materialize the policy's built-in roles for all role owners on startup")
+	viperx.MustBindFlag(v, "api.builtinRoles", serverCmd.Flags().Lookup("api-builtin-roles"))
 }
 
-func serve(_ context.Context, cfg *config.AppConfig) {
+func serve(ctx context.Context, cfg *config.AppConfig) {
 	err := otelx.InitTracer(cfg.Tracing, appName, logger)
 	if err != nil {
 		logger.Fatalw("unable to initialize tracing system", "error", err)
@@ -74,11 +126,49 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("invalid spicedb policy", "error", err)
 	}
 
-	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	engineOpts := []query.Option{
+		query.WithPolicy(policy),
+		query.WithLogger(logger),
+		query.WithPrivilegeEscalationGuard(cfg.API.PrivilegeEscalationGuard),
+	}
+
+	var eventsConn events.Connection
+
+	// break-glass access and decision events are published to the events bus.
+	if cfg.API.BreakGlassRole != "" || cfg.API.DecisionLogOutput == decisionLogOutputEvents {
+		conn, shutdown := newEventsConnection(cfg)
+		defer shutdown()
+
+		eventsConn = conn
+	}
+
+	if cfg.API.BreakGlassRole != "" {
+		engineOpts = append(engineOpts, query.WithBreakGlass(newBreakGlassConfig(cfg, eventsConn)))
+	}
+
+	switch {
+	case cfg.API.UsageSampleRate <= 0:
+		logger.Warn("role-binding usage recording disabled, unused access can not be reported")
+	case cfg.API.UsageFlushInterval <= 0:
+		logger.Warn("role-binding usage flush interval not positive, role-binding usage recording disabled")
+	default:
+		engineOpts = append(engineOpts, query.WithRoleBindingUsage(cfg.API.UsageSampleRate))
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, engineOpts...)
 	if err != nil {
 		logger.Fatalw("error creating engine", "error", err)
 	}
 
+	if cfg.API.BuiltinRoles {
+		owners, err := engine.MaterializeAllBuiltinRoles(ctx)
+		if err != nil {
+			logger.Fatalw("error materializing built-in roles", "owners", owners, "error", err)
+		}
+
+		logger.Infof("built-in roles materialized for %d role owners", owners)
+	}
+
 	srv, err := echox.NewServer(
 		logger.Desugar(),
 		echox.ConfigFromViper(viper.GetViper()),
@@ -88,11 +178,34 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 		logger.Fatal("failed to initialize new server", zap.Error(err))
 	}
 
-	r, err := api.NewRouter(cfg.OIDC, engine, api.WithLogger(logger))
+	checkDelegates := make([]gidx.PrefixedID, len(cfg.API.CheckDelegates))
+
+	for i, delegate := range cfg.API.CheckDelegates {
+		checkDelegates[i], err = gidx.Parse(delegate)
+		if err != nil {
+			logger.Fatalw("invalid check delegate subject id", "subject_id", delegate, "error", err)
+		}
+	}
+
+	decisionLogger, closeDecisionLogger := newDecisionLogger(cfg, eventsConn)
+	defer closeDecisionLogger()
+
+	r, err := api.NewRouter(
+		cfg.OIDC, engine,
+		api.WithLogger(logger),
+		api.WithCheckDelegates(checkDelegates...),
+		api.WithAccessRequestApproverAction(cfg.API.AccessRequestApproverAction),
+		api.WithRelationshipListAction(cfg.API.RelationshipListAction),
+		api.WithRelationshipListActions(cfg.API.RelationshipListActions),
+		api.WithDecisionLogger(decisionLogger),
+	)
 	if err != nil {
 		logger.Fatalw("unable to initialize router", "error", err)
 	}
 
+	go expireAccessRequests(ctx, engine, cfg.API.AccessRequestExpiryInterval)
+	go flushRoleBindingUsage(ctx, engine, cfg.API.UsageFlushInterval)
+
 	srv.AddHandler(r)
 	srv.AddReadinessCheck("spicedb", spicedbx.Healthcheck(spiceClient))
 	srv.AddReadinessCheck("storage", store.HealthCheck)
@@ -100,4 +213,175 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 	if err := srv.Run(); err != nil {
 		logger.Fatal("failed to run server", zap.Error(err))
 	}
+
+	// write the usage recorded since the last flush before exiting.
+	if _, err := engine.FlushRoleBindingUsage(context.Background()); err != nil {
+		logger.Errorw("error flushing role-binding usage", "error", err)
+	}
+}
+
+// newEventsConnection connects to the events bus, returning the connection along with a function shutting it down.
+func newEventsConnection(cfg *config.AppConfig) (events.Connection, func()) {
+	eventsConn, err := events.NewConnection(cfg.Events.Config, events.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("failed to initialize events", "error", err)
+	}
+
+	shutdown := func() {
+		if err := eventsConn.Shutdown(context.Background()); err != nil {
+			logger.Errorw("failed to shutdown events gracefully", "error", err)
+		}
+	}
+
+	return eventsConn, shutdown
+}
+
+// newBreakGlassConfig returns the break-glass configuration, publishing break-glass events with publisher.
+func newBreakGlassConfig(cfg *config.AppConfig, publisher events.Publisher) query.BreakGlassConfig {
+	roleID, err := gidx.Parse(cfg.API.BreakGlassRole)
+	if err != nil {
+		logger.Fatalw("invalid break-glass role id", "role_id", cfg.API.BreakGlassRole, "error", err)
+	}
+
+	if cfg.API.BreakGlassDuration <= 0 {
+		logger.Fatalw("break-glass duration must be positive", "duration", cfg.API.BreakGlassDuration)
+	}
+
+	return query.BreakGlassConfig{
+		RoleID:    roleID,
+		Duration:  cfg.API.BreakGlassDuration,
+		Publisher: publisher,
+	}
+}
+
+const (
+	decisionLogOutputStdout = "stdout"
+	decisionLogOutputFile   = "file"
+	decisionLogOutputEvents = "events"
+)
+
+// newDecisionLogger returns the logger of the decisions of permission checks along with a function closing it.
+// It returns a nil logger if decisions are not logged.
+func newDecisionLogger(cfg *config.AppConfig, publisher events.Publisher) (decisionlog.Logger, func()) {
+	var (
+		out         decisionlog.Logger
+		closeLogger = func() {}
+	)
+
+	switch cfg.API.DecisionLogOutput {
+	case "":
+		return nil, closeLogger
+	case decisionLogOutputStdout:
+		out = decisionlog.NewWriterLogger(os.Stdout, logger)
+	case decisionLogOutputFile:
+		if cfg.API.DecisionLogFile == "" {
+			logger.Fatal("decision log file required when decisions are logged to a file")
+		}
+
+		f, err := os.OpenFile(cfg.API.DecisionLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			logger.Fatalw("failed to open decision log file", "file", cfg.API.DecisionLogFile, "error", err)
+		}
+
+		out = decisionlog.NewWriterLogger(f, logger)
+		closeLogger = func() {
+			if err := f.Close(); err != nil {
+				logger.Errorw("failed to close decision log file", "error", err)
+			}
+		}
+	case decisionLogOutputEvents:
+		eventsLogger := decisionlog.NewEventsLogger(publisher, logger)
+
+		out = eventsLogger
+		closeLogger = eventsLogger.Close
+	default:
+		logger.Fatalw("unknown decision log output", "output", cfg.API.DecisionLogOutput)
+	}
+
+	rates := decisionlog.SampleRates{
+		Default:  cfg.API.DecisionLogSampleRate,
+		Outcomes: make(map[decisionlog.Outcome]float64, len(cfg.API.DecisionLogOutcomeSampleRates)),
+	}
+
+	for outcome, rate := range cfg.API.DecisionLogOutcomeSampleRates {
+		switch o := decisionlog.Outcome(outcome); o {
+		case decisionlog.OutcomeAllowed, decisionlog.OutcomeDenied, decisionlog.OutcomeInvalid, decisionlog.OutcomeError:
+			rates.Outcomes[o] = rate
+		default:
+			logger.Fatalw("unknown decision log outcome", "outcome", outcome)
+		}
+	}
+
+	return decisionlog.NewSampledLogger(out, rates), closeLogger
+}
+
+// accessRequestExpiryBatchSize is the maximum number of access requests expired at once.
+const accessRequestExpiryBatchSize = 100
+
+// expireAccessRequests removes the role-bindings of expired access requests every interval until ctx is done.
+func expireAccessRequests(ctx context.Context, engine query.Engine, interval time.Duration) {
+	if interval <= 0 {
+		logger.Warn("access request expiry disabled, role-bindings of expired access requests are kept")
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			// requests which fail to expire are logged and skipped by the engine, so an
+			// error here means the expired requests couldn't be listed at all.
+			expired, err := engine.ExpireAccessRequests(ctx, accessRequestExpiryBatchSize)
+			if err != nil {
+				logger.Errorw("error listing expired access requests", "error", err)
+
+				break
+			}
+
+			if expired != 0 {
+				logger.Infow("expired access requests", "count", expired)
+			}
+
+			if expired < accessRequestExpiryBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// flushRoleBindingUsage writes the recorded role-binding usage to the database every interval until ctx is done.
+func flushRoleBindingUsage(ctx context.Context, engine query.Engine, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		flushed, err := engine.FlushRoleBindingUsage(ctx)
+		if err != nil {
+			logger.Errorw("error flushing role-binding usage", "error", err)
+
+			continue
+		}
+
+		if flushed != 0 {
+			logger.Debugw("flushed role-binding usage", "count", flushed)
+		}
+	}
 }
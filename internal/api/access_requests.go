@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func accessRequestResp(req types.AccessRequest) accessRequestResponse {
+	resp := accessRequestResponse{
+		ID:            req.ID,
+		ResourceID:    req.ResourceID,
+		RoleID:        req.RoleID,
+		SubjectID:     req.SubjectID,
+		Reason:        req.Reason,
+		Status:        string(req.Status),
+		RoleBindingID: req.RoleBindingID,
+		BreakGlass:    req.BreakGlass,
+
+		ReviewedBy: req.ReviewedBy,
+		CreatedAt:  req.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  req.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if req.ExpiresIn != 0 {
+		resp.ExpiresIn = req.ExpiresIn.String()
+	}
+
+	if req.ExpiresAt != nil {
+		resp.ExpiresAt = req.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
+
+func parseAccessRequestExpiresIn(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	expiresIn, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: expires_in: %s", ErrInvalidDuration, err.Error())
+	}
+
+	if expiresIn <= 0 {
+		return 0, fmt.Errorf("%w: expires_in: must be positive", ErrInvalidDuration)
+	}
+
+	return expiresIn, nil
+}
+
+func parseAccessRequestStatus(c echo.Context) (types.AccessRequestStatus, error) {
+	status := types.AccessRequestStatus(c.QueryParam("status"))
+
+	switch status {
+	case "", types.AccessRequestPending, types.AccessRequestApproved, types.AccessRequestDenied, types.AccessRequestExpired:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: status: unknown status %q", ErrInvalidFilter, status)
+	}
+}
+
+// accessRequestCreate requests the role on the resource for the caller.
+func (r *Router) accessRequestCreate(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.accessRequestCreate")
+	defer span.End()
+
+	var body accessRequestCreateRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	expiresIn, err := parseAccessRequestExpiresIn(body.ExpiresIn)
+	if err != nil {
+		return r.errorResponse("error parsing request body", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	resource, err := r.engine.NewResourceFromID(body.ResourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	roleResource, err := r.engine.NewResourceFromID(body.RoleID)
+	if err != nil {
+		return r.errorResponse("error creating role resource", err)
+	}
+
+	req, err := r.engine.CreateAccessRequest(ctx, actor, resource, roleResource, body.Reason, expiresIn)
+	if err != nil {
+		return r.errorResponse("error creating access request", err)
+	}
+
+	return c.JSON(http.StatusCreated, accessRequestResp(req))
+}
+
+// accessRequestsListOwn lists the caller's access requests, optionally filtered by status.
+func (r *Router) accessRequestsListOwn(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.accessRequestsListOwn")
+	defer span.End()
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	status, err := parseAccessRequestStatus(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	reqs, nextCursor, err := r.engine.ListSubjectAccessRequests(ctx, actor, status, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing access requests", err)
+	}
+
+	return c.JSON(http.StatusOK, listAccessRequestsResp(reqs, nextCursor))
+}
+
+// accessRequestsList lists the access requests on a resource, optionally filtered by status.
+// Requests may be listed by approvers of the resource's access requests.
+func (r *Router) accessRequestsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, r.accessRequestApproverAction, resource); err != nil {
+		return err
+	}
+
+	status, err := parseAccessRequestStatus(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	reqs, nextCursor, err := r.engine.ListAccessRequests(ctx, resource, status, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing access requests", err)
+	}
+
+	return c.JSON(http.StatusOK, listAccessRequestsResp(reqs, nextCursor))
+}
+
+func listAccessRequestsResp(reqs []types.AccessRequest, nextCursor string) listAccessRequestsResponse {
+	resp := listAccessRequestsResponse{
+		Data:       make([]accessRequestResponse, len(reqs)),
+		NextCursor: nextCursor,
+	}
+
+	for i, req := range reqs {
+		resp.Data[i] = accessRequestResp(req)
+	}
+
+	return resp
+}
+
+// accessRequestGet fetches an access request.
+// Requests may be fetched by their subject and by approvers of the request's resource.
+func (r *Router) accessRequestGet(c echo.Context) error {
+	reqIDStr := c.Param("acr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestGet",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	_, req, err := r.authorizeAccessRequest(ctx, c, reqIDStr, true)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, accessRequestResp(req))
+}
+
+// accessRequestApprove approves a pending access request, binding its subject to the role.
+// Requests may be approved by approvers of the request's resource other than its subject.
+func (r *Router) accessRequestApprove(c echo.Context) error {
+	reqIDStr := c.Param("acr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestApprove",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	var body accessRequestApproveRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	expiresIn, err := parseAccessRequestExpiresIn(body.ExpiresIn)
+	if err != nil {
+		return r.errorResponse("error parsing request body", err)
+	}
+
+	actor, req, err := r.authorizeAccessRequest(ctx, c, reqIDStr, false)
+	if err != nil {
+		return err
+	}
+
+	req, err = r.engine.ApproveAccessRequest(ctx, actor, req.ID, expiresIn)
+	if err != nil {
+		return r.errorResponse("error approving access request", err)
+	}
+
+	return c.JSON(http.StatusOK, accessRequestResp(req))
+}
+
+// accessRequestDeny denies a pending access request.
+// Requests may be denied by approvers of the request's resource other than its subject.
+func (r *Router) accessRequestDeny(c echo.Context) error {
+	reqIDStr := c.Param("acr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestDeny",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	actor, req, err := r.authorizeAccessRequest(ctx, c, reqIDStr, false)
+	if err != nil {
+		return err
+	}
+
+	req, err = r.engine.DenyAccessRequest(ctx, actor, req.ID)
+	if err != nil {
+		return r.errorResponse("error denying access request", err)
+	}
+
+	return c.JSON(http.StatusOK, accessRequestResp(req))
+}
+
+// authorizeAccessRequest fetches an access request and ensures the current subject is an approver
+// of the request's resource. If allowSubject is set, the request's subject is permitted as well.
+func (r *Router) authorizeAccessRequest(
+	ctx context.Context, c echo.Context, reqIDStr string, allowSubject bool,
+) (types.Resource, types.AccessRequest, error) {
+	reqID, err := gidx.Parse(reqIDStr)
+	if err != nil {
+		return types.Resource{}, types.AccessRequest{}, r.errorResponse("error parsing access request ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, types.AccessRequest{}, err
+	}
+
+	req, err := r.engine.GetAccessRequest(ctx, reqID)
+	if err != nil {
+		return types.Resource{}, types.AccessRequest{}, r.errorResponse("error getting access request", err)
+	}
+
+	if allowSubject && req.SubjectID == actor.ID {
+		return actor, req, nil
+	}
+
+	resource, err := r.engine.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return types.Resource{}, types.AccessRequest{}, r.errorResponse("error creating resource", err)
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, r.accessRequestApproverAction, resource); err != nil {
+		return types.Resource{}, types.AccessRequest{}, err
+	}
+
+	return actor, req, nil
+}
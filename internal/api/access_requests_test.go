@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestAccessRequests(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	pending := types.AccessRequest{
+		ID:         "permacr-abc123",
+		ResourceID: "tnntten-abc123",
+		RoleID:     "permrol-abc123",
+		SubjectID:  "idntusr-def456",
+		Reason:     "on-call",
+		Status:     types.AccessRequestPending,
+		ExpiresIn:  8 * time.Hour,
+	}
+
+	expiresAt := time.Date(2024, 12, 15, 8, 0, 0, 0, time.UTC)
+
+	approved := pending
+	approved.Status = types.AccessRequestApproved
+	approved.RoleBindingID = "permrbn-abc123"
+	approved.ReviewedBy = "idntusr-abc123"
+	approved.ExpiresAt = &expiresAt
+
+	newEngine := func(ctx context.Context, setup func(*mock.Engine)) context.Context {
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		setup(&engine)
+
+		return context.WithValue(ctx, contextKeyEngine, &engine)
+	}
+
+	testCases := []testingx.TestCase[input, *httptest.ResponseRecorder]{
+		{
+			Name: "Create",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-requests",
+				body:   `{"resource_id":"tnntten-abc123","role_id":"permrol-abc123","reason":"on-call","expires_in":"8h"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("CreateAccessRequest").Return(pending, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusCreated, res.Success.Code)
+
+				var ret accessRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, pending.ID, ret.ID)
+				assert.Equal(t, "pending", ret.Status)
+				assert.Equal(t, "8h0m0s", ret.ExpiresIn)
+			},
+		},
+		{
+			Name: "CreateInvalidExpiry",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-requests",
+				body:   `{"resource_id":"tnntten-abc123","role_id":"permrol-abc123","expires_in":"-1h"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(*mock.Engine) {})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "CreateAccessRequest")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "GetOwnRequest",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-requests/permacr-abc123",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					req := pending
+					req.SubjectID = "idntusr-abc123"
+
+					e.On("GetAccessRequest").Return(req, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "SubjectHasPermission")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+		{
+			Name: "GetDenied",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-requests/permacr-abc123",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "Approve",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-requests/permacr-abc123/approve",
+				body:   `{"expires_in":"1h"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ApproveAccessRequest").Return(approved, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret accessRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, "approved", ret.Status)
+				assert.Equal(t, approved.RoleBindingID, ret.RoleBindingID)
+				assert.Equal(t, "2024-12-15T08:00:00Z", ret.ExpiresAt)
+			},
+		},
+		{
+			Name: "DenyOwnRequest",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-requests/permacr-abc123/deny",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("DenyAccessRequest").Return(types.AccessRequest{}, query.ErrAccessRequestReviewForbidden)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "ListResourcePending",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/resources/tnntten-abc123/access-requests?status=pending",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ListAccessRequests").Return([]types.AccessRequest{pending}, "", nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listAccessRequestsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, pending.ID, ret.Data[0].ID)
+			},
+		},
+		{
+			Name: "ListOwnInvalidStatus",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-requests?status=unknown",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(*mock.Engine) {})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, in.method, in.path, bytes.NewBufferString(in.body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func accessReviewResp(review types.AccessReview) accessReviewResponse {
+	resp := accessReviewResponse{
+		ID:         review.ID,
+		ResourceID: review.ResourceID,
+		Name:       review.Name,
+		Status:     string(review.Status),
+
+		CreatedBy: review.CreatedBy,
+		ClosedBy:  review.ClosedBy,
+		CreatedAt: review.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: review.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if review.ClosedAt != nil {
+		resp.ClosedAt = review.ClosedAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
+
+func accessReviewItemResp(item types.AccessReviewItem) accessReviewItemResponse {
+	return accessReviewItemResponse{
+		ID:            item.ID,
+		ReviewID:      item.ReviewID,
+		RoleBindingID: item.RoleBindingID,
+		ResourceID:    item.ResourceID,
+		RoleID:        item.RoleID,
+		SubjectID:     item.SubjectID,
+		Decision:      string(item.Decision),
+
+		ReviewedBy: item.ReviewedBy,
+		UpdatedAt:  item.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func parseAccessReviewStatus(c echo.Context) (types.AccessReviewStatus, error) {
+	status := types.AccessReviewStatus(c.QueryParam("status"))
+
+	switch status {
+	case "", types.AccessReviewOpen, types.AccessReviewClosed:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: status: unknown status %q", ErrInvalidFilter, status)
+	}
+}
+
+func parseAccessReviewDecision(c echo.Context) (types.AccessReviewDecision, error) {
+	decision := types.AccessReviewDecision(c.QueryParam("decision"))
+
+	switch decision {
+	case "", types.AccessReviewPending, types.AccessReviewKeep, types.AccessReviewRevoke:
+		return decision, nil
+	default:
+		return "", fmt.Errorf("%w: decision: unknown decision %q", ErrInvalidFilter, decision)
+	}
+}
+
+// accessReviewCreate opens an access review of the role-bindings on the resource and the resources beneath it.
+func (r *Router) accessReviewCreate(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewCreate",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	var body accessReviewCreateRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	actor, resource, err := r.authorizeAccessReviewResource(ctx, c, resourceIDStr)
+	if err != nil {
+		return err
+	}
+
+	review, err := r.engine.CreateAccessReview(ctx, actor, resource, body.Name)
+	if err != nil {
+		return r.errorResponse("error creating access review", err)
+	}
+
+	return c.JSON(http.StatusCreated, accessReviewResp(review))
+}
+
+// accessReviewsList lists the access reviews of a resource, optionally filtered by status.
+func (r *Router) accessReviewsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	_, resource, err := r.authorizeAccessReviewResource(ctx, c, resourceIDStr)
+	if err != nil {
+		return err
+	}
+
+	status, err := parseAccessReviewStatus(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	reviews, nextCursor, err := r.engine.ListAccessReviews(ctx, resource, status, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing access reviews", err)
+	}
+
+	resp := listAccessReviewsResponse{
+		Data:       make([]accessReviewResponse, len(reviews)),
+		NextCursor: nextCursor,
+	}
+
+	for i, review := range reviews {
+		resp.Data[i] = accessReviewResp(review)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// accessReviewGet fetches an access review.
+func (r *Router) accessReviewGet(c echo.Context) error {
+	reviewIDStr := c.Param("arv_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewGet",
+		trace.WithAttributes(attribute.String("id", reviewIDStr)),
+	)
+	defer span.End()
+
+	_, review, err := r.authorizeAccessReview(ctx, c, reviewIDStr, string(iapl.RoleBindingActionList))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, accessReviewResp(review))
+}
+
+// accessReviewItemsList lists the items of an access review, optionally filtered by decision.
+func (r *Router) accessReviewItemsList(c echo.Context) error {
+	reviewIDStr := c.Param("arv_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewItemsList",
+		trace.WithAttributes(attribute.String("id", reviewIDStr)),
+	)
+	defer span.End()
+
+	_, review, err := r.authorizeAccessReview(ctx, c, reviewIDStr, string(iapl.RoleBindingActionList))
+	if err != nil {
+		return err
+	}
+
+	decision, err := parseAccessReviewDecision(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	items, nextCursor, err := r.engine.ListAccessReviewItems(ctx, review.ID, decision, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing access review items", err)
+	}
+
+	resp := listAccessReviewItemsResponse{
+		Data:       make([]accessReviewItemResponse, len(items)),
+		NextCursor: nextCursor,
+	}
+
+	for i, item := range items {
+		resp.Data[i] = accessReviewItemResp(item)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// accessReviewItemDecide records the decision to keep or revoke an item of an open access review.
+func (r *Router) accessReviewItemDecide(c echo.Context) error {
+	reviewIDStr := c.Param("arv_id")
+	itemIDStr := c.Param("item_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewItemDecide",
+		trace.WithAttributes(
+			attribute.String("id", reviewIDStr),
+			attribute.String("item_id", itemIDStr),
+		),
+	)
+	defer span.End()
+
+	itemID, err := gidx.Parse(itemIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing access review item ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body accessReviewItemDecideRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	actor, review, err := r.authorizeAccessReview(ctx, c, reviewIDStr, string(iapl.RoleBindingActionUpdate))
+	if err != nil {
+		return err
+	}
+
+	item, err := r.engine.DecideAccessReviewItem(ctx, actor, review.ID, itemID, types.AccessReviewDecision(body.Decision))
+	if err != nil {
+		return r.errorResponse("error deciding access review item", err)
+	}
+
+	return c.JSON(http.StatusOK, accessReviewItemResp(item))
+}
+
+// accessReviewClose closes an open access review, revoking the items decided to be revoked.
+func (r *Router) accessReviewClose(c echo.Context) error {
+	reviewIDStr := c.Param("arv_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessReviewClose",
+		trace.WithAttributes(attribute.String("id", reviewIDStr)),
+	)
+	defer span.End()
+
+	actor, review, err := r.authorizeAccessReview(
+		ctx, c, reviewIDStr,
+		string(iapl.RoleBindingActionUpdate), string(iapl.RoleBindingActionDelete),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := r.authorizeAccessReviewRevocations(ctx, actor, review); err != nil {
+		return err
+	}
+
+	review, err = r.engine.CloseAccessReview(ctx, actor, review.ID)
+	if err != nil {
+		return r.errorResponse("error closing access review", err)
+	}
+
+	return c.JSON(http.StatusOK, accessReviewResp(review))
+}
+
+// authorizeAccessReviewRevocations ensures the current subject may update and delete the role-bindings
+// of the review's revoked items on descendants of the reviewed resource, rather than relying on the
+// role-binding actions being inherited from it.
+func (r *Router) authorizeAccessReviewRevocations(ctx context.Context, actor types.Resource, review types.AccessReview) error {
+	items, _, err := r.engine.ListAccessReviewItems(ctx, review.ID, types.AccessReviewRevoke, query.ListOptions{})
+	if err != nil {
+		return r.errorResponse("error listing access review items", err)
+	}
+
+	checked := map[gidx.PrefixedID]struct{}{
+		review.ResourceID: {},
+	}
+
+	for _, item := range items {
+		if _, ok := checked[item.ResourceID]; ok {
+			continue
+		}
+
+		checked[item.ResourceID] = struct{}{}
+
+		resource, err := r.engine.NewResourceFromID(item.ResourceID)
+		if err != nil {
+			return r.errorResponse("error creating resource", err)
+		}
+
+		for _, action := range []iapl.RoleBindingAction{iapl.RoleBindingActionUpdate, iapl.RoleBindingActionDelete} {
+			if err := r.checkActionWithResponse(ctx, actor, string(action), resource); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// authorizeAccessReviewResource ensures the current subject may list the role-bindings of the resource.
+func (r *Router) authorizeAccessReviewResource(
+	ctx context.Context, c echo.Context, resourceIDStr string,
+) (types.Resource, types.Resource, error) {
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return types.Resource{}, types.Resource{}, r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return types.Resource{}, types.Resource{}, r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, types.Resource{}, err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionList), resource); err != nil {
+		return types.Resource{}, types.Resource{}, err
+	}
+
+	return actor, resource, nil
+}
+
+// authorizeAccessReview fetches an access review and ensures the current subject holds
+// each of the actions on the review's resource.
+func (r *Router) authorizeAccessReview(
+	ctx context.Context, c echo.Context, reviewIDStr string, actions ...string,
+) (types.Resource, types.AccessReview, error) {
+	reviewID, err := gidx.Parse(reviewIDStr)
+	if err != nil {
+		return types.Resource{}, types.AccessReview{}, r.errorResponse("error parsing access review ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, types.AccessReview{}, err
+	}
+
+	review, err := r.engine.GetAccessReview(ctx, reviewID)
+	if err != nil {
+		return types.Resource{}, types.AccessReview{}, r.errorResponse("error getting access review", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(review.ResourceID)
+	if err != nil {
+		return types.Resource{}, types.AccessReview{}, r.errorResponse("error creating resource", err)
+	}
+
+	for _, action := range actions {
+		if err := r.checkActionWithResponse(ctx, actor, action, resource); err != nil {
+			return types.Resource{}, types.AccessReview{}, err
+		}
+	}
+
+	return actor, review, nil
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestAccessReviews(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	open := types.AccessReview{
+		ID:         "permarv-abc123",
+		ResourceID: "tnntten-abc123",
+		Name:       "quarterly",
+		Status:     types.AccessReviewOpen,
+		CreatedBy:  "idntusr-abc123",
+	}
+
+	closedAt := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	closed := open
+	closed.Status = types.AccessReviewClosed
+	closed.ClosedBy = "idntusr-abc123"
+	closed.ClosedAt = &closedAt
+
+	item := types.AccessReviewItem{
+		ID:            "permari-abc123",
+		ReviewID:      open.ID,
+		RoleBindingID: "permrbn-abc123",
+		ResourceID:    "tnntten-abc123",
+		RoleID:        "permrol-abc123",
+		SubjectID:     "idntusr-def456",
+		Decision:      types.AccessReviewRevoke,
+		ReviewedBy:    "idntusr-abc123",
+	}
+
+	childItem := item
+	childItem.ID = "permari-def456"
+	childItem.RoleBindingID = "permrbn-def456"
+	childItem.ResourceID = "tnntten-child"
+
+	newEngine := func(ctx context.Context, setup func(*mock.Engine)) context.Context {
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		setup(&engine)
+
+		return context.WithValue(ctx, contextKeyEngine, &engine)
+	}
+
+	testCases := []testingx.TestCase[input, *httptest.ResponseRecorder]{
+		{
+			Name: "Create",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/access-reviews",
+				body:   `{"name":"quarterly"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("CreateAccessReview").Return(open, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusCreated, res.Success.Code)
+
+				var ret accessReviewResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, open.ID, ret.ID)
+				assert.Equal(t, "open", ret.Status)
+				assert.Empty(t, ret.ClosedAt)
+			},
+		},
+		{
+			Name: "CreateDenied",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/access-reviews",
+				body:   `{"name":"quarterly"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "CreateAccessReview")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "ListItemsRevoked",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-reviews/permarv-abc123/items?decision=revoke",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(open, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ListAccessReviewItems").Return([]types.AccessReviewItem{item}, "", nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listAccessReviewItemsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, item.SubjectID, ret.Data[0].SubjectID)
+				assert.Equal(t, "revoke", ret.Data[0].Decision)
+			},
+		},
+		{
+			Name: "ListItemsInvalidDecision",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-reviews/permarv-abc123/items?decision=unknown",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(open, nil)
+					e.On("SubjectHasPermission").Return(nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "ListAccessReviewItems")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "DecideClosedReview",
+			Input: input{
+				method: http.MethodPatch,
+				path:   "/api/v2/access-reviews/permarv-abc123/items/permari-abc123",
+				body:   `{"decision":"keep"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(closed, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("DecideAccessReviewItem").Return(types.AccessReviewItem{}, query.ErrAccessReviewClosed)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusConflict, res.Success.Code)
+			},
+		},
+		{
+			Name: "Close",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-reviews/permarv-abc123/close",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(open, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ListAccessReviewItems").Return([]types.AccessReviewItem{item, childItem}, "", nil)
+					e.On("CloseAccessReview").Return(closed, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret accessReviewResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, "closed", ret.Status)
+				assert.Equal(t, "2025-01-01T08:00:00Z", ret.ClosedAt)
+			},
+		},
+		{
+			Name: "CloseDeniedOnRevokedResource",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/access-reviews/permarv-abc123/close",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(open, nil)
+					// allowed on the reviewed resource, denied on the child resource of a revoked item.
+					e.On("SubjectHasPermission").Return(nil).Times(2)
+					e.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+					e.On("ListAccessReviewItems").Return([]types.AccessReviewItem{item, childItem}, "", nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "CloseAccessReview")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "GetNotFound",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/access-reviews/permarv-abc123",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetAccessReview").Return(types.AccessReview{}, query.ErrAccessReviewNotFound)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, in.method, in.path, bytes.NewBufferString(in.body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
@@ -121,8 +121,13 @@ func (r *Router) assignmentsList(c echo.Context) error {
 		ID: roleID,
 	}
 
-	assignments, err := r.engine.ListAssignments(ctx, role)
-	if err != nil {
+	assignments, nextCursor, err := r.engine.ListAssignments(ctx, role, ParsePagination(c).ListOptions())
+
+	switch {
+	case err == nil:
+	case errors.Is(err, query.ErrInvalidCursor):
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor").SetInternal(err)
+	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error listing assignments").SetInternal(err)
 	}
 
@@ -137,7 +142,8 @@ func (r *Router) assignmentsList(c echo.Context) error {
 	}
 
 	out := listAssignmentsResponse{
-		Data: items,
+		Data:       items,
+		NextCursor: nextCursor,
 	}
 
 	return c.JSON(http.StatusOK, out)
@@ -242,7 +242,7 @@ func TestAssignmentsList(t *testing.T) {
 				engine.On("SubjectHasPermission").Return(nil)
 				engine.On("ListAssignments").Return([]types.Resource{{
 					ID: gidx.MustNewID("idntusr"),
-				}}, nil)
+				}}, "", nil)
 
 				return context.WithValue(ctx, contextKeyEngine, &engine)
 			},
@@ -268,6 +268,45 @@ func TestAssignmentsList(t *testing.T) {
 				assert.True(t, strings.HasPrefix(ret.Data[0].SubjectID, "idntusr-"))
 			},
 		},
+		{
+			Name:  "AssignmentsPaginated",
+			Input: "/api/v1/roles/permrol-abc123/assignments?limit=1",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("GetRoleResource").Return(types.Resource{}, nil)
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("ListAssignments").Return([]types.Resource{{
+					ID: gidx.MustNewID("idntusr"),
+				}}, "next-page", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				resp := res.Success.Result()
+
+				defer resp.Body.Close() //nolint:errcheck
+
+				var ret listAssignmentsResponse
+
+				err := json.NewDecoder(resp.Body).Decode(&ret)
+
+				require.NoError(t, err)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				require.NotEmpty(t, ret.Data)
+				assert.True(t, strings.HasPrefix(ret.Data[0].SubjectID, "idntusr-"))
+				assert.Equal(t, "next-page", ret.NextCursor)
+			},
+		},
 	}
 
 	testFn := func(ctx context.Context, path string) testingx.TestResult[*httptest.ResponseRecorder] {
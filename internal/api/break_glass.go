@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+// breakGlassCreate binds the caller to the emergency role on the resource for a short, fixed duration.
+// The caller must hold the break-glass action on the resource and justify the access.
+func (r *Router) breakGlassCreate(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.breakGlassCreate",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	var body breakGlassRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionBreakGlass), resource); err != nil {
+		return err
+	}
+
+	req, err := r.engine.BreakGlass(ctx, actor, resource, body.Justification)
+	if err != nil {
+		return r.errorResponse("error breaking glass", err)
+	}
+
+	return c.JSON(http.StatusCreated, accessRequestResp(req))
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestBreakGlass(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type input struct {
+		method string
+		path   string
+		body   string
+	}
+
+	expiresAt := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	granted := types.AccessRequest{
+		ID:            "permacr-abc123",
+		ResourceID:    "tnntten-abc123",
+		RoleID:        "permrol-abc123",
+		SubjectID:     "idntusr-abc123",
+		Reason:        "incident",
+		Status:        types.AccessRequestApproved,
+		ExpiresIn:     time.Hour,
+		ExpiresAt:     &expiresAt,
+		RoleBindingID: "permrbn-abc123",
+		BreakGlass:    true,
+		ReviewedBy:    "idntusr-abc123",
+	}
+
+	newEngine := func(ctx context.Context, setup func(*mock.Engine)) context.Context {
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		setup(&engine)
+
+		return context.WithValue(ctx, contextKeyEngine, &engine)
+	}
+
+	testCases := []testingx.TestCase[input, *httptest.ResponseRecorder]{
+		{
+			Name: "BreakGlass",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/break-glass",
+				body:   `{"justification":"incident"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("BreakGlass").Return(granted, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusCreated, res.Success.Code)
+
+				var ret accessRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.True(t, ret.BreakGlass)
+				assert.Equal(t, granted.RoleBindingID, ret.RoleBindingID)
+				assert.Equal(t, "2025-01-15T09:00:00Z", ret.ExpiresAt)
+			},
+		},
+		{
+			Name: "BreakGlassDenied",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/break-glass",
+				body:   `{"justification":"incident"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "BreakGlass")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "MissingJustification",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/break-glass",
+				body:   `{}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("BreakGlass").Return(types.AccessRequest{}, query.ErrBreakGlassJustificationRequired)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "NotConfigured",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/break-glass",
+				body:   `{"justification":"incident"}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("BreakGlass").Return(types.AccessRequest{}, query.ErrBreakGlassNotConfigured)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, in.method, in.path, bytes.NewBufferString(in.body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
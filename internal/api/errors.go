@@ -7,4 +7,14 @@ var (
 	ErrInvalidID = errors.New("invalid ID")
 	// ErrParsingRequestBody is returned when failing to parse the request body
 	ErrParsingRequestBody = errors.New("error parsing request body")
+	// ErrCheckDelegateNotAllowed is returned when a subject checks permissions on behalf of another subject without being a check delegate
+	ErrCheckDelegateNotAllowed = errors.New("subject is not a check delegate")
+	// ErrInvalidFilter is returned when a list filter query parameter is invalid
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidBatch is returned when a batch request has no operations or too many operations
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrInvalidIdempotencyKey is returned when the Idempotency-Key header is too long
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrInvalidDuration is returned when a duration is not a positive Go duration
+	ErrInvalidDuration = errors.New("invalid duration")
 )
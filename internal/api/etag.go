@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+const (
+	// ETagHeader is the response header holding the current version of a role or role-binding.
+	ETagHeader = "ETag"
+	// IfMatchHeader is the request header which makes role and role-binding updates and deletes
+	// conditional on the ETag of the current version.
+	IfMatchHeader = "If-Match"
+
+	etagBase = 36
+)
+
+// etag returns the strong entity tag for an object last updated at updatedAt.
+func etag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixNano(), etagBase) + `"`
+}
+
+// setETag sets the ETag response header for an object last updated at updatedAt.
+func setETag(c echo.Context, updatedAt time.Time) {
+	c.Response().Header().Set(ETagHeader, etag(updatedAt))
+}
+
+// ifMatchContext returns ctx with a version precondition for the request's If-Match header, if one was provided.
+func ifMatchContext(ctx context.Context, c echo.Context) context.Context {
+	versions, ok := parseIfMatch(c.Request().Header.Get(IfMatchHeader))
+	if !ok {
+		return ctx
+	}
+
+	return query.ContextWithVersionPrecondition(ctx, versions...)
+}
+
+// parseIfMatch returns the versions matched by an If-Match header. The returned bool is false when
+// the header matches any version. Weak and malformed entity tags never match, as If-Match uses strong
+// comparison.
+func parseIfMatch(header string) ([]time.Time, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, false
+	}
+
+	versions := []time.Time{}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+
+		nanos, err := strconv.ParseInt(tag[1:len(tag)-1], etagBase, 64)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, time.Unix(0, nanos))
+	}
+
+	return versions, true
+}
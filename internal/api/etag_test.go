@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	ctx := context.Background()
+
+	updatedAt := time.Date(2024, 5, 6, 16, 0, 46, 123456000, time.UTC)
+	otherUpdatedAt := updatedAt.Add(time.Second)
+
+	type result struct {
+		versions []time.Time
+		ok       bool
+	}
+
+	testCases := []testingx.TestCase[string, result]{
+		{
+			Name:  "NoHeader",
+			Input: "",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.False(t, res.Success.ok)
+			},
+		},
+		{
+			Name:  "Any",
+			Input: "*",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.False(t, res.Success.ok)
+			},
+		},
+		{
+			Name:  "ETag",
+			Input: etag(updatedAt),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.True(t, res.Success.ok)
+				assert.Len(t, res.Success.versions, 1)
+				assert.True(t, updatedAt.Equal(res.Success.versions[0]))
+			},
+		},
+		{
+			Name:  "ETagList",
+			Input: etag(updatedAt) + ", " + etag(otherUpdatedAt),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.True(t, res.Success.ok)
+				assert.Len(t, res.Success.versions, 2)
+				assert.True(t, otherUpdatedAt.Equal(res.Success.versions[1]))
+			},
+		},
+		{
+			Name:  "WeakAndMalformed",
+			Input: "W/" + etag(updatedAt) + `, "not-base36!", unquoted`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.True(t, res.Success.ok)
+				assert.Empty(t, res.Success.versions)
+			},
+		},
+	}
+
+	testFn := func(_ context.Context, header string) testingx.TestResult[result] {
+		versions, ok := parseIfMatch(header)
+
+		return testingx.TestResult[result]{Success: result{versions: versions, ok: ok}}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
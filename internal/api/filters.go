@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// timeRange is the created and updated time range filter shared by list endpoints.
+type timeRange struct {
+	createdAfter  time.Time
+	createdBefore time.Time
+	updatedAfter  time.Time
+	updatedBefore time.Time
+}
+
+// parseRoleFilter parses the role list filter query parameters.
+func parseRoleFilter(c echo.Context) (query.RoleFilter, error) {
+	params := c.QueryParams()
+
+	createdBy, err := parseFilterID(params, "created_by")
+	if err != nil {
+		return query.RoleFilter{}, err
+	}
+
+	times, err := parseTimeRange(params)
+	if err != nil {
+		return query.RoleFilter{}, err
+	}
+
+	return query.RoleFilter{
+		NamePrefix:    params.Get("name_prefix"),
+		Action:        params.Get("action"),
+		CreatedBy:     createdBy,
+		CreatedAfter:  times.createdAfter,
+		CreatedBefore: times.createdBefore,
+		UpdatedAfter:  times.updatedAfter,
+		UpdatedBefore: times.updatedBefore,
+	}, nil
+}
+
+// parseRoleBindingFilter parses the role-binding list filter query parameters.
+func parseRoleBindingFilter(c echo.Context) (query.RoleBindingFilter, error) {
+	params := c.QueryParams()
+
+	subjectID, err := parseFilterID(params, "subject_id")
+	if err != nil {
+		return query.RoleBindingFilter{}, err
+	}
+
+	createdBy, err := parseFilterID(params, "created_by")
+	if err != nil {
+		return query.RoleBindingFilter{}, err
+	}
+
+	times, err := parseTimeRange(params)
+	if err != nil {
+		return query.RoleBindingFilter{}, err
+	}
+
+	return query.RoleBindingFilter{
+		SubjectID:     subjectID,
+		Action:        params.Get("action"),
+		CreatedBy:     createdBy,
+		CreatedAfter:  times.createdAfter,
+		CreatedBefore: times.createdBefore,
+		UpdatedAfter:  times.updatedAfter,
+		UpdatedBefore: times.updatedBefore,
+	}, nil
+}
+
+func parseFilterID(params url.Values, key string) (gidx.PrefixedID, error) {
+	value := params.Get(key)
+	if value == "" {
+		return "", nil
+	}
+
+	id, err := gidx.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %s", ErrInvalidFilter, key, err.Error())
+	}
+
+	return id, nil
+}
+
+func parseTimeRange(params url.Values) (timeRange, error) {
+	var (
+		times timeRange
+		err   error
+	)
+
+	for key, dst := range map[string]*time.Time{
+		"created_after":  &times.createdAfter,
+		"created_before": &times.createdBefore,
+		"updated_after":  &times.updatedAfter,
+		"updated_before": &times.updatedBefore,
+	} {
+		value := params.Get(key)
+		if value == "" {
+			continue
+		}
+
+		*dst, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return timeRange{}, fmt.Errorf("%w: %s: %s", ErrInvalidFilter, key, err.Error())
+		}
+	}
+
+	return times, nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestParseRoleBindingFilter(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []testingx.TestCase[string, query.RoleBindingFilter]{
+		{
+			Name:  "NoFilter",
+			Input: "",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[query.RoleBindingFilter]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, query.RoleBindingFilter{}, res.Success)
+			},
+		},
+		{
+			Name:  "AllFilters",
+			Input: "subject_id=idntusr-abc123&action=loadbalancer_get&created_by=idntusr-def456&created_after=2024-01-02T03:04:05Z&updated_before=2024-02-03T04:05:06Z",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[query.RoleBindingFilter]) {
+				require.NoError(t, res.Err)
+
+				expect := query.RoleBindingFilter{
+					SubjectID:     "idntusr-abc123",
+					Action:        "loadbalancer_get",
+					CreatedBy:     "idntusr-def456",
+					CreatedAfter:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+					UpdatedBefore: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+				}
+
+				assert.Equal(t, expect, res.Success)
+			},
+		},
+		{
+			Name:  "InvalidSubjectID",
+			Input: "subject_id=invalid",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[query.RoleBindingFilter]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidFilter)
+			},
+		},
+		{
+			Name:  "InvalidTime",
+			Input: "created_before=yesterday",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[query.RoleBindingFilter]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidFilter)
+			},
+		},
+	}
+
+	testFn := func(_ context.Context, input string) testingx.TestResult[query.RoleBindingFilter] {
+		req := httptest.NewRequest(http.MethodGet, "/?"+input, nil)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		filter, err := parseRoleBindingFilter(c)
+
+		return testingx.TestResult[query.RoleBindingFilter]{Success: filter, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
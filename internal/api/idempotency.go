@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// IdempotencyKeyHeader is the request header which makes role and role-binding creation idempotent,
+// including role-bindings which are requested for approval.
+// Retried create requests with the same key return the originally created object.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength is the length of the idempotency key storage columns.
+const maxIdempotencyKeyLength = 255
+
+// idempotentContext returns ctx carrying the request's idempotency key, if one was provided.
+func (r *Router) idempotentContext(ctx context.Context, c echo.Context) (context.Context, error) {
+	key := c.Request().Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return ctx, nil
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, r.errorResponse(
+			"error parsing "+IdempotencyKeyHeader+" header",
+			fmt.Errorf("%w: must be at most %d characters", ErrInvalidIdempotencyKey, maxIdempotencyKeyLength),
+		)
+	}
+
+	return query.ContextWithIdempotencyKey(ctx, key), nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestIdempotentContext(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []testingx.TestCase[string, context.Context]{
+		{
+			Name:  "NoKey",
+			Input: "",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[context.Context]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, ctx, res.Success)
+			},
+		},
+		{
+			Name:  "Key",
+			Input: "create-lb-viewer",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[context.Context]) {
+				require.NoError(t, res.Err)
+				assert.NotEqual(t, ctx, res.Success)
+			},
+		},
+		{
+			Name:  "KeyTooLong",
+			Input: strings.Repeat("k", maxIdempotencyKeyLength+1),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[context.Context]) {
+				var httpErr *echo.HTTPError
+
+				require.True(t, errors.As(res.Err, &httpErr))
+				assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, key string) testingx.TestResult[context.Context] {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		out, err := (&Router{}).idempotentContext(ctx, c)
+
+		return testingx.TestResult[context.Context]{Success: out, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestIdempotencyErrorResponse(t *testing.T) {
+	r := &Router{}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, r.errorResponse("error creating role", query.ErrIdempotencyKeyReused).Code)
+	assert.Equal(t, http.StatusConflict, r.errorResponse("error creating role", storage.ErrIdempotencyKeyTaken).Code)
+}
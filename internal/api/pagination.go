@@ -4,6 +4,8 @@ import (
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/query"
 )
 
 var (
@@ -13,19 +15,18 @@ var (
 	DefaultPaginationSize = 100
 )
 
-// Pagination allow you to paginate the results
+// Pagination allow you to paginate the results using an opaque cursor.
+// The cursor for the next page is returned in the next_cursor field of list responses.
 type Pagination struct {
-	Limit int
-	Page  int
-	Order string
+	Limit  int
+	Cursor string
 }
 
 // ParsePagination parses the pagination query parameters from the echo context
 func ParsePagination(c echo.Context) *Pagination {
 	// Initializing default
 	limit := DefaultPaginationSize
-	page := 1
-	order := ""
+	cursor := ""
 	query := c.Request().URL.Query()
 
 	for key, value := range query {
@@ -34,37 +35,17 @@ func ParsePagination(c echo.Context) *Pagination {
 		switch key {
 		case "limit":
 			limit, _ = strconv.Atoi(queryValue)
-		case "page":
-			page, _ = strconv.Atoi(queryValue)
-		case "order":
-			order = queryValue
+		case "cursor":
+			cursor = queryValue
 		}
 	}
 
 	return &Pagination{
-		Limit: parseLimit(limit),
-		Page:  page,
-		Order: order,
+		Limit:  parseLimit(limit),
+		Cursor: cursor,
 	}
 }
 
-// // queryMods converts the list params into sql conditions that can be added to sql queries
-// func (p *Pagination) QueryMods() []qm.QueryMod {
-// 	if p == nil {
-// 		p = &Pagination{}
-// 	}
-
-// 	mods := []qm.QueryMod{}
-
-// 	mods = append(mods, qm.Limit(p.Limit))
-
-// 	if p.Page != 0 {
-// 		mods = append(mods, qm.Offset(p.offset()))
-// 	}
-
-// 	return mods
-// }
-
 func parseLimit(l int) int {
 	limit := l
 
@@ -78,18 +59,10 @@ func parseLimit(l int) int {
 	return limit
 }
 
-// func (p *Pagination) offset() int {
-// 	page := p.Page
-// 	if page == 0 {
-// 		page = 1
-// 	}
-//
-// 	return (page - 1) * p.Limit
-// }
-
-// SetHeaders sets the pagination headers on a response
-func (p *Pagination) SetHeaders(c echo.Context, count int) {
-	c.Response().Header().Set("Pagination-Count", strconv.Itoa(count))
-	c.Response().Header().Set("Pagination-Limit", strconv.Itoa(p.Limit))
-	c.Response().Header().Set("Pagination-Page", strconv.Itoa(p.Page))
+// ListOptions returns the engine list options for the requested page.
+func (p *Pagination) ListOptions() query.ListOptions {
+	return query.ListOptions{
+		Limit:  p.Limit,
+		Cursor: p.Cursor,
+	}
 }
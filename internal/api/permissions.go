@@ -10,9 +10,12 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
+	"go.infratographer.com/permissions-api/internal/decisionlog"
 	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/types"
 )
@@ -37,11 +40,15 @@ var (
 // It will return a 403 if the subject is not allowed to perform the action on the resource.
 //
 // Note that this expects a JWT token to be present in the request. This token must
-// contain the subject of the request in the "sub" claim.
+// contain the subject of the request in the "sub" claim. Check delegates may provide
+// the subject_id query parameter to check permissions on behalf of another subject.
 //
 // The following query parameters are required:
 // - resource: the resource ID to check
 // - action: the action to check
+//
+// The following query parameters are optional:
+// - subject_id: the subject ID to check, only permitted for check delegates
 func (r *Router) checkAction(c echo.Context) error {
 	ctx, span := tracer.Start(c.Request().Context(), "api.checkAction")
 	defer span.End()
@@ -69,7 +76,7 @@ func (r *Router) checkAction(c echo.Context) error {
 	}
 
 	// Subject validation
-	subjectResource, err := r.currentSubject(c)
+	subjectResource, err := r.checkSubject(c)
 	if err != nil {
 		return err
 	}
@@ -83,8 +90,12 @@ func (r *Router) checkAction(c echo.Context) error {
 }
 
 func (r *Router) checkActionWithResponse(ctx context.Context, subjectResource types.Resource, action string, resource types.Resource) error {
-	err := r.engine.SubjectHasPermission(ctx, subjectResource, action, resource)
+	return actionErrorResponse(subjectResource, action, resource, r.subjectHasPermission(ctx, subjectResource, action, resource))
+}
 
+// actionErrorResponse returns the HTTP error for the result of checking the subject may perform
+// the action on the resource, or nil if the subject may.
+func actionErrorResponse(subjectResource types.Resource, action string, resource types.Resource, err error) error {
 	switch {
 	case errors.Is(err, query.ErrActionNotAssigned):
 		msg := fmt.Sprintf(
@@ -110,6 +121,47 @@ func (r *Router) checkActionWithResponse(ctx context.Context, subjectResource ty
 	}
 }
 
+// subjectHasPermission checks whether the subject may perform the action on the resource,
+// recording the decision with the decision logger if one is set.
+func (r *Router) subjectHasPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) error {
+	if r.decisionLogger == nil {
+		return r.engine.SubjectHasPermission(ctx, subject, action, resource)
+	}
+
+	var details query.CheckDetails
+
+	start := time.Now()
+
+	err := r.engine.SubjectHasPermission(query.ContextWithCheckDetails(ctx, &details), subject, action, resource)
+
+	decision := decisionlog.Decision{
+		Time:         start.UTC(),
+		SubjectID:    subject.ID,
+		Action:       action,
+		ResourceID:   resource.ID,
+		ResourceType: resource.Type,
+		Consistency:  details.Consistency,
+		ZedToken:     details.ZedToken,
+		Latency:      time.Since(start),
+	}
+
+	switch {
+	case err == nil:
+		decision.Outcome = decisionlog.OutcomeAllowed
+	case errors.Is(err, query.ErrActionNotAssigned):
+		decision.Outcome = decisionlog.OutcomeDenied
+	case errors.Is(err, query.ErrInvalidAction):
+		decision.Outcome = decisionlog.OutcomeInvalid
+	default:
+		decision.Outcome = decisionlog.OutcomeError
+		decision.Error = err.Error()
+	}
+
+	r.decisionLogger.LogDecision(ctx, decision)
+
+	return err
+}
+
 type checkPermissionsRequest struct {
 	Actions []checkAction `json:"actions"`
 }
@@ -148,13 +200,14 @@ type bulkCheckActionsResponse []checkActionResponse
 // It will return a 403 if the subject is not allowed to perform all requested resource actions.
 //
 // Note that this expects a JWT token to be present in the request. This token must
-// contain the subject of the request in the "sub" claim.
+// contain the subject of the request in the "sub" claim. Check delegates may provide
+// the subject_id query parameter to check permissions on behalf of another subject.
 func (r *Router) checkAllActions(c echo.Context) error {
 	ctx, span := tracer.Start(c.Request().Context(), "api.checkAllActions")
 	defer span.End()
 
 	// Subject validation
-	subjectResource, err := r.currentSubject(c)
+	subjectResource, err := r.checkSubject(c)
 	if err != nil {
 		return err
 	}
@@ -224,7 +277,7 @@ func (r *Router) checkAllActions(c echo.Context) error {
 					result.Request = check
 
 					// Check the permissions
-					err := r.engine.SubjectHasPermission(ctx, subjectResource, check.Action, check.Resource)
+					err := r.subjectHasPermission(ctx, subjectResource, check.Action, check.Resource)
 					if err != nil {
 						result.Error = err
 					}
@@ -336,7 +389,7 @@ func (r *Router) bulkCheckActions(c echo.Context) error {
 	defer span.End()
 
 	// Subject validation
-	subjectResource, err := r.currentSubject(c)
+	subjectResource, err := r.checkSubject(c)
 	if err != nil {
 		return err
 	}
@@ -415,7 +468,7 @@ func (r *Router) bulkCheckActions(c echo.Context) error {
 				Action:     action,
 			}
 
-			err := r.engine.SubjectHasPermission(ctxWithCancel, subjectResource, action, resource)
+			err := r.subjectHasPermission(ctxWithCancel, subjectResource, action, resource)
 
 			switch {
 			case errors.Is(err, query.ErrActionNotAssigned):
@@ -436,3 +489,39 @@ func (r *Router) bulkCheckActions(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, responses)
 }
+
+// effectiveActionsList returns the actions a subject is allowed to perform on a resource,
+// checked with a single bulk check rather than one check per action.
+//
+// Like checkAction, the actions are checked for the subject in the JWT token unless a
+// check delegate provides the subject_id query parameter.
+func (r *Router) effectiveActionsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(c.Request().Context(), "api.effectiveActionsList", trace.WithAttributes(attribute.String("id", resourceIDStr)))
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subjectResource, err := r.checkSubject(c)
+	if err != nil {
+		return err
+	}
+
+	actions, err := r.engine.EffectiveActions(ctx, subjectResource, resource)
+	if err != nil {
+		return r.errorResponse("error checking actions", err)
+	}
+
+	return c.JSON(http.StatusOK, listEffectiveActionsResponse{
+		Data: actions,
+	})
+}
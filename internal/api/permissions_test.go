@@ -0,0 +1,474 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/decisionlog"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestCheckActionSubject(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	delegate := gidx.PrefixedID("idntcli-delegate")
+
+	type testInput struct {
+		actor string
+		path  string
+	}
+
+	testCases := []testingx.TestCase[testInput, *httptest.ResponseRecorder]{
+		{
+			Name: "CurrentSubject",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+		{
+			Name: "SameSubject",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get&subject_id=idntusr-abc123",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+		{
+			Name: "NotDelegate",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get&subject_id=idntusr-def456",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+
+				var body ErrorResponse
+
+				require.NoError(t, json.Unmarshal(res.Success.Body.Bytes(), &body))
+				assert.Equal(t, ErrorCodeCheckDelegateNotAllowed, body.Code)
+			},
+		},
+		{
+			Name: "DelegateInvalidSubject",
+			Input: testInput{
+				actor: delegate.String(),
+				path:  "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get&subject_id=badid",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "Delegate",
+			Input: testInput{
+				actor: delegate.String(),
+				path:  "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get&subject_id=idntusr-def456",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine, WithCheckDelegates(delegate))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, input.path, nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, input.actor))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestEffectiveActionsList(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	delegate := gidx.PrefixedID("idntcli-delegate")
+
+	type testInput struct {
+		actor string
+		path  string
+	}
+
+	testCases := []testingx.TestCase[testInput, *httptest.ResponseRecorder]{
+		{
+			Name: "InvalidResourceID",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v2/resources/badid/effective-actions",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "CurrentSubject",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v2/resources/tnntten-abc123/effective-actions",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("EffectiveActions").Return([]string{"loadbalancer_get", "loadbalancer_list"}, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listEffectiveActionsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, []string{"loadbalancer_get", "loadbalancer_list"}, ret.Data)
+			},
+		},
+		{
+			Name: "NotDelegate",
+			Input: testInput{
+				actor: "idntusr-abc123",
+				path:  "/api/v2/resources/tnntten-abc123/effective-actions?subject_id=idntusr-def456",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+
+				var body ErrorResponse
+
+				require.NoError(t, json.Unmarshal(res.Success.Body.Bytes(), &body))
+				assert.Equal(t, ErrorCodeCheckDelegateNotAllowed, body.Code)
+			},
+		},
+		{
+			Name: "Delegate",
+			Input: testInput{
+				actor: delegate.String(),
+				path:  "/api/v2/resources/tnntten-abc123/effective-actions?subject_id=idntusr-def456",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("EffectiveActions").Return([]string{}, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine, WithCheckDelegates(delegate))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, input.path, nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, input.actor))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+// testDecisionLogger records the decisions logged.
+type testDecisionLogger struct {
+	mu        sync.Mutex
+	decisions []decisionlog.Decision
+}
+
+func (l *testDecisionLogger) LogDecision(_ context.Context, decision decisionlog.Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.decisions = append(l.decisions, decision)
+}
+
+func TestCheckActionDecisionLog(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type testInput struct {
+		err     error
+		outcome decisionlog.Outcome
+	}
+
+	testCases := []testingx.TestCase[testInput, *httptest.ResponseRecorder]{
+		{
+			Name:  "Allowed",
+			Input: testInput{outcome: decisionlog.OutcomeAllowed},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+		{
+			Name:  "Denied",
+			Input: testInput{err: query.ErrActionNotAssigned, outcome: decisionlog.OutcomeDenied},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+
+				var body ErrorResponse
+
+				require.NoError(t, json.Unmarshal(res.Success.Body.Bytes(), &body))
+				assert.Empty(t, body.Code)
+			},
+		},
+		{
+			Name:  "InvalidAction",
+			Input: testInput{err: query.ErrInvalidAction, outcome: decisionlog.OutcomeInvalid},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		engine.On("SubjectHasPermission").Return(input.err)
+
+		decisions := &testDecisionLogger{}
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, &engine, WithDecisionLogger(decisions))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get", nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		require.Len(t, decisions.decisions, 1)
+
+		decision := decisions.decisions[0]
+		assert.Equal(t, input.outcome, decision.Outcome)
+		assert.Equal(t, gidx.PrefixedID("idntusr-abc123"), decision.SubjectID)
+		assert.Equal(t, "loadbalancer_get", decision.Action)
+		assert.Equal(t, gidx.PrefixedID("tnntten-abc123"), decision.ResourceID)
+		assert.Equal(t, "tenant", decision.ResourceType)
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
@@ -1,17 +1,107 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"sync"
 
 	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 
 	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
 )
 
+// relationshipListActionFor returns the action subjects must hold on resources of the given type
+// to list their relationships.
+func (r *Router) relationshipListActionFor(resourceType string) string {
+	if action, ok := r.relationshipListActions[resourceType]; ok {
+		return action
+	}
+
+	return r.relationshipListAction
+}
+
+// checkRelationshipListAction checks the subject may list the relationships of the resource. Like the
+// resources on the other end of listed relationships, resources whose type does not define the list
+// action are not guarded by it.
+func (r *Router) checkRelationshipListAction(ctx context.Context, subject, resource types.Resource) error {
+	action := r.relationshipListActionFor(resource.Type)
+
+	err := r.subjectHasPermission(ctx, subject, action, resource)
+	if errors.Is(err, query.ErrInvalidAction) {
+		return nil
+	}
+
+	return actionErrorResponse(subject, action, resource, err)
+}
+
+// visibleResources returns the IDs of the resources on the other end of listed relationships which
+// the subject may see: the subject itself, resources the subject may list the relationships of, and
+// resources whose type does not define the relationship list action.
+func (r *Router) visibleResources(ctx context.Context, subject types.Resource, resources []types.Resource) (map[gidx.PrefixedID]struct{}, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		visible = make(map[gidx.PrefixedID]struct{}, len(resources))
+		checked = make(map[gidx.PrefixedID]struct{}, len(resources))
+		errs    []error
+		sem     = make(chan struct{}, r.concurrentChecks)
+	)
+
+	for _, resource := range resources {
+		if _, ok := checked[resource.ID]; ok {
+			continue
+		}
+
+		checked[resource.ID] = struct{}{}
+
+		if resource.ID == subject.ID {
+			visible[resource.ID] = struct{}{}
+
+			continue
+		}
+
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(resource types.Resource) {
+			defer func() {
+				<-sem
+
+				wg.Done()
+			}()
+
+			err := r.subjectHasPermission(ctx, subject, r.relationshipListActionFor(resource.Type), resource)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err == nil, errors.Is(err, query.ErrInvalidAction):
+				visible[resource.ID] = struct{}{}
+			case errors.Is(err, query.ErrActionNotAssigned):
+				// hidden from the subject
+			default:
+				errs = append(errs, err)
+			}
+		}(resource)
+	}
+
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		return nil, err
+	}
+
+	return visible, nil
+}
+
 func (r *Router) relationshipListFrom(c echo.Context) error {
 	resourceIDStr := c.Param("id")
 
@@ -28,22 +118,52 @@ func (r *Router) relationshipListFrom(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error listing relationships").SetInternal(err)
 	}
 
-	rels, err := r.engine.ListRelationshipsFrom(ctx, resource)
+	subjectResource, err := r.currentSubject(c)
 	if err != nil {
+		return err
+	}
+
+	if err := r.checkRelationshipListAction(ctx, subjectResource, resource); err != nil {
+		return err
+	}
+
+	rels, nextCursor, err := r.engine.ListRelationshipsFrom(ctx, resource, ParsePagination(c).ListOptions())
+
+	switch {
+	case err == nil:
+	case errors.Is(err, query.ErrInvalidCursor):
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor").SetInternal(err)
+	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
 	}
 
-	items := make([]relationshipItem, len(rels))
+	subjects := make([]types.Resource, len(rels))
 
 	for i, rel := range rels {
-		items[i] = relationshipItem{
+		subjects[i] = rel.Subject
+	}
+
+	visible, err := r.visibleResources(ctx, subjectResource, subjects)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
+	}
+
+	items := make([]relationshipItem, 0, len(rels))
+
+	for _, rel := range rels {
+		if _, ok := visible[rel.Subject.ID]; !ok {
+			continue
+		}
+
+		items = append(items, relationshipItem{
 			Relation:  rel.Relation,
 			SubjectID: rel.Subject.ID.String(),
-		}
+		})
 	}
 
 	out := listRelationshipsResponse{
-		Data: items,
+		Data:       items,
+		NextCursor: nextCursor,
 	}
 
 	return c.JSON(http.StatusOK, out)
@@ -65,27 +185,54 @@ func (r *Router) relationshipListTo(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error listing relationships").SetInternal(err)
 	}
 
-	rels, err := r.engine.ListRelationshipsTo(ctx, resource)
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkRelationshipListAction(ctx, subjectResource, resource); err != nil {
+		return err
+	}
+
+	rels, nextCursor, err := r.engine.ListRelationshipsTo(ctx, resource, ParsePagination(c).ListOptions())
 
 	switch {
 	case err == nil:
 	case errors.Is(err, query.ErrInvalidType):
 		return echo.NewHTTPError(http.StatusBadRequest, "resource doesn't support relationships")
+	case errors.Is(err, query.ErrInvalidCursor):
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor").SetInternal(err)
 	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
 	}
 
-	items := make([]relationshipItem, len(rels))
+	resources := make([]types.Resource, len(rels))
 
 	for i, rel := range rels {
-		items[i] = relationshipItem{
+		resources[i] = rel.Resource
+	}
+
+	visible, err := r.visibleResources(ctx, subjectResource, resources)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
+	}
+
+	items := make([]relationshipItem, 0, len(rels))
+
+	for _, rel := range rels {
+		if _, ok := visible[rel.Resource.ID]; !ok {
+			continue
+		}
+
+		items = append(items, relationshipItem{
 			ResourceID: rel.Resource.ID.String(),
 			Relation:   rel.Relation,
-		}
+		})
 	}
 
 	out := listRelationshipsResponse{
-		Data: items,
+		Data:       items,
+		NextCursor: nextCursor,
 	}
 
 	return c.JSON(http.StatusOK, out)
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRelationshipsList(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	tenant := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-abc123")}
+	parent := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-def456")}
+	child := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-ghi789")}
+	caller := types.Resource{Type: "user", ID: gidx.PrefixedID("idntusr-abc123")}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "Denied",
+			Input: "/api/v1/relationships/from/tnntten-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name:  "FromFiltersSubjects",
+			Input: "/api/v1/resources/tnntten-abc123/relationships",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				// allowed on the resource, denied on the parent
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned).Once()
+				engine.On("ListRelationshipsFrom").Return([]types.Relationship{
+					{Resource: tenant, Relation: "parent", Subject: parent},
+					{Resource: tenant, Relation: "member", Subject: caller},
+				}, "", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listRelationshipsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, caller.ID.String(), ret.Data[0].SubjectID)
+			},
+		},
+		{
+			Name:  "ToUndefinedAction",
+			Input: "/api/v1/relationships/to/tnntten-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				// allowed on the resource, the child's type does not define the action
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("SubjectHasPermission").Return(query.ErrInvalidAction).Once()
+				engine.On("ListRelationshipsTo").Return([]types.Relationship{
+					{Resource: child, Relation: "parent", Subject: tenant},
+				}, "", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listRelationshipsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, child.ID.String(), ret.Data[0].ResourceID)
+			},
+		},
+		{
+			Name:  "FromUndefinedAction",
+			Input: "/api/v1/relationships/from/tnntten-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				// the resource's type does not define the action, so it is treated like the subjects
+				engine.On("SubjectHasPermission").Return(query.ErrInvalidAction).Once()
+				engine.On("ListRelationshipsFrom").Return([]types.Relationship{
+					{Resource: tenant, Relation: "member", Subject: caller},
+				}, "", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNumberOfCalls(t, "SubjectHasPermission", 1)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listRelationshipsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, caller.ID.String(), ret.Data[0].SubjectID)
+			},
+		},
+		{
+			Name:  "CheckError",
+			Input: "/api/v1/relationships/to/tnntten-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("SubjectHasPermission").Return(errors.New("unavailable")).Once()
+				engine.On("ListRelationshipsTo").Return([]types.Relationship{
+					{Resource: child, Relation: "parent", Subject: tenant},
+				}, "", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusInternalServerError, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, path string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, caller.ID.String()))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRelationshipListActionFor(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+
+	router, err := NewRouter(
+		echojwtx.AuthConfig{Issuer: authsrv.Issuer}, &mock.Engine{},
+		WithRelationshipListActions(map[string]string{"tenant": "tenant_get"}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant_get", router.relationshipListActionFor("tenant"))
+	assert.Equal(t, "iam_rolebinding_list", router.relationshipListActionFor("loadbalancer"))
+}
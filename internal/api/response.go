@@ -17,8 +17,14 @@ import (
 type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
 }
 
+// ErrorCodeCheckDelegateNotAllowed is the code of the 403 response returned when a subject
+// which is not a check delegate checks permissions on behalf of another subject. It tells
+// this misconfiguration apart from a denied permission check.
+const ErrorCodeCheckDelegateNotAllowed = "check_delegate_not_allowed"
+
 var (
 	// ErrResourceNotFound is returned when the requested resource isn't found
 	ErrResourceNotFound = errors.New("resource not found")
@@ -41,18 +47,40 @@ func (r *Router) errorResponse(basemsg string, err error) *echo.HTTPError {
 		errors.Is(err, query.ErrInvalidAction),
 		errors.Is(err, query.ErrInvalidNamespace),
 		errors.Is(err, ErrInvalidID),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrInvalidBatch),
+		errors.Is(err, ErrInvalidIdempotencyKey),
+		errors.Is(err, ErrInvalidDuration),
 		status.Code(err) == codes.InvalidArgument,
 		status.Code(err) == codes.FailedPrecondition:
 		httpstatus = http.StatusBadRequest
 	case
 		errors.Is(err, storage.ErrNoRoleFound),
 		errors.Is(err, query.ErrRoleNotFound),
-		errors.Is(err, query.ErrRoleBindingNotFound):
+		errors.Is(err, query.ErrRoleBindingNotFound),
+		errors.Is(err, query.ErrRoleBindingRequestNotFound),
+		errors.Is(err, query.ErrAccessRequestNotFound),
+		errors.Is(err, query.ErrAccessReviewNotFound),
+		errors.Is(err, query.ErrAccessReviewItemNotFound),
+		errors.Is(err, query.ErrBreakGlassNotConfigured):
 		httpstatus = http.StatusNotFound
 	case
 		errors.Is(err, storage.ErrRoleAlreadyExists),
-		errors.Is(err, storage.ErrRoleNameTaken):
+		errors.Is(err, storage.ErrRoleNameTaken),
+		errors.Is(err, storage.ErrIdempotencyKeyTaken),
+		errors.Is(err, query.ErrRoleBindingRequestNotPending),
+		errors.Is(err, query.ErrAccessRequestNotPending),
+		errors.Is(err, query.ErrAccessReviewClosed):
 		httpstatus = http.StatusConflict
+	case
+		errors.Is(err, query.ErrPrivilegeEscalation),
+		errors.Is(err, query.ErrRoleBindingRequestSelfReview),
+		errors.Is(err, query.ErrAccessRequestReviewForbidden):
+		httpstatus = http.StatusForbidden
+	case errors.Is(err, query.ErrIdempotencyKeyReused):
+		httpstatus = http.StatusUnprocessableEntity
+	case errors.Is(err, query.ErrPreconditionFailed):
+		httpstatus = http.StatusPreconditionFailed
 	default:
 		msg = basemsg
 	}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func roleBindingRequestResp(req types.RoleBindingRequest) roleBindingRequestResponse {
+	return roleBindingRequestResponse{
+		ID:            req.ID,
+		ResourceID:    req.ResourceID,
+		RoleID:        req.RoleID,
+		Manager:       req.Manager,
+		SubjectIDs:    req.SubjectIDs,
+		Status:        string(req.Status),
+		RoleBindingID: req.RoleBindingID,
+
+		RequestedBy: req.RequestedBy,
+		ReviewedBy:  req.ReviewedBy,
+		CreatedAt:   req.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   req.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func parseRoleBindingRequestStatus(c echo.Context) (types.RoleBindingRequestStatus, error) {
+	status := types.RoleBindingRequestStatus(c.QueryParam("status"))
+
+	switch status {
+	case "", types.RoleBindingRequestPending, types.RoleBindingRequestApproved, types.RoleBindingRequestRejected:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: status: unknown status %q", ErrInvalidFilter, status)
+	}
+}
+
+// roleBindingRequestsList lists the role-binding requests on a resource, optionally filtered by status.
+// Requests may be listed by callers which may list role-bindings on the resource.
+func (r *Router) roleBindingRequestsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingRequestsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, subjectResource, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	status, err := parseRoleBindingRequestStatus(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	reqs, nextCursor, err := r.engine.ListRoleBindingRequests(ctx, resource, status, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing role-binding requests", err)
+	}
+
+	resp := listRoleBindingRequestsResponse{
+		Data:       make([]roleBindingRequestResponse, len(reqs)),
+		NextCursor: nextCursor,
+	}
+
+	for i, req := range reqs {
+		resp.Data[i] = roleBindingRequestResp(req)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// roleBindingRequestGet fetches a role-binding request.
+// Requests may be fetched by callers which may get role-bindings on the request's resource.
+func (r *Router) roleBindingRequestGet(c echo.Context) error {
+	reqIDStr := c.Param("rbr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingRequestGet",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	_, req, err := r.authorizeRoleBindingRequest(ctx, c, reqIDStr, string(iapl.RoleBindingActionGet))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, roleBindingRequestResp(req))
+}
+
+// roleBindingRequestApprove approves a pending role-binding request, creating its role-binding.
+// Requests may be approved by subjects other than the requester which may approve
+// role-bindings on the request's resource.
+func (r *Router) roleBindingRequestApprove(c echo.Context) error {
+	reqIDStr := c.Param("rbr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingRequestApprove",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	actor, req, err := r.authorizeRoleBindingRequest(ctx, c, reqIDStr, string(iapl.RoleBindingActionApprove))
+	if err != nil {
+		return err
+	}
+
+	req, err = r.engine.ApproveRoleBindingRequest(ctx, actor, req.ID)
+	if err != nil {
+		return r.errorResponse("error approving role-binding request", err)
+	}
+
+	return c.JSON(http.StatusOK, roleBindingRequestResp(req))
+}
+
+// roleBindingRequestReject rejects a pending role-binding request.
+// Requests may be rejected by subjects other than the requester which may approve
+// role-bindings on the request's resource.
+func (r *Router) roleBindingRequestReject(c echo.Context) error {
+	reqIDStr := c.Param("rbr_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingRequestReject",
+		trace.WithAttributes(attribute.String("id", reqIDStr)),
+	)
+	defer span.End()
+
+	actor, req, err := r.authorizeRoleBindingRequest(ctx, c, reqIDStr, string(iapl.RoleBindingActionApprove))
+	if err != nil {
+		return err
+	}
+
+	req, err = r.engine.RejectRoleBindingRequest(ctx, actor, req.ID)
+	if err != nil {
+		return r.errorResponse("error rejecting role-binding request", err)
+	}
+
+	return c.JSON(http.StatusOK, roleBindingRequestResp(req))
+}
+
+// authorizeRoleBindingRequest fetches a role-binding request and ensures the current subject
+// may perform the action on the request's resource.
+func (r *Router) authorizeRoleBindingRequest(
+	ctx context.Context, c echo.Context, reqIDStr, action string,
+) (types.Resource, types.RoleBindingRequest, error) {
+	reqID, err := gidx.Parse(reqIDStr)
+	if err != nil {
+		return types.Resource{}, types.RoleBindingRequest{}, r.errorResponse("error parsing role-binding request ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, types.RoleBindingRequest{}, err
+	}
+
+	req, err := r.engine.GetRoleBindingRequest(ctx, reqID)
+	if err != nil {
+		return types.Resource{}, types.RoleBindingRequest{}, r.errorResponse("error getting role-binding request", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return types.Resource{}, types.RoleBindingRequest{}, r.errorResponse("error creating resource", err)
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, action, resource); err != nil {
+		return types.Resource{}, types.RoleBindingRequest{}, err
+	}
+
+	return actor, req, nil
+}
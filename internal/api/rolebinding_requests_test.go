@@ -0,0 +1,328 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRoleBindingRequests(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type input struct {
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+	}
+
+	pending := types.RoleBindingRequest{
+		ID:          "permrbr-abc123",
+		ResourceID:  "tnntten-abc123",
+		RoleID:      "permrol-abc123",
+		SubjectIDs:  []gidx.PrefixedID{"idntusr-def456"},
+		Status:      types.RoleBindingRequestPending,
+		RequestedBy: "idntusr-abc123",
+	}
+
+	approved := pending
+	approved.Status = types.RoleBindingRequestApproved
+	approved.RoleBindingID = "permrbn-abc123"
+	approved.ReviewedBy = "idntusr-abc123"
+
+	newEngine := func(ctx context.Context, setup func(*mock.Engine)) context.Context {
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		setup(&engine)
+
+		return context.WithValue(ctx, contextKeyEngine, &engine)
+	}
+
+	testCases := []testingx.TestCase[input, *httptest.ResponseRecorder]{
+		{
+			Name: "CreateRequiresApproval",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/resources/tnntten-abc123/role-bindings",
+				body:   `{"role_id":"permrol-abc123","subject_ids":["idntusr-def456"]}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("RoleBindingRequiresApproval").Return(true, nil)
+					e.On("CreateRoleBindingRequest").Return(pending, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "CreateRoleBinding")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusAccepted, res.Success.Code)
+
+				var ret roleBindingRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, pending.ID, ret.ID)
+				assert.Equal(t, "pending", ret.Status)
+			},
+		},
+		{
+			Name: "CreateRequiresApprovalInvalidIdempotencyKey",
+			Input: input{
+				method:  http.MethodPost,
+				path:    "/api/v2/resources/tnntten-abc123/role-bindings",
+				body:    `{"role_id":"permrol-abc123","subject_ids":["idntusr-def456"]}`,
+				headers: map[string]string{IdempotencyKeyHeader: strings.Repeat("k", maxIdempotencyKeyLength+1)},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("RoleBindingRequiresApproval").Return(true, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "CreateRoleBindingRequest")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "CreateRequiresApprovalIdempotencyKeyReused",
+			Input: input{
+				method:  http.MethodPost,
+				path:    "/api/v2/resources/tnntten-abc123/role-bindings",
+				body:    `{"role_id":"permrol-abc123","subject_ids":["idntusr-def456"]}`,
+				headers: map[string]string{IdempotencyKeyHeader: "request-lb-admin"},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("RoleBindingRequiresApproval").Return(true, nil)
+					e.On("CreateRoleBindingRequest").Return(types.RoleBindingRequest{}, query.ErrIdempotencyKeyReused)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusUnprocessableEntity, res.Success.Code)
+			},
+		},
+		{
+			Name: "Approve",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/role-binding-requests/permrbr-abc123/approve",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetRoleBindingRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ApproveRoleBindingRequest").Return(approved, nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret roleBindingRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Equal(t, "approved", ret.Status)
+				assert.Equal(t, approved.RoleBindingID, ret.RoleBindingID)
+			},
+		},
+		{
+			Name: "ApproveDenied",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/role-binding-requests/permrbr-abc123/approve",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetRoleBindingRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "ApproveRoleBindingRequest")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "RejectOwnRequest",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/role-binding-requests/permrbr-abc123/reject",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetRoleBindingRequest").Return(pending, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("RejectRoleBindingRequest").Return(types.RoleBindingRequest{}, query.ErrRoleBindingRequestSelfReview)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "ApproveReviewed",
+			Input: input{
+				method: http.MethodPost,
+				path:   "/api/v2/role-binding-requests/permrbr-abc123/approve",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("GetRoleBindingRequest").Return(approved, nil)
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ApproveRoleBindingRequest").Return(types.RoleBindingRequest{}, query.ErrRoleBindingRequestNotPending)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusConflict, res.Success.Code)
+			},
+		},
+		{
+			Name: "ListInvalidStatus",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/resources/tnntten-abc123/role-binding-requests?status=unknown",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "ListPending",
+			Input: input{
+				method: http.MethodGet,
+				path:   "/api/v2/resources/tnntten-abc123/role-binding-requests?status=pending",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				return newEngine(ctx, func(e *mock.Engine) {
+					e.On("SubjectHasPermission").Return(nil)
+					e.On("ListRoleBindingRequests").Return([]types.RoleBindingRequest{pending}, "", nil)
+				})
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listRoleBindingRequestsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+				assert.Equal(t, pending.ID, ret.Data[0].ID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, in.method, in.path, bytes.NewBufferString(in.body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Content-Type", "application/json")
+
+		for k, v := range in.headers {
+			req.Header.Set(k, v)
+		}
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
@@ -73,11 +75,33 @@ func (r *Router) roleBindingCreate(c echo.Context) error {
 		}
 	}
 
+	requiresApproval, err := r.engine.RoleBindingRequiresApproval(ctx, roleResource)
+	if err != nil {
+		return r.errorResponse("error checking role-binding approval", err)
+	}
+
+	ctx, err = r.idempotentContext(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// role-bindings granting sensitive actions are only created once another subject approves them
+	if requiresApproval {
+		req, err := r.engine.CreateRoleBindingRequest(ctx, actor, resource, roleResource, body.Manager, subjects)
+		if err != nil {
+			return r.errorResponse("error creating role-binding request", err)
+		}
+
+		return c.JSON(http.StatusAccepted, roleBindingRequestResp(req))
+	}
+
 	rb, err := r.engine.CreateRoleBinding(ctx, actor, resource, roleResource, body.Manager, subjects)
 	if err != nil {
 		return r.errorResponse("error creating role-binding", err)
 	}
 
+	setETag(c, rb.UpdatedAt)
+
 	return c.JSON(
 		http.StatusCreated,
 		roleBindingResponse{
@@ -123,14 +147,23 @@ func (r *Router) roleBindingsList(c echo.Context) error {
 		return err
 	}
 
-	var rbs []types.RoleBinding
+	filter, err := parseRoleBindingFilter(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	var (
+		rbs        []types.RoleBinding
+		nextCursor string
+	)
 
 	params := c.QueryParams()
+	listOpts := ParsePagination(c).ListOptions()
 
 	if params.Has("manager") {
-		rbs, err = r.engine.ListManagerRoleBindings(ctx, params.Get("manager"), resource, nil)
+		rbs, nextCursor, err = r.engine.ListManagerRoleBindings(ctx, params.Get("manager"), resource, nil, filter, listOpts)
 	} else {
-		rbs, err = r.engine.ListRoleBindings(ctx, resource, nil)
+		rbs, nextCursor, err = r.engine.ListRoleBindings(ctx, resource, nil, filter, listOpts)
 	}
 
 	if err != nil {
@@ -138,7 +171,8 @@ func (r *Router) roleBindingsList(c echo.Context) error {
 	}
 
 	resp := listRoleBindingsResponse{
-		Data: make([]roleBindingResponse, len(rbs)),
+		Data:       make([]roleBindingResponse, len(rbs)),
+		NextCursor: nextCursor,
 	}
 
 	for i, rb := range rbs {
@@ -159,6 +193,95 @@ func (r *Router) roleBindingsList(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+func (r *Router) subjectRoleBindingsList(c echo.Context) error {
+	subjectIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.subjectRoleBindingsList",
+		trace.WithAttributes(attribute.String("id", subjectIDStr)),
+	)
+	defer span.End()
+
+	subjectID, err := gidx.Parse(subjectIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing subject ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	subject, err := r.engine.NewResourceFromID(subjectID)
+	if err != nil {
+		return r.errorResponse("error creating subject resource", err)
+	}
+
+	currentSubject, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	rbs, nextCursor, err := r.engine.ListSubjectRoleBindings(ctx, subject, ParsePagination(c).ListOptions())
+	if err != nil {
+		return r.errorResponse("error listing role-binding", err)
+	}
+
+	resp := listSubjectRoleBindingsResponse{
+		Data:       make([]subjectRoleBindingResponse, 0, len(rbs)),
+		NextCursor: nextCursor,
+	}
+
+	// subjects may list their own role-bindings, other subjects' role-bindings
+	// are only listed on resources where role-bindings may be listed.
+	allowed := map[gidx.PrefixedID]bool{}
+
+	for _, rb := range rbs {
+		if currentSubject.ID != subject.ID {
+			ok, checked := allowed[rb.Resource.ID]
+			if !checked {
+				err := r.subjectHasPermission(ctx, currentSubject, string(iapl.RoleBindingActionList), rb.Resource)
+
+				switch {
+				case err == nil:
+					ok = true
+				case errors.Is(err, query.ErrActionNotAssigned):
+					ok = false
+				default:
+					return echo.NewHTTPError(http.StatusInternalServerError, "an error occurred checking permissions").SetInternal(err)
+				}
+
+				allowed[rb.Resource.ID] = ok
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		resp.Data = append(resp.Data, subjectRoleBindingResponse{
+			roleBindingResponse: roleBindingResponse{
+				ID:         rb.ID,
+				ResourceID: rb.ResourceID,
+				SubjectIDs: rb.SubjectIDs,
+				RoleID:     rb.RoleID,
+				Manager:    rb.Manager,
+
+				CreatedBy: rb.CreatedBy,
+				UpdatedBy: rb.UpdatedBy,
+				CreatedAt: rb.CreatedAt.Format(time.RFC3339),
+				UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+			},
+			Role: subjectRoleBindingRole{
+				ID:   rb.Role.ID,
+				Name: rb.Role.Name,
+			},
+			Resource: subjectRoleBindingResource{
+				ID:   rb.Resource.ID,
+				Type: rb.Resource.Type,
+			},
+			GroupIDs: rb.GroupIDs,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 func (r *Router) roleBindingDelete(c echo.Context) error {
 	rbID := c.Param("rb_id")
 
@@ -195,7 +318,7 @@ func (r *Router) roleBindingDelete(c echo.Context) error {
 		return err
 	}
 
-	if err := r.engine.DeleteRoleBinding(ctx, rbRes); err != nil {
+	if err := r.engine.DeleteRoleBinding(ifMatchContext(ctx, c), rbRes); err != nil {
 		return r.errorResponse("error updating role-binding", err)
 	}
 
@@ -246,6 +369,8 @@ func (r *Router) roleBindingGet(c echo.Context) error {
 		return err
 	}
 
+	setETag(c, rb.UpdatedAt)
+
 	return c.JSON(
 		http.StatusOK,
 		roleBindingResponse{
@@ -321,11 +446,13 @@ func (r *Router) roleBindingUpdate(c echo.Context) error {
 		}
 	}
 
-	rb, err := r.engine.UpdateRoleBinding(ctx, actor, rbRes, subjects)
+	rb, err := r.engine.UpdateRoleBinding(ifMatchContext(ctx, c), actor, rbRes, subjects)
 	if err != nil {
 		return r.errorResponse("error updating role-binding", err)
 	}
 
+	setETag(c, rb.UpdatedAt)
+
 	return c.JSON(
 		http.StatusOK,
 		roleBindingResponse{
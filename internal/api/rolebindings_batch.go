@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// MaxRoleBindingBatchSize is the maximum number of operations accepted by a single role-binding batch request.
+var MaxRoleBindingBatchSize = 100
+
+func (r *Router) roleBindingsBatch(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.roleBindingsBatch")
+	defer span.End()
+
+	var body roleBindingBatchRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	span.SetAttributes(attribute.Int("operations", len(body.Operations)))
+
+	if len(body.Operations) == 0 || len(body.Operations) > MaxRoleBindingBatchSize {
+		return r.errorResponse(
+			"error processing batch",
+			fmt.Errorf("%w: between 1 and %d operations are required", ErrInvalidBatch, MaxRoleBindingBatchSize),
+		)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	ops := make([]query.RoleBindingOperation, len(body.Operations))
+
+	for i, reqOp := range body.Operations {
+		op, err := r.roleBindingBatchOperation(c, actor, reqOp)
+		if err != nil {
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				httpErr.Message = fmt.Sprintf("operation %d: %v", i, httpErr.Message)
+			}
+
+			return err
+		}
+
+		ops[i] = op
+	}
+
+	rbs, err := r.engine.BatchRoleBindings(ctx, actor, ops)
+	if err != nil {
+		return r.errorResponse("error applying role-binding batch", err)
+	}
+
+	resp := roleBindingBatchResponse{
+		Data: make([]roleBindingBatchResult, len(rbs)),
+	}
+
+	for i, rb := range rbs {
+		resp.Data[i] = roleBindingBatchResult{
+			Op: string(ops[i].Op),
+			ID: rb.ID,
+		}
+
+		if ops[i].Op == query.RoleBindingOpDelete {
+			continue
+		}
+
+		resp.Data[i].RoleBinding = &roleBindingResponse{
+			ID:         rb.ID,
+			ResourceID: rb.ResourceID,
+			SubjectIDs: rb.SubjectIDs,
+			RoleID:     rb.RoleID,
+			Manager:    rb.Manager,
+
+			CreatedBy: rb.CreatedBy,
+			UpdatedBy: rb.UpdatedBy,
+			CreatedAt: rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// roleBindingBatchOperation converts a batch request operation into an engine operation,
+// checking the actor is allowed to perform it.
+func (r *Router) roleBindingBatchOperation(c echo.Context, actor types.Resource, reqOp roleBindingBatchOperation) (query.RoleBindingOperation, error) {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingBatchOperation",
+		trace.WithAttributes(attribute.String("op", reqOp.Op)),
+	)
+	defer span.End()
+
+	op := query.RoleBindingOperation{
+		Op:      query.RoleBindingOp(reqOp.Op),
+		Manager: reqOp.Manager,
+	}
+
+	var (
+		resource types.Resource
+		action   iapl.RoleBindingAction
+		err      error
+	)
+
+	switch op.Op {
+	case query.RoleBindingOpCreate:
+		resource, err = r.parseResource("resource", reqOp.ResourceID)
+		if err != nil {
+			return op, err
+		}
+
+		op.Resource = resource
+
+		op.Role, err = r.parseResource("role", reqOp.RoleID)
+		if err != nil {
+			return op, err
+		}
+
+		action = iapl.RoleBindingActionCreate
+	case query.RoleBindingOpUpdate, query.RoleBindingOpDelete:
+		op.RoleBinding, err = r.parseResource("role-binding", reqOp.RoleBindingID)
+		if err != nil {
+			return op, err
+		}
+
+		resource, err = r.engine.GetRoleBindingResource(ctx, op.RoleBinding)
+		if err != nil {
+			return op, r.errorResponse("error getting role-binding owner resource", err)
+		}
+
+		action = iapl.RoleBindingActionUpdate
+		if op.Op == query.RoleBindingOpDelete {
+			action = iapl.RoleBindingActionDelete
+		}
+	default:
+		return op, r.errorResponse("error processing batch", fmt.Errorf("%w: unknown operation %q", ErrInvalidBatch, reqOp.Op))
+	}
+
+	// permissions on role binding actions, similar to roles v1, are granted on the resources
+	if err := r.checkActionWithResponse(ctx, actor, string(action), resource); err != nil {
+		return op, err
+	}
+
+	if op.Op == query.RoleBindingOpDelete {
+		return op, nil
+	}
+
+	op.Subjects = make([]types.RoleBindingSubject, len(reqOp.SubjectIDs))
+
+	for i, sid := range reqOp.SubjectIDs {
+		subj, err := r.engine.NewResourceFromID(sid)
+		if err != nil {
+			return op, r.errorResponse("error creating subject resource", err)
+		}
+
+		op.Subjects[i] = types.RoleBindingSubject{
+			SubjectResource: subj,
+		}
+	}
+
+	return op, nil
+}
+
+// parseResource parses the named resource ID from a request body into a resource.
+func (r *Router) parseResource(name, idStr string) (types.Resource, error) {
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return types.Resource{}, r.errorResponse("error parsing "+name+" ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(id)
+	if err != nil {
+		return types.Resource{}, r.errorResponse("error creating "+name+" resource", err)
+	}
+
+	return resource, nil
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestSubjectRoleBindingsList(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	rbs := []types.SubjectRoleBinding{
+		{
+			RoleBinding: types.RoleBinding{
+				ID:         "permrbn-abc123",
+				ResourceID: "tnntten-abc123",
+				RoleID:     "permrv2-abc123",
+			},
+			Role:     types.Role{ID: "permrv2-abc123", Name: "lb_viewer"},
+			Resource: types.Resource{Type: "tenant", ID: "tnntten-abc123"},
+			GroupIDs: []gidx.PrefixedID{"idntgrp-abc123"},
+		},
+	}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "InvalidSubjectID",
+			Input: "/api/v2/subjects/badid/role-bindings",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name:  "OwnRoleBindings",
+			Input: "/api/v2/subjects/idntusr-abc123/role-bindings",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("ListSubjectRoleBindings").Return(rbs, "", nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listSubjectRoleBindingsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+
+				assert.Equal(t, "lb_viewer", ret.Data[0].Role.Name)
+				assert.Equal(t, "tenant", ret.Data[0].Resource.Type)
+				assert.Equal(t, rbs[0].GroupIDs, ret.Data[0].GroupIDs)
+			},
+		},
+		{
+			Name:  "OtherSubjectAllowed",
+			Input: "/api/v2/subjects/idntusr-def456/role-bindings",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("ListSubjectRoleBindings").Return(rbs, "", nil)
+				engine.On("SubjectHasPermission").Return(nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listSubjectRoleBindingsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Len(t, ret.Data, 1)
+			},
+		},
+		{
+			Name:  "OtherSubjectDenied",
+			Input: "/api/v2/subjects/idntusr-def456/role-bindings",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("ListSubjectRoleBindings").Return(rbs, "", nil)
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret listSubjectRoleBindingsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				assert.Empty(t, ret.Data)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, path string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRoleBindingsBatch(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	testCases := []testingx.TestCase[any, *httptest.ResponseRecorder]{
+		{
+			Name:  "NoOperations",
+			Input: map[string]any{"operations": []any{}},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "UnknownOperation",
+			Input: map[string]any{
+				"operations": []any{
+					map[string]any{"op": "rename", "rolebinding_id": "permrbn-abc123"},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+				assert.Contains(t, res.Success.Body.String(), "operation 0")
+			},
+		},
+		{
+			Name: "Denied",
+			Input: map[string]any{
+				"operations": []any{
+					map[string]any{
+						"op":          "create",
+						"resource_id": "tnntten-abc123",
+						"role_id":     "permrol-abc123",
+						"subject_ids": []string{"idntusr-def456"},
+					},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "Applied",
+			Input: map[string]any{
+				"operations": []any{
+					map[string]any{
+						"op":          "create",
+						"resource_id": "tnntten-abc123",
+						"role_id":     "permrol-abc123",
+						"subject_ids": []string{"idntusr-def456"},
+					},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("BatchRoleBindings").Return([]types.RoleBinding{
+					{ID: "permrbn-abc123", ResourceID: "tnntten-abc123", RoleID: "permrol-abc123"},
+				}, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var ret roleBindingBatchResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&ret))
+				require.Len(t, ret.Data, 1)
+
+				assert.Equal(t, "create", ret.Data[0].Op)
+				assert.Equal(t, gidx.PrefixedID("permrbn-abc123"), ret.Data[0].ID)
+				require.NotNil(t, ret.Data[0].RoleBinding)
+				assert.Equal(t, gidx.PrefixedID("permrol-abc123"), ret.Data[0].RoleBinding.RoleID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input any) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		body, err := json.Marshal(input)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/v2/role-bindings/batch", bytes.NewBuffer(body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestPrivilegeEscalationErrorResponse(t *testing.T) {
+	r := &Router{}
+
+	assert.Equal(t, http.StatusForbidden, r.errorResponse("error creating role-binding", query.ErrPrivilegeEscalation).Code)
+	assert.Equal(t, http.StatusInternalServerError, r.errorResponse("error creating role-binding", query.ErrActionNotAssigned).Code)
+}
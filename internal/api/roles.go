@@ -242,22 +242,31 @@ func (r *Router) rolesList(c echo.Context) error {
 		return err
 	}
 
-	var roles []types.Role
+	var (
+		roles      []types.Role
+		nextCursor string
+	)
 
 	params := c.QueryParams()
+	listOpts := ParsePagination(c).ListOptions()
 
 	if params.Has("manager") {
-		roles, err = r.engine.ListManagerRoles(ctx, params.Get("manager"), resource)
+		roles, nextCursor, err = r.engine.ListManagerRoles(ctx, params.Get("manager"), resource, listOpts)
 	} else {
-		roles, err = r.engine.ListRoles(ctx, resource)
+		roles, nextCursor, err = r.engine.ListRoles(ctx, resource, listOpts)
 	}
 
-	if err != nil {
+	switch {
+	case err == nil:
+	case errors.Is(err, query.ErrInvalidCursor):
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor").SetInternal(err)
+	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error getting role").SetInternal(err)
 	}
 
 	resp := listRolesResponse{
-		Data: []roleResponse{},
+		Data:       []roleResponse{},
+		NextCursor: nextCursor,
 	}
 
 	for _, role := range roles {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/types"
 
 	"github.com/labstack/echo/v4"
@@ -47,6 +48,11 @@ func (r *Router) roleV2Create(c echo.Context) error {
 		return err
 	}
 
+	ctx, err = r.idempotentContext(ctx, c)
+	if err != nil {
+		return err
+	}
+
 	role, err := r.engine.CreateRoleV2(
 		ctx, subjectResource, resource, reqBody.Manager,
 		strings.TrimSpace(reqBody.Name), reqBody.Actions,
@@ -60,6 +66,7 @@ func (r *Router) roleV2Create(c echo.Context) error {
 		Name:       role.Name,
 		Manager:    role.Manager,
 		Actions:    role.Actions,
+		Includes:   role.Includes,
 		ResourceID: role.ResourceID,
 		CreatedBy:  role.CreatedBy,
 		UpdatedBy:  role.UpdatedBy,
@@ -67,6 +74,8 @@ func (r *Router) roleV2Create(c echo.Context) error {
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
 	}
 
+	setETag(c, role.UpdatedAt)
+
 	return c.JSON(http.StatusCreated, resp)
 }
 
@@ -104,19 +113,43 @@ func (r *Router) roleV2Update(c echo.Context) error {
 		return err
 	}
 
+	var includes []types.Resource
+
+	if reqBody.Includes != nil {
+		includes = make([]types.Resource, len(*reqBody.Includes))
+
+		for i, id := range *reqBody.Includes {
+			includes[i], err = r.engine.NewResourceFromID(id)
+			if err != nil {
+				return r.errorResponse("error creating included role resource", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+			}
+		}
+	}
+
 	role, err := r.engine.UpdateRoleV2(
-		ctx, subjectResource, roleResource,
+		ifMatchContext(ctx, c), subjectResource, roleResource,
 		strings.TrimSpace(reqBody.Name), reqBody.Actions,
 	)
 	if err != nil {
 		return r.errorResponse("error updating role", err)
 	}
 
+	if reqBody.Includes != nil {
+		// the role must not have changed since it was updated above.
+		role, err = r.engine.UpdateRoleV2Includes(
+			query.ContextWithVersionPrecondition(ctx, role.UpdatedAt), subjectResource, roleResource, includes,
+		)
+		if err != nil {
+			return r.errorResponse("error updating role includes", err)
+		}
+	}
+
 	resp := roleResponse{
 		ID:         role.ID,
 		Name:       role.Name,
 		Manager:    role.Manager,
 		Actions:    role.Actions,
+		Includes:   role.Includes,
 		ResourceID: role.ResourceID,
 		CreatedBy:  role.CreatedBy,
 		UpdatedBy:  role.UpdatedBy,
@@ -124,6 +157,8 @@ func (r *Router) roleV2Update(c echo.Context) error {
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
 	}
 
+	setETag(c, role.UpdatedAt)
+
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -164,6 +199,7 @@ func (r *Router) roleV2Get(c echo.Context) error {
 		Name:       role.Name,
 		Manager:    role.Manager,
 		Actions:    role.Actions,
+		Includes:   role.Includes,
 		ResourceID: role.ResourceID,
 		CreatedBy:  role.CreatedBy,
 		UpdatedBy:  role.UpdatedBy,
@@ -171,6 +207,8 @@ func (r *Router) roleV2Get(c echo.Context) error {
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
 	}
 
+	setETag(c, role.UpdatedAt)
+
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -199,14 +237,23 @@ func (r *Router) roleV2sList(c echo.Context) error {
 		return err
 	}
 
-	var roles []types.Role
+	filter, err := parseRoleFilter(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	var (
+		roles      []types.Role
+		nextCursor string
+	)
 
 	params := c.QueryParams()
+	listOpts := ParsePagination(c).ListOptions()
 
 	if params.Has("manager") {
-		roles, err = r.engine.ListManagerRolesV2(ctx, params.Get("manager"), resource)
+		roles, nextCursor, err = r.engine.ListManagerRolesV2(ctx, params.Get("manager"), resource, filter, listOpts)
 	} else {
-		roles, err = r.engine.ListRolesV2(ctx, resource)
+		roles, nextCursor, err = r.engine.ListRolesV2(ctx, resource, filter, listOpts)
 	}
 
 	if err != nil {
@@ -214,7 +261,8 @@ func (r *Router) roleV2sList(c echo.Context) error {
 	}
 
 	resp := listRolesV2Response{
-		Data: []listRolesV2Role{},
+		Data:       []listRolesV2Role{},
+		NextCursor: nextCursor,
 	}
 
 	for _, role := range roles {
@@ -262,7 +310,7 @@ func (r *Router) roleV2Delete(c echo.Context) error {
 		return err
 	}
 
-	err = r.engine.DeleteRoleV2(ctx, roleResource)
+	err = r.engine.DeleteRoleV2(ifMatchContext(ctx, c), roleResource)
 	if err != nil {
 		return r.errorResponse("error deleting role", err)
 	}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -13,6 +14,8 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"go.infratographer.com/permissions-api/internal/decisionlog"
+	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/types"
 )
@@ -26,6 +29,15 @@ type Router struct {
 	logger *zap.SugaredLogger
 
 	concurrentChecks int
+	checkDelegates   map[gidx.PrefixedID]struct{}
+	// accessRequestApproverAction is the action approvers of access requests must hold on the requested resource.
+	accessRequestApproverAction string
+	// decisionLogger records the decisions of permission checks, if set.
+	decisionLogger decisionlog.Logger
+	// relationshipListAction is the action subjects must hold on a resource to list its relationships.
+	relationshipListAction string
+	// relationshipListActions overrides relationshipListAction for the resource types it contains.
+	relationshipListActions map[string]string
 }
 
 // NewRouter returns a new api router
@@ -40,7 +52,9 @@ func NewRouter(authCfg echojwtx.AuthConfig, engine query.Engine, options ...Opti
 		engine: engine,
 		logger: zap.NewNop().Sugar(),
 
-		concurrentChecks: defaultMaxCheckConcurrency,
+		concurrentChecks:            defaultMaxCheckConcurrency,
+		accessRequestApproverAction: string(iapl.RoleBindingActionCreate),
+		relationshipListAction:      string(iapl.RoleBindingActionList),
 	}
 
 	for _, opt := range options {
@@ -94,6 +108,36 @@ func (r *Router) Routes(rg *echo.Group) {
 		v2.GET("/role-bindings/:rb_id", r.roleBindingGet)
 		v2.DELETE("/role-bindings/:rb_id", r.roleBindingDelete)
 		v2.PATCH("/role-bindings/:rb_id", r.roleBindingUpdate)
+		v2.POST("/role-bindings/batch", r.roleBindingsBatch)
+
+		v2.GET("/resources/:id/role-binding-requests", r.roleBindingRequestsList)
+		v2.GET("/role-binding-requests/:rbr_id", r.roleBindingRequestGet)
+		v2.POST("/role-binding-requests/:rbr_id/approve", r.roleBindingRequestApprove)
+		v2.POST("/role-binding-requests/:rbr_id/reject", r.roleBindingRequestReject)
+
+		v2.POST("/access-requests", r.accessRequestCreate)
+		v2.GET("/access-requests", r.accessRequestsListOwn)
+		v2.GET("/resources/:id/access-requests", r.accessRequestsList)
+		v2.GET("/access-requests/:acr_id", r.accessRequestGet)
+		v2.POST("/access-requests/:acr_id/approve", r.accessRequestApprove)
+		v2.POST("/access-requests/:acr_id/deny", r.accessRequestDeny)
+
+		v2.POST("/resources/:id/break-glass", r.breakGlassCreate)
+
+		v2.GET("/resources/:id/access-reviews", r.accessReviewsList)
+		v2.POST("/resources/:id/access-reviews", r.accessReviewCreate)
+		v2.GET("/access-reviews/:arv_id", r.accessReviewGet)
+		v2.GET("/access-reviews/:arv_id/items", r.accessReviewItemsList)
+		v2.PATCH("/access-reviews/:arv_id/items/:item_id", r.accessReviewItemDecide)
+		v2.POST("/access-reviews/:arv_id/close", r.accessReviewClose)
+
+		v2.GET("/subjects/:id/role-bindings", r.subjectRoleBindingsList)
+
+		v2.GET("/resources/:id/effective-actions", r.effectiveActionsList)
+
+		v2.GET("/resources/:id/separation-of-duties-violations", r.separationOfDutiesViolationsList)
+
+		v2.GET("/resources/:id/unused-access", r.unusedAccessList)
 
 		v2.GET("/actions", r.listActions)
 	}
@@ -147,6 +191,21 @@ func WithLogger(logger *zap.SugaredLogger) Option {
 	}
 }
 
+// WithCheckDelegates sets the subjects which may check permissions on behalf of other subjects.
+func WithCheckDelegates(delegates ...gidx.PrefixedID) Option {
+	return func(r *Router) error {
+		if r.checkDelegates == nil {
+			r.checkDelegates = make(map[gidx.PrefixedID]struct{}, len(delegates))
+		}
+
+		for _, delegate := range delegates {
+			r.checkDelegates[delegate] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
 // WithCheckConcurrency sets the check concurrency for bulk permission checks.
 func WithCheckConcurrency(count int) Option {
 	return func(r *Router) error {
@@ -160,6 +219,49 @@ func WithCheckConcurrency(count int) Option {
 	}
 }
 
+// WithAccessRequestApproverAction sets the action subjects must hold on a resource to list,
+// approve and deny access requests on it. Defaults to iam_rolebinding_create.
+func WithAccessRequestApproverAction(action string) Option {
+	return func(r *Router) error {
+		if action != "" {
+			r.accessRequestApproverAction = action
+		}
+
+		return nil
+	}
+}
+
+// WithRelationshipListAction sets the action subjects must hold on a resource to list its
+// relationships. Defaults to iam_rolebinding_list, which existing roles already grant.
+func WithRelationshipListAction(action string) Option {
+	return func(r *Router) error {
+		if action != "" {
+			r.relationshipListAction = action
+		}
+
+		return nil
+	}
+}
+
+// WithRelationshipListActions sets the action subjects must hold to list the relationships of
+// resources of the given types, overriding the action set by WithRelationshipListAction.
+func WithRelationshipListActions(actions map[string]string) Option {
+	return func(r *Router) error {
+		r.relationshipListActions = actions
+
+		return nil
+	}
+}
+
+// WithDecisionLogger sets the logger recording the decisions of permission checks.
+func WithDecisionLogger(logger decisionlog.Logger) Option {
+	return func(r *Router) error {
+		r.decisionLogger = logger
+
+		return nil
+	}
+}
+
 func (r *Router) currentSubject(c echo.Context) (types.Resource, error) {
 	subjectStr := echojwtx.Actor(c)
 
@@ -175,3 +277,41 @@ func (r *Router) currentSubject(c echo.Context) (types.Resource, error) {
 
 	return subjectResource, nil
 }
+
+// checkSubject returns the subject permission checks should be evaluated for.
+// By default this is the current subject. If the subject_id query parameter is provided,
+// the current subject must be a check delegate to check permissions on behalf of the requested subject.
+func (r *Router) checkSubject(c echo.Context) (types.Resource, error) {
+	currentSubject, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, err
+	}
+
+	subjectIDStr, hasSubject := getParam(c, "subject_id")
+	if !hasSubject || subjectIDStr == currentSubject.ID.String() {
+		return currentSubject, nil
+	}
+
+	if _, ok := r.checkDelegates[currentSubject.ID]; !ok {
+		msg := fmt.Sprintf("subject '%s' may not check permissions on behalf of other subjects", currentSubject.ID)
+
+		resp := ErrorResponse{
+			Message: msg,
+			Code:    ErrorCodeCheckDelegateNotAllowed,
+		}
+
+		return types.Resource{}, echo.NewHTTPError(http.StatusForbidden, resp).SetInternal(ErrCheckDelegateNotAllowed)
+	}
+
+	subjectID, err := gidx.Parse(subjectIDStr)
+	if err != nil {
+		return types.Resource{}, echo.NewHTTPError(http.StatusBadRequest, "error parsing subject ID").SetInternal(err)
+	}
+
+	subjectResource, err := r.engine.NewResourceFromID(subjectID)
+	if err != nil {
+		return types.Resource{}, echo.NewHTTPError(http.StatusBadRequest, "error processing subject ID").SetInternal(err)
+	}
+
+	return subjectResource, nil
+}
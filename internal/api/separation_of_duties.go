@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+// separationOfDutiesViolationsList reports the subjects of the role-bindings on a resource
+// which hold actions from more than one set of a separation of duties constraint.
+// Role-bindings may be listed on the resource by the caller.
+func (r *Router) separationOfDutiesViolationsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.separationOfDutiesViolationsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, subjectResource, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	violations, err := r.engine.ListSeparationOfDutiesViolations(ctx, resource)
+	if err != nil {
+		return r.errorResponse("error listing separation of duties violations", err)
+	}
+
+	resp := listSeparationOfDutiesViolationsResponse{
+		Data: make([]separationOfDutiesViolationResponse, len(violations)),
+	}
+
+	for i, violation := range violations {
+		resp.Data[i] = separationOfDutiesViolationResponse{
+			Constraint: violation.Constraint,
+			SubjectID:  violation.SubjectID,
+			ResourceID: violation.ResourceID,
+			Actions:    violation.Actions,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -11,15 +11,17 @@ type createRoleRequest struct {
 }
 
 type updateRoleRequest struct {
-	Name    string   `json:"name"`
-	Actions []string `json:"actions"`
+	Name     string             `json:"name"`
+	Actions  []string           `json:"actions"`
+	Includes *[]gidx.PrefixedID `json:"includes"`
 }
 
 type roleResponse struct {
-	ID      gidx.PrefixedID `json:"id"`
-	Name    string          `json:"name"`
-	Manager string          `json:"manager,omitempty"`
-	Actions []string        `json:"actions"`
+	ID       gidx.PrefixedID   `json:"id"`
+	Name     string            `json:"name"`
+	Manager  string            `json:"manager,omitempty"`
+	Actions  []string          `json:"actions"`
+	Includes []gidx.PrefixedID `json:"includes,omitempty"`
 
 	ResourceID gidx.PrefixedID `json:"resource_id,omitempty"`
 	CreatedBy  gidx.PrefixedID `json:"created_by"`
@@ -37,7 +39,8 @@ type deleteRoleResponse struct {
 }
 
 type listRolesResponse struct {
-	Data []roleResponse `json:"data"`
+	Data       []roleResponse `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 type relationshipItem struct {
@@ -47,7 +50,8 @@ type relationshipItem struct {
 }
 
 type listRelationshipsResponse struct {
-	Data []relationshipItem `json:"data"`
+	Data       []relationshipItem `json:"data"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 type createAssignmentRequest struct {
@@ -71,11 +75,13 @@ type assignmentItem struct {
 }
 
 type listAssignmentsResponse struct {
-	Data []assignmentItem `json:"data"`
+	Data       []assignmentItem `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 type listRolesV2Response struct {
-	Data []listRolesV2Role `json:"data"`
+	Data       []listRolesV2Role `json:"data"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 type listRolesV2Role struct {
@@ -102,6 +108,29 @@ type rolebindingUpdateRequest struct {
 	SubjectIDs []gidx.PrefixedID `json:"subject_ids" binding:"required"`
 }
 
+type roleBindingBatchOperation struct {
+	Op            string            `json:"op"`
+	ResourceID    string            `json:"resource_id"`
+	RoleID        string            `json:"role_id"`
+	RoleBindingID string            `json:"rolebinding_id"`
+	SubjectIDs    []gidx.PrefixedID `json:"subject_ids"`
+	Manager       string            `json:"manager"`
+}
+
+type roleBindingBatchRequest struct {
+	Operations []roleBindingBatchOperation `json:"operations" binding:"required"`
+}
+
+type roleBindingBatchResult struct {
+	Op          string               `json:"op"`
+	ID          gidx.PrefixedID      `json:"id"`
+	RoleBinding *roleBindingResponse `json:"role_binding,omitempty"`
+}
+
+type roleBindingBatchResponse struct {
+	Data []roleBindingBatchResult `json:"data"`
+}
+
 type roleBindingResponse struct {
 	ID         gidx.PrefixedID   `json:"id"`
 	ResourceID gidx.PrefixedID   `json:"resource_id"`
@@ -116,7 +145,165 @@ type roleBindingResponse struct {
 }
 
 type listRoleBindingsResponse struct {
-	Data []roleBindingResponse `json:"data"`
+	Data       []roleBindingResponse `json:"data"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+type roleBindingRequestResponse struct {
+	ID            gidx.PrefixedID   `json:"id"`
+	ResourceID    gidx.PrefixedID   `json:"resource_id"`
+	RoleID        gidx.PrefixedID   `json:"role_id"`
+	Manager       string            `json:"manager"`
+	SubjectIDs    []gidx.PrefixedID `json:"subject_ids"`
+	Status        string            `json:"status"`
+	RoleBindingID gidx.PrefixedID   `json:"role_binding_id,omitempty"`
+
+	RequestedBy gidx.PrefixedID `json:"requested_by"`
+	ReviewedBy  gidx.PrefixedID `json:"reviewed_by,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at"`
+}
+
+type listRoleBindingRequestsResponse struct {
+	Data       []roleBindingRequestResponse `json:"data"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+type accessRequestCreateRequest struct {
+	ResourceID gidx.PrefixedID `json:"resource_id" binding:"required"`
+	RoleID     gidx.PrefixedID `json:"role_id" binding:"required"`
+	Reason     string          `json:"reason"`
+	// ExpiresIn is a duration, e.g. 8h, after which the approved role-binding is removed.
+	ExpiresIn string `json:"expires_in"`
+}
+
+type accessRequestApproveRequest struct {
+	// ExpiresIn overrides the duration requested, if set.
+	ExpiresIn string `json:"expires_in"`
+}
+
+type accessRequestResponse struct {
+	ID            gidx.PrefixedID `json:"id"`
+	ResourceID    gidx.PrefixedID `json:"resource_id"`
+	RoleID        gidx.PrefixedID `json:"role_id"`
+	SubjectID     gidx.PrefixedID `json:"subject_id"`
+	Reason        string          `json:"reason"`
+	Status        string          `json:"status"`
+	ExpiresIn     string          `json:"expires_in,omitempty"`
+	ExpiresAt     string          `json:"expires_at,omitempty"`
+	RoleBindingID gidx.PrefixedID `json:"role_binding_id,omitempty"`
+	BreakGlass    bool            `json:"break_glass,omitempty"`
+
+	ReviewedBy gidx.PrefixedID `json:"reviewed_by,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
+}
+
+type listAccessRequestsResponse struct {
+	Data       []accessRequestResponse `json:"data"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type breakGlassRequest struct {
+	Justification string `json:"justification" binding:"required"`
+}
+
+type accessReviewCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type accessReviewResponse struct {
+	ID         gidx.PrefixedID `json:"id"`
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	Name       string          `json:"name"`
+	Status     string          `json:"status"`
+
+	CreatedBy gidx.PrefixedID `json:"created_by"`
+	ClosedBy  gidx.PrefixedID `json:"closed_by,omitempty"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
+	ClosedAt  string          `json:"closed_at,omitempty"`
+}
+
+type listAccessReviewsResponse struct {
+	Data       []accessReviewResponse `json:"data"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+type accessReviewItemDecideRequest struct {
+	Decision string `json:"decision" binding:"required"`
+}
+
+type accessReviewItemResponse struct {
+	ID            gidx.PrefixedID `json:"id"`
+	ReviewID      gidx.PrefixedID `json:"review_id"`
+	RoleBindingID gidx.PrefixedID `json:"role_binding_id"`
+	ResourceID    gidx.PrefixedID `json:"resource_id"`
+	RoleID        gidx.PrefixedID `json:"role_id"`
+	SubjectID     gidx.PrefixedID `json:"subject_id"`
+	Decision      string          `json:"decision"`
+
+	ReviewedBy gidx.PrefixedID `json:"reviewed_by,omitempty"`
+	UpdatedAt  string          `json:"updated_at"`
+}
+
+type listAccessReviewItemsResponse struct {
+	Data       []accessReviewItemResponse `json:"data"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+}
+
+type subjectRoleBindingRole struct {
+	ID   gidx.PrefixedID `json:"id"`
+	Name string          `json:"name"`
+}
+
+type subjectRoleBindingResource struct {
+	ID   gidx.PrefixedID `json:"id"`
+	Type string          `json:"type"`
+}
+
+type subjectRoleBindingResponse struct {
+	roleBindingResponse
+
+	Role     subjectRoleBindingRole     `json:"role"`
+	Resource subjectRoleBindingResource `json:"resource"`
+	GroupIDs []gidx.PrefixedID          `json:"group_ids,omitempty"`
+}
+
+type listSubjectRoleBindingsResponse struct {
+	Data       []subjectRoleBindingResponse `json:"data"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+type listEffectiveActionsResponse struct {
+	Data []string `json:"data"`
+}
+
+type separationOfDutiesViolationResponse struct {
+	Constraint string          `json:"constraint"`
+	SubjectID  gidx.PrefixedID `json:"subject_id"`
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	Actions    []string        `json:"actions"`
+}
+
+type listSeparationOfDutiesViolationsResponse struct {
+	Data []separationOfDutiesViolationResponse `json:"data"`
+}
+
+type unusedAccessResponse struct {
+	RoleBindingID gidx.PrefixedID `json:"role_binding_id"`
+	ResourceID    gidx.PrefixedID `json:"resource_id"`
+	RoleID        gidx.PrefixedID `json:"role_id"`
+	SubjectID     gidx.PrefixedID `json:"subject_id"`
+	LastUsedAt    string          `json:"last_used_at,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+type listUnusedAccessResponse struct {
+	Data []unusedAccessResponse `json:"data"`
+	// UsageSampleRate is the fraction of allowed checks whose usage is recorded. Access used
+	// less often than the sample rate allows for may be listed even though it was used.
+	UsageSampleRate float64 `json:"usage_sample_rate"`
 }
 
 type deleteRoleBindingResponse struct {
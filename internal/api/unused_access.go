@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+// defaultUnusedDays is the number of days access is reported unused for when not provided.
+const defaultUnusedDays = 90
+
+func parseUnusedDays(c echo.Context) (int, error) {
+	daysStr := c.QueryParam("days")
+	if daysStr == "" {
+		return defaultUnusedDays, nil
+	}
+
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days <= 0 {
+		return 0, fmt.Errorf("%w: days: must be a positive integer", ErrInvalidFilter)
+	}
+
+	return days, nil
+}
+
+// unusedAccessList reports the subjects of the role-bindings on a resource and the resources beneath it
+// which have not been allowed an action through their role-binding for the given number of days.
+// Role-bindings may be listed on the resource by the caller. Usage is only recorded for a sample of
+// the allowed checks, so the response includes the sample rate the report is based on.
+func (r *Router) unusedAccessList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.unusedAccessList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	days, err := parseUnusedDays(c)
+	if err != nil {
+		return r.errorResponse("error parsing filter", err)
+	}
+
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, subjectResource, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	unused, err := r.engine.ListUnusedAccess(ctx, resource, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return r.errorResponse("error listing unused access", err)
+	}
+
+	resp := listUnusedAccessResponse{
+		Data:            make([]unusedAccessResponse, len(unused)),
+		UsageSampleRate: r.engine.RoleBindingUsageSampleRate(),
+	}
+
+	for i, access := range unused {
+		resp.Data[i] = unusedAccessResponse{
+			RoleBindingID: access.RoleBindingID,
+			ResourceID:    access.ResourceID,
+			RoleID:        access.RoleID,
+			SubjectID:     access.SubjectID,
+			CreatedAt:     access.CreatedAt.Format(time.RFC3339),
+		}
+
+		if access.LastUsedAt != nil {
+			resp.Data[i].LastUsedAt = access.LastUsedAt.Format(time.RFC3339)
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/crdbx"
@@ -23,6 +25,41 @@ type EventsConfig struct {
 	ZedTokenBucket string
 }
 
+// APIConfig stores the configuration for the permissions-api server
+type APIConfig struct {
+	// CheckDelegates are the subject IDs permitted to check permissions on behalf of other subjects.
+	CheckDelegates []string
+	// PrivilegeEscalationGuard rejects role-bindings and role updates granting actions which the actor does not hold.
+	PrivilegeEscalationGuard bool
+	// AccessRequestApproverAction is the action subjects must hold on a resource to review access requests on it.
+	AccessRequestApproverAction string
+	// RelationshipListAction is the action subjects must hold on a resource to list its relationships.
+	RelationshipListAction string
+	// RelationshipListActions override RelationshipListAction by resource type.
+	RelationshipListActions map[string]string
+	// AccessRequestExpiryInterval is how often role-bindings of expired access requests are removed.
+	AccessRequestExpiryInterval time.Duration
+	// BreakGlassRole is the ID of the emergency role subjects may bind themselves to. Break-glass access is disabled if empty.
+	BreakGlassRole string
+	// BreakGlassDuration is how long break-glass role-bindings are kept.
+	BreakGlassDuration time.Duration
+	// UsageSampleRate is the fraction of allowed permission checks whose role-bindings are recorded as used.
+	UsageSampleRate float64
+	// UsageFlushInterval is how often recorded role-binding usage is written to the database.
+	UsageFlushInterval time.Duration
+	// DecisionLogOutput is where decisions of permission checks are logged: stdout, file or events.
+	// Decisions are not logged if empty.
+	DecisionLogOutput string
+	// DecisionLogFile is the file decisions are appended to when DecisionLogOutput is file.
+	DecisionLogFile string
+	// DecisionLogSampleRate is the fraction of decisions logged whose outcome has no rate of its own.
+	DecisionLogSampleRate float64
+	// DecisionLogOutcomeSampleRates are the fractions of decisions logged by outcome.
+	DecisionLogOutcomeSampleRates map[string]float64
+	// BuiltinRoles materializes the policy's built-in roles for all role owners on startup.
+	BuiltinRoles bool
+}
+
 // DBEngine is the type for the database engine
 type DBEngine string
 
@@ -49,6 +86,7 @@ type AppConfig struct {
 	Tracing otelx.Config
 	Events  EventsConfig
 	DB      DBDriverConfig
+	API     APIConfig
 }
 
 // MustViperFlags sets the cobra flags and viper config for events.
@@ -0,0 +1,85 @@
+package decisionlog
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// Outcome is the outcome of a permission check.
+type Outcome string
+
+const (
+	// OutcomeAllowed is the outcome of a check allowing the subject to perform the action.
+	OutcomeAllowed Outcome = "allowed"
+	// OutcomeDenied is the outcome of a check denying the subject to perform the action.
+	OutcomeDenied Outcome = "denied"
+	// OutcomeInvalid is the outcome of a check of an action which does not exist on the resource.
+	OutcomeInvalid Outcome = "invalid"
+	// OutcomeError is the outcome of a check which failed.
+	OutcomeError Outcome = "error"
+)
+
+// Decision is the record of a permission check.
+type Decision struct {
+	Time         time.Time       `json:"time"`
+	SubjectID    gidx.PrefixedID `json:"subject_id"`
+	Action       string          `json:"action"`
+	ResourceID   gidx.PrefixedID `json:"resource_id"`
+	ResourceType string          `json:"resource_type"`
+	Outcome      Outcome         `json:"outcome"`
+	// Consistency is the consistency requirement the check was made with.
+	Consistency string `json:"consistency,omitempty"`
+	// ZedToken is the SpiceDB revision the check was evaluated at.
+	ZedToken string        `json:"zedtoken,omitempty"`
+	Latency  time.Duration `json:"latency_ns"`
+	// Error is the error of checks with the error outcome.
+	Error string `json:"error,omitempty"`
+}
+
+// Logger records decisions. Implementations must be safe for concurrent use and
+// should not block the check the decision was made by.
+type Logger interface {
+	LogDecision(ctx context.Context, decision Decision)
+}
+
+// SampleRates are the fractions of decisions logged, between 0 and 1.
+type SampleRates struct {
+	// Default is the rate of decisions whose outcome has no rate of its own.
+	Default float64
+	// Outcomes are the rates of decisions with the outcome.
+	Outcomes map[Outcome]float64
+}
+
+func (r SampleRates) rate(outcome Outcome) float64 {
+	if rate, ok := r.Outcomes[outcome]; ok {
+		return rate
+	}
+
+	return r.Default
+}
+
+type sampledLogger struct {
+	next  Logger
+	rates SampleRates
+}
+
+// NewSampledLogger returns a logger passing a sample of the decisions to next,
+// at the rate of the decision's outcome.
+func NewSampledLogger(next Logger, rates SampleRates) Logger {
+	return &sampledLogger{
+		next:  next,
+		rates: rates,
+	}
+}
+
+// LogDecision passes the decision to the next logger if it is sampled.
+func (l *sampledLogger) LogDecision(ctx context.Context, decision Decision) {
+	rate := l.rates.rate(decision.Outcome)
+
+	if rate >= 1 || rand.Float64() < rate { //nolint:gosec // sampling does not need a secure source
+		l.next.LogDecision(ctx, decision)
+	}
+}
@@ -0,0 +1,120 @@
+package decisionlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+)
+
+// testLogger records the decisions logged.
+type testLogger struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+func (l *testLogger) LogDecision(_ context.Context, decision Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.decisions = append(l.decisions, decision)
+}
+
+// testEventPublisher records the events published.
+type testEventPublisher struct {
+	topics []string
+	events []events.EventMessage
+}
+
+func (p *testEventPublisher) PublishChange(context.Context, string, events.ChangeMessage) (events.Message[events.ChangeMessage], error) {
+	return nil, nil
+}
+
+func (p *testEventPublisher) PublishEvent(_ context.Context, topic string, msg events.EventMessage) (events.Message[events.EventMessage], error) {
+	p.topics = append(p.topics, topic)
+	p.events = append(p.events, msg)
+
+	return nil, nil
+}
+
+func testDecision(outcome Outcome) Decision {
+	return Decision{
+		Time:         time.Now().UTC(),
+		SubjectID:    "idntusr-abc123",
+		Action:       "loadbalancer_get",
+		ResourceID:   "loadbal-abc123",
+		ResourceType: "loadbalancer",
+		Outcome:      outcome,
+		Consistency:  "minimize_latency",
+		ZedToken:     "GhUKEzE3MDAwMDAwMDAwMDAwMDAwMDA=",
+		Latency:      time.Millisecond,
+	}
+}
+
+func TestSampledLogger(t *testing.T) {
+	ctx := context.Background()
+	next := &testLogger{}
+
+	logger := NewSampledLogger(next, SampleRates{
+		Default: 1,
+		Outcomes: map[Outcome]float64{
+			OutcomeAllowed: 0,
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.LogDecision(ctx, testDecision(OutcomeAllowed))
+		logger.LogDecision(ctx, testDecision(OutcomeDenied))
+	}
+
+	require.Len(t, next.decisions, 10)
+
+	for _, decision := range next.decisions {
+		assert.Equal(t, OutcomeDenied, decision.Outcome)
+	}
+}
+
+func TestWriterLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewWriterLogger(&buf, zap.NewNop().Sugar())
+
+	decision := testDecision(OutcomeDenied)
+
+	logger.LogDecision(context.Background(), decision)
+
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "idntusr-abc123", got["subject_id"])
+	assert.Equal(t, "loadbalancer_get", got["action"])
+	assert.Equal(t, "denied", got["outcome"])
+	assert.Equal(t, "GhUKEzE3MDAwMDAwMDAwMDAwMDAwMDA=", got["zedtoken"])
+	assert.InDelta(t, float64(time.Millisecond), got["latency_ns"], 0)
+	assert.NotContains(t, got, "error")
+}
+
+func TestEventsLogger(t *testing.T) {
+	publisher := &testEventPublisher{}
+
+	logger := NewEventsLogger(publisher, zap.NewNop().Sugar())
+
+	logger.LogDecision(context.Background(), testDecision(OutcomeAllowed))
+	logger.Close()
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "loadbalancer", publisher.topics[0])
+
+	event := publisher.events[0]
+	assert.Equal(t, EventType, event.EventType)
+	assert.Equal(t, "loadbal-abc123", event.SubjectID.String())
+	assert.Equal(t, "allowed", event.Data["outcome"])
+	assert.Equal(t, "idntusr-abc123", event.Data["subject_id"])
+}
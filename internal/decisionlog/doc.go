@@ -0,0 +1,2 @@
+// Package decisionlog provides loggers recording the decisions of permission checks as structured records.
+package decisionlog
@@ -0,0 +1,105 @@
+package decisionlog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+)
+
+const (
+	// EventType is the event type decisions are published with.
+	EventType = "permission-decision"
+
+	// eventSource is the source of published decision events.
+	eventSource = "permissions-api"
+
+	// eventsBufferSize is the number of decisions buffered to be published.
+	eventsBufferSize = 1024
+)
+
+// EventsLogger publishes decisions as events, to the topic of the checked resource's type.
+// Decisions are published in the background so checks are not delayed, and are dropped
+// while the buffer of decisions to publish is full.
+type EventsLogger struct {
+	publisher events.Publisher
+	logger    *zap.SugaredLogger
+
+	decisions chan Decision
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewEventsLogger returns a logger publishing decisions with publisher until it is closed.
+func NewEventsLogger(publisher events.Publisher, logger *zap.SugaredLogger) *EventsLogger {
+	l := &EventsLogger{
+		publisher: publisher,
+		logger:    logger,
+		decisions: make(chan Decision, eventsBufferSize),
+		done:      make(chan struct{}),
+	}
+
+	go l.publish()
+
+	return l
+}
+
+// LogDecision queues the decision to be published, dropping it if the queue is full.
+func (l *EventsLogger) LogDecision(_ context.Context, decision Decision) {
+	select {
+	case l.decisions <- decision:
+	default:
+		if dropped := l.dropped.Add(1); dropped%eventsBufferSize == 1 {
+			l.logger.Warnw("decision events buffer full, dropping decisions", "dropped", dropped)
+		}
+	}
+}
+
+// Close publishes the queued decisions and stops the logger.
+// Decisions must not be logged once the logger is closed.
+func (l *EventsLogger) Close() {
+	l.closeOnce.Do(func() {
+		close(l.decisions)
+	})
+
+	<-l.done
+}
+
+func (l *EventsLogger) publish() {
+	defer close(l.done)
+
+	for decision := range l.decisions {
+		if _, err := l.publisher.PublishEvent(context.Background(), decision.ResourceType, decisionEvent(decision)); err != nil {
+			l.logger.Errorw("error publishing decision", "error", err)
+		}
+	}
+}
+
+func decisionEvent(decision Decision) events.EventMessage {
+	data := map[string]interface{}{
+		"subject_id":  decision.SubjectID.String(),
+		"action":      decision.Action,
+		"resource_id": decision.ResourceID.String(),
+		"outcome":     string(decision.Outcome),
+		"consistency": decision.Consistency,
+		"zedtoken":    decision.ZedToken,
+		"latency_ns":  decision.Latency.Nanoseconds(),
+	}
+
+	if decision.Error != "" {
+		data["error"] = decision.Error
+	}
+
+	return events.EventMessage{
+		SubjectID:            decision.ResourceID,
+		EventType:            EventType,
+		AdditionalSubjectIDs: []gidx.PrefixedID{decision.SubjectID},
+		Source:               eventSource,
+		Timestamp:            decision.Time,
+		Data:                 data,
+	}
+}
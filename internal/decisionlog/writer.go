@@ -0,0 +1,36 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type writerLogger struct {
+	logger *zap.SugaredLogger
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterLogger returns a logger writing decisions to w as JSON lines, such as to stdout or a file.
+// Errors writing decisions are logged to logger.
+func NewWriterLogger(w io.Writer, logger *zap.SugaredLogger) Logger {
+	return &writerLogger{
+		logger: logger,
+		enc:    json.NewEncoder(w),
+	}
+}
+
+// LogDecision writes the decision.
+func (l *writerLogger) LogDecision(_ context.Context, decision Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(decision); err != nil {
+		l.logger.Errorw("error writing decision", "error", err)
+	}
+}
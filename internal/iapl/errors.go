@@ -19,4 +19,8 @@ var (
 	ErrorMissingRelationship = errors.New("missing relationship")
 	// ErrorDuplicateRBACDefinition represents an error where a duplicate RBAC definition was declared.
 	ErrorDuplicateRBACDefinition = errors.New("duplicated RBAC definition")
+	// ErrorInvalidBuiltinRole represents an error where a built-in role is not valid.
+	ErrorInvalidBuiltinRole = errors.New("invalid built-in role")
+	// ErrorInvalidSeparationOfDuties represents an error where a separation of duties constraint is not valid.
+	ErrorInvalidSeparationOfDuties = errors.New("invalid separation of duties constraint")
 )
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"go.infratographer.com/permissions-api/internal/types"
@@ -15,6 +16,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// minSeparationOfDutiesSets is the minimum number of mutually exclusive sets of a separation
+// of duties constraint.
+const minSeparationOfDutiesSets = 2
+
 // PolicyDocument represents a partial authorization policy.
 type PolicyDocument struct {
 	ResourceTypes  []ResourceType
@@ -47,6 +52,9 @@ type Union struct {
 // Action represents an action that can be taken in an authorization policy.
 type Action struct {
 	Name string
+	// RequiresApproval requires role bindings granting the action to be approved by a
+	// second subject before they are created.
+	RequiresApproval bool
 }
 
 // ActionBinding represents a binding of an action to a resource type or union.
@@ -84,6 +92,8 @@ type Policy interface {
 	Validate() error
 	Schema() []types.ResourceType
 	RBAC() *RBAC
+	// ApprovalActions returns the names of the actions which require approval.
+	ApprovalActions() []string
 }
 
 var _ Policy = &policy{}
@@ -426,6 +436,7 @@ func (v *policy) validateActionBindings() error {
 
 // validateRoles validates V2 role resource types to ensure that:
 //   - role resource type has a valid owner relationship
+//   - built-in roles are valid
 func (v *policy) validateRoles() error {
 	if v.p.RBAC == nil {
 		return nil
@@ -440,6 +451,117 @@ func (v *policy) validateRoles() error {
 		}
 	}
 
+	if err := v.validateBuiltinRoles(); err != nil {
+		return fmt.Errorf("builtinRoles: %w", err)
+	}
+
+	if err := v.validateSeparationOfDuties(); err != nil {
+		return fmt.Errorf("separationOfDuties: %w", err)
+	}
+
+	return nil
+}
+
+// validateBuiltinRoles validates that built-in roles have a name which is
+// unique for each of their owners, are owned by role owners and only
+// contain actions defined in the policy.
+func (v *policy) validateBuiltinRoles() error {
+	roleOwners := v.p.RBAC.RoleOwnersSet()
+	names := make(map[string]map[string]struct{}, len(roleOwners))
+
+	for i, role := range v.p.RBAC.BuiltinRoles {
+		if role.Name == "" {
+			return fmt.Errorf("%d: %w: name is required", i, ErrorInvalidBuiltinRole)
+		}
+
+		if len(role.RoleOwners) == 0 {
+			return fmt.Errorf("%d (%s): %w: at least one role owner is required", i, role.Name, ErrorInvalidBuiltinRole)
+		}
+
+		for _, owner := range role.RoleOwners {
+			if _, ok := roleOwners[owner]; !ok {
+				return fmt.Errorf("%d (%s): %w: %s is not a role owner", i, role.Name, ErrorUnknownType, owner)
+			}
+
+			if _, ok := names[owner]; !ok {
+				names[owner] = make(map[string]struct{})
+			}
+
+			if _, ok := names[owner][role.Name]; ok {
+				return fmt.Errorf("%d (%s): %w: duplicate role for %s", i, role.Name, ErrorInvalidBuiltinRole, owner)
+			}
+
+			names[owner][role.Name] = struct{}{}
+		}
+
+		for _, action := range role.Actions {
+			if _, ok := v.ac[action]; !ok {
+				return fmt.Errorf("%d (%s): %s: %w", i, role.Name, action, ErrorUnknownAction)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSeparationOfDuties validates that separation of duties constraints have a unique
+// name and at least two non-empty, disjoint sets, only containing actions defined in the
+// policy and built-in roles declared in the policy.
+func (v *policy) validateSeparationOfDuties() error {
+	builtinRoles := make(map[string]struct{}, len(v.p.RBAC.BuiltinRoles))
+
+	for _, role := range v.p.RBAC.BuiltinRoles {
+		builtinRoles[role.Name] = struct{}{}
+	}
+
+	names := make(map[string]struct{}, len(v.p.RBAC.SeparationOfDuties))
+
+	for i, constraint := range v.p.RBAC.SeparationOfDuties {
+		if constraint.Name == "" {
+			return fmt.Errorf("%d: %w: name is required", i, ErrorInvalidSeparationOfDuties)
+		}
+
+		if _, ok := names[constraint.Name]; ok {
+			return fmt.Errorf("%d (%s): %w: duplicate constraint", i, constraint.Name, ErrorInvalidSeparationOfDuties)
+		}
+
+		names[constraint.Name] = struct{}{}
+
+		if len(constraint.ActionSets)+len(constraint.BuiltinRoles) < minSeparationOfDutiesSets {
+			return fmt.Errorf("%d (%s): %w: at least %d sets are required", i, constraint.Name, ErrorInvalidSeparationOfDuties, minSeparationOfDutiesSets)
+		}
+
+		for _, name := range constraint.BuiltinRoles {
+			if _, ok := builtinRoles[name]; !ok {
+				return fmt.Errorf("%d (%s): %w: %s is not a built-in role", i, constraint.Name, ErrorInvalidSeparationOfDuties, name)
+			}
+		}
+
+		for _, set := range constraint.ActionSets {
+			if len(set) == 0 {
+				return fmt.Errorf("%d (%s): %w: action sets can not be empty", i, constraint.Name, ErrorInvalidSeparationOfDuties)
+			}
+
+			for _, action := range set {
+				if _, ok := v.ac[action]; !ok {
+					return fmt.Errorf("%d (%s): %s: %w", i, constraint.Name, action, ErrorUnknownAction)
+				}
+			}
+		}
+
+		actionSets := make(map[string]int)
+
+		for j, set := range v.p.RBAC.SeparationOfDutiesSets(constraint) {
+			for _, action := range set {
+				if k, ok := actionSets[action]; ok && k != j {
+					return fmt.Errorf("%d (%s): %w: %s is in more than one set", i, constraint.Name, ErrorInvalidSeparationOfDuties, action)
+				}
+
+				actionSets[action] = j
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -483,6 +605,9 @@ func (v *policy) expandActionBindings() {
 //	  - relation: owner
 //	    targettypes:
 //	      - name: tenant
+//	  - relation: include
+//	    targettypes:
+//	      - name: rolev2
 //	  - relation: foo_resource_get_rel
 //	    targettypes:
 //	      - name: user
@@ -504,7 +629,7 @@ func (v *policy) createV2RoleResourceType() {
 	}
 
 	// 2. create a list of relationships for all permissions and ownerships
-	roleRel := make([]Relationship, 0, len(v.ac)+1)
+	roleRel := make([]Relationship, 0, len(v.ac)+2)
 
 	for _, action := range v.ac {
 		targettypes := make([]types.TargetType, len(v.p.RBAC.RoleSubjectTypes))
@@ -521,8 +646,16 @@ func (v *policy) createV2RoleResourceType() {
 		)
 	}
 
-	// 3. create a role resource type containing all the relationships shown above
-	roleRel = append(roleRel, roleOwners)
+	// 3. create a relationship for included roles
+	roleInclude := Relationship{
+		Relation: RoleIncludeRelation,
+		TargetTypes: []types.TargetType{
+			{Name: v.p.RBAC.RoleResource.Name},
+		},
+	}
+
+	// 4. create a role resource type containing all the relationships shown above
+	roleRel = append(roleRel, roleOwners, roleInclude)
 
 	role.Relationships = roleRel
 	v.rt[role.Name] = role
@@ -556,7 +689,7 @@ func (v *policy) createV2RoleResourceType() {
 //	      - conditions:
 //	        - relationshipaction:
 //	            relation: rolev2
-//	            actionname: foo_resource_get_rel
+//	            actionname: foo_resource_get_perm
 //	      - conditions:
 //	        - relationshipaction:
 //	            relation: subject
@@ -595,7 +728,7 @@ func (v *policy) createRoleBindingResourceType() {
 						{
 							RelationshipAction: &types.ConditionRelationshipAction{
 								Relation:   RolebindingRoleRelation,
-								ActionName: actionName + PermissionRelationSuffix,
+								ActionName: actionName + RolePermissionSuffix,
 							},
 						},
 					},
@@ -837,6 +970,20 @@ func (v *policy) Schema() []types.ResourceType {
 		typeMap[resType].Actions = append(typeMap[resType].Actions, actions...)
 	}
 
+	// role permissions, including the actions of included roles
+	if v.p.RBAC != nil {
+		actionNames := make([]string, 0, len(v.ac))
+
+		for actionName := range v.ac {
+			actionNames = append(actionNames, actionName)
+		}
+
+		slices.Sort(actionNames)
+
+		roleType := typeMap[v.p.RBAC.RoleResource.Name]
+		roleType.Actions = append(roleType.Actions, v.p.RBAC.RoleActions(actionNames...)...)
+	}
+
 	out := make([]types.ResourceType, 0, len(typeMap))
 	for _, rt := range typeMap {
 		out = append(out, *rt)
@@ -850,6 +997,20 @@ func (v *policy) RBAC() *RBAC {
 	return v.p.RBAC
 }
 
+func (v *policy) ApprovalActions() []string {
+	var actions []string
+
+	for _, action := range v.p.Actions {
+		if action.RequiresApproval {
+			actions = append(actions, action.Name)
+		}
+	}
+
+	slices.Sort(actions)
+
+	return actions
+}
+
 func (v *policy) findRelationship(rels []Relationship, name string) bool {
 	for _, rel := range rels {
 		if rel.Relation == name {
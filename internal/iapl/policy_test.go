@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.infratographer.com/x/gidx"
 
@@ -471,6 +472,160 @@ func TestPolicy(t *testing.T) {
 				require.NotNil(t, res.Success.RBAC())
 			},
 		},
+		{
+			Name: "BuiltinRoleUnknownOwner",
+			Input: rbacPolicyDocument(BuiltinRole{
+				Name:       "Viewer",
+				Actions:    []string{"tenant_get"},
+				RoleOwners: []string{"user"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownType)
+			},
+		},
+		{
+			Name: "BuiltinRoleUnknownAction",
+			Input: rbacPolicyDocument(BuiltinRole{
+				Name:       "Viewer",
+				Actions:    []string{"tenant_list"},
+				RoleOwners: []string{"tenant"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownAction)
+			},
+		},
+		{
+			Name: "BuiltinRoleDuplicate",
+			Input: rbacPolicyDocument(
+				BuiltinRole{Name: "Viewer", Actions: []string{"tenant_get"}, RoleOwners: []string{"tenant"}},
+				BuiltinRole{Name: "Viewer", Actions: []string{"tenant_get"}, RoleOwners: []string{"tenant"}},
+			),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorInvalidBuiltinRole)
+			},
+		},
+		{
+			Name: "BuiltinRoleOK",
+			Input: rbacPolicyDocument(BuiltinRole{
+				Name:       "Viewer",
+				Actions:    []string{"tenant_get"},
+				RoleOwners: []string{"tenant"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+
+				roles := res.Success.RBAC().BuiltinRolesForOwner("tenant")
+				require.Len(t, roles, 1)
+				assert.Equal(t, "Viewer", roles[0].Name)
+				assert.Empty(t, res.Success.RBAC().BuiltinRolesForOwner("user"))
+			},
+		},
+		{
+			Name: "SeparationOfDutiesTooFewSets",
+			Input: separationOfDutiesPolicyDocument(SeparationOfDutiesConstraint{
+				Name:       "single",
+				ActionSets: [][]string{{"tenant_get"}},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				assert.ErrorIs(t, res.Err, ErrorInvalidSeparationOfDuties)
+			},
+		},
+		{
+			Name: "SeparationOfDutiesUnknownAction",
+			Input: separationOfDutiesPolicyDocument(SeparationOfDutiesConstraint{
+				Name:       "unknown",
+				ActionSets: [][]string{{"tenant_get"}, {"tenant_delete"}},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				assert.ErrorIs(t, res.Err, ErrorUnknownAction)
+			},
+		},
+		{
+			Name: "SeparationOfDutiesUnknownRole",
+			Input: separationOfDutiesPolicyDocument(SeparationOfDutiesConstraint{
+				Name:         "unknown",
+				ActionSets:   [][]string{{"tenant_get"}},
+				BuiltinRoles: []string{"Admin"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				assert.ErrorIs(t, res.Err, ErrorInvalidSeparationOfDuties)
+			},
+		},
+		{
+			Name: "SeparationOfDutiesOverlappingSets",
+			Input: separationOfDutiesPolicyDocument(SeparationOfDutiesConstraint{
+				Name:         "viewers",
+				ActionSets:   [][]string{{"tenant_get"}},
+				BuiltinRoles: []string{"Viewer"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				assert.ErrorIs(t, res.Err, ErrorInvalidSeparationOfDuties)
+			},
+		},
+		{
+			Name: "SeparationOfDutiesOK",
+			Input: separationOfDutiesPolicyDocument(SeparationOfDutiesConstraint{
+				Name:         "viewers",
+				ActionSets:   [][]string{{"tenant_update"}},
+				BuiltinRoles: []string{"Viewer"},
+			}),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+
+				rbac := res.Success.RBAC()
+				require.Len(t, rbac.SeparationOfDuties, 1)
+				assert.Equal(t, [][]string{{"tenant_update"}, {"tenant_get"}}, rbac.SeparationOfDutiesSets(rbac.SeparationOfDuties[0]))
+			},
+		},
+		{
+			Name: "ApprovalActions",
+			Input: func() PolicyDocument {
+				doc := rbacPolicyDocument()
+				doc.Actions = append(doc.Actions, Action{Name: "tenant_delete", RequiresApproval: true})
+				doc.ActionBindings = append(doc.ActionBindings, ActionBinding{
+					TypeName:   "tenant",
+					ActionName: "tenant_delete",
+					Conditions: []Condition{{RoleBindingV2: &ConditionRoleBindingV2{}}},
+				})
+
+				return doc
+			}(),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []string{"tenant_delete"}, res.Success.ApprovalActions())
+			},
+		},
+		{
+			Name:  "RoleIncludes",
+			Input: rbacPolicyDocument(),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+
+				var role, rolebinding types.ResourceType
+
+				for _, rt := range res.Success.Schema() {
+					switch rt.Name {
+					case "rolev2":
+						role = rt
+					case "role_binding":
+						rolebinding = rt
+					}
+				}
+
+				assert.Contains(t, role.Relationships, types.ResourceTypeRelationship{
+					Relation: RoleIncludeRelation,
+					Types:    []types.TargetType{{Name: "rolev2"}},
+				})
+				assert.Contains(t, role.Actions, (&RBAC{}).RoleActions("tenant_get")[0])
+
+				for _, action := range rolebinding.Actions {
+					if action.Name == "tenant_get" {
+						require.Len(t, action.ConditionSets, 2)
+						assert.Equal(t, "tenant_get_perm", action.ConditionSets[0].Conditions[0].RelationshipAction.ActionName)
+					}
+				}
+			},
+		},
 	}
 
 	testFn := func(_ context.Context, doc PolicyDocument) testingx.TestResult[Policy] {
@@ -495,3 +650,52 @@ func defaultRBAC() RBAC {
 		RoleBindingSubjects: []types.TargetType{{Name: "user"}, {Name: "client"}, {Name: "group", SubjectRelation: "member"}},
 	}
 }
+
+func separationOfDutiesPolicyDocument(constraints ...SeparationOfDutiesConstraint) PolicyDocument {
+	doc := rbacPolicyDocument(BuiltinRole{
+		Name:       "Viewer",
+		Actions:    []string{"tenant_get"},
+		RoleOwners: []string{"tenant"},
+	})
+	doc.RBAC.SeparationOfDuties = constraints
+	doc.Actions = append(doc.Actions, Action{Name: "tenant_update"})
+	doc.ActionBindings = append(doc.ActionBindings, ActionBinding{
+		TypeName:   "tenant",
+		ActionName: "tenant_update",
+		Conditions: []Condition{{RoleBindingV2: &ConditionRoleBindingV2{}}},
+	})
+
+	return doc
+}
+
+func rbacPolicyDocument(roles ...BuiltinRole) PolicyDocument {
+	rbac := defaultRBAC()
+	rbac.RoleSubjectTypes = []string{"user"}
+	rbac.RoleBindingSubjects = []types.TargetType{{Name: "user"}}
+	rbac.BuiltinRoles = roles
+
+	return PolicyDocument{
+		RBAC: &rbac,
+		ResourceTypes: []ResourceType{
+			{
+				Name:          "tenant",
+				IDPrefix:      "tnntten",
+				RoleBindingV2: &ResourceRoleBindingV2{},
+			},
+			{
+				Name:     "user",
+				IDPrefix: "idntusr",
+			},
+		},
+		Actions: []Action{
+			{Name: "tenant_get"},
+		},
+		ActionBindings: []ActionBinding{
+			{
+				TypeName:   "tenant",
+				ActionName: "tenant_get",
+				Conditions: []Condition{{RoleBindingV2: &ConditionRoleBindingV2{}}},
+			},
+		},
+	}
+}
@@ -22,8 +22,15 @@ const (
 	// PermissionRelationSuffix is the suffix append to the name of the relationship
 	// representing a permission in a role
 	PermissionRelationSuffix = "_rel"
+	// RolePermissionSuffix is the suffix appended to the name of the permission
+	// representing an action in a role, including the actions of the roles it includes
+	RolePermissionSuffix = "_perm"
+	// RoleIncludeRelation is the name of the relationship that connects a role to the roles it includes.
+	RoleIncludeRelation = "include"
 	// GrantRelationship is the name of the relationship that connects a role binding to a resource.
 	GrantRelationship = "grant"
+	// BuiltinRoleManager is the manager of roles materialized from the policy's built-in roles.
+	BuiltinRoleManager = "policy"
 )
 
 // RoleAction is the list of actions that can be performed on a role resource
@@ -56,6 +63,12 @@ const (
 	RoleBindingActionGet RoleBindingAction = "iam_rolebinding_get"
 	// RoleBindingActionList is the action name to list role bindings
 	RoleBindingActionList RoleBindingAction = "iam_rolebinding_list"
+	// RoleBindingActionApprove is the action name to approve or reject role binding requests
+	RoleBindingActionApprove RoleBindingAction = "iam_rolebinding_approve"
+	// RoleBindingActionBreakGlass is the action name to bind oneself to the break-glass emergency role
+	RoleBindingActionBreakGlass RoleBindingAction = "iam_rolebinding_breakglass"
+	// RoleBindingActionRelationshipList is the action name to list the relationships of a resource
+	RoleBindingActionRelationshipList RoleBindingAction = "iam_relationship_list"
 )
 
 // ResourceRoleBindingV2 describes the relationships that will be created
@@ -143,10 +156,33 @@ type RBAC struct {
 	// RoleBindingSubjects is the names of the resource types that can be subjects in a role binding.
 	// e.g. rolebinding_create, rolebinding_list, rolebinding_delete
 	RoleBindingSubjects []types.TargetType
+	// BuiltinRoles is the list of roles which are created automatically, with
+	// the BuiltinRoleManager manager, for every resource of their role owner types.
+	BuiltinRoles []BuiltinRole
+	// SeparationOfDuties is the list of constraints preventing a subject from holding
+	// mutually exclusive actions on the same resource.
+	SeparationOfDuties []SeparationOfDutiesConstraint
 
 	roleownersset map[string]struct{}
 }
 
+// BuiltinRole is a role declared in the policy which is materialized for
+// every resource of the given role owner types.
+type BuiltinRole struct {
+	Name       string
+	Actions    []string
+	RoleOwners []string
+}
+
+// SeparationOfDutiesConstraint declares mutually exclusive sets of actions, a subject may hold
+// actions from at most one of the sets on the same resource. The actions of built-in roles
+// named in BuiltinRoles each form an additional set.
+type SeparationOfDutiesConstraint struct {
+	Name         string
+	ActionSets   [][]string
+	BuiltinRoles []string
+}
+
 // RBACResourceDefinition is a struct to define a resource type for a role
 // and role-bindings
 type RBACResourceDefinition struct {
@@ -193,6 +229,9 @@ func (r *RBAC) CreateRoleBindingActionsForResource(inheritFrom ...string) []type
 		RoleBindingActionDelete,
 		RoleBindingActionGet,
 		RoleBindingActionList,
+		RoleBindingActionApprove,
+		RoleBindingActionBreakGlass,
+		RoleBindingActionRelationshipList,
 	}
 
 	actions := make([]types.Action, 0, len(actionsStr))
@@ -215,6 +254,9 @@ func (r *RBAC) RoleBindingActions() []Action {
 		RoleBindingActionDelete,
 		RoleBindingActionGet,
 		RoleBindingActionList,
+		RoleBindingActionApprove,
+		RoleBindingActionBreakGlass,
+		RoleBindingActionRelationshipList,
 	}
 
 	actions := make([]Action, 0, len(actionsStr)+1)
@@ -228,6 +270,37 @@ func (r *RBAC) RoleBindingActions() []Action {
 	return actions
 }
 
+// RoleActions returns the permissions of a role resource for the given actions. Each
+// permission grants the action if the role contains it or includes a role which grants it.
+// e.g. for a doc_read action, it will create the following permission:
+// doc_read_perm = doc_read_rel + include->doc_read_perm
+func (r *RBAC) RoleActions(actionNames ...string) []types.Action {
+	actions := make([]types.Action, 0, len(actionNames))
+
+	for _, actionName := range actionNames {
+		permission := actionName + RolePermissionSuffix
+
+		actions = append(actions, types.Action{
+			Name: permission,
+			Conditions: []types.Condition{
+				{
+					RelationshipAction: &types.ConditionRelationshipAction{
+						Relation: actionName + PermissionRelationSuffix,
+					},
+				},
+				{
+					RelationshipAction: &types.ConditionRelationshipAction{
+						Relation:   RoleIncludeRelation,
+						ActionName: permission,
+					},
+				},
+			},
+		})
+	}
+
+	return actions
+}
+
 // RoleOwnersSet returns the set of role owners for easy role owner lookups
 func (r *RBAC) RoleOwnersSet() map[string]struct{} {
 	if r.roleownersset == nil {
@@ -239,3 +312,42 @@ func (r *RBAC) RoleOwnersSet() map[string]struct{} {
 
 	return r.roleownersset
 }
+
+// BuiltinRolesForOwner returns the built-in roles which are materialized for
+// resources of the given role owner type.
+func (r *RBAC) BuiltinRolesForOwner(ownerType string) []BuiltinRole {
+	var roles []BuiltinRole
+
+	for _, role := range r.BuiltinRoles {
+		for _, owner := range role.RoleOwners {
+			if owner == ownerType {
+				roles = append(roles, role)
+
+				break
+			}
+		}
+	}
+
+	return roles
+}
+
+// SeparationOfDutiesSets returns the mutually exclusive action sets of the constraint, including
+// the actions of its built-in roles. Built-in roles sharing a name form a single set.
+func (r *RBAC) SeparationOfDutiesSets(constraint SeparationOfDutiesConstraint) [][]string {
+	sets := make([][]string, 0, len(constraint.ActionSets)+len(constraint.BuiltinRoles))
+	sets = append(sets, constraint.ActionSets...)
+
+	for _, name := range constraint.BuiltinRoles {
+		var actions []string
+
+		for _, role := range r.BuiltinRoles {
+			if role.Name == name {
+				actions = append(actions, role.Actions...)
+			}
+		}
+
+		sets = append(sets, actions)
+	}
+
+	return sets
+}
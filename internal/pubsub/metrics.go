@@ -0,0 +1,17 @@
+package pubsub
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	outcomeAck = "ack"
+	outcomeNak = "nak"
+)
+
+var messagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "permissions_api",
+	Name:      "subscriber_messages_total",
+	Help:      "Relationship request messages processed by the subscriber by whether they were acked or naked.",
+}, []string{"outcome"})
@@ -106,11 +106,17 @@ func (s Subscriber) listen(messages <-chan events.Request[events.AuthRelationshi
 		if err := s.processEvent(msg); err != nil {
 			elogger.Errorw("failed to process msg", "error", err)
 
+			messagesTotal.WithLabelValues(outcomeNak).Inc()
+
 			if nakErr := msg.Nak(nakDelay); nakErr != nil {
 				elogger.Warnw("error occurred while naking", "error", nakErr)
 			}
-		} else if ackErr := msg.Ack(); ackErr != nil {
-			elogger.Errorw("error occurred while acking", "error", ackErr)
+		} else {
+			messagesTotal.WithLabelValues(outcomeAck).Inc()
+
+			if ackErr := msg.Ack(); ackErr != nil {
+				elogger.Errorw("error occurred while acking", "error", ackErr)
+			}
 		}
 	}
 }
@@ -259,9 +265,19 @@ func (s *Subscriber) handleCreateEvent(ctx context.Context, msg events.Request[e
 		if !errors.Is(err, query.ErrInvalidRelationship) {
 			span.SetStatus(codes.Error, err.Error())
 		}
+
+		return respondRequest(ctx, elogger, msg, err)
 	}
 
-	return respondRequest(ctx, elogger, msg, err)
+	// built-in roles are materialized for new role owners. The relationships have
+	// already been created, so failures are logged rather than returned.
+	if _, err := s.qe.MaterializeBuiltinRoles(ctx, resource); err != nil {
+		span.RecordError(err)
+
+		elogger.Errorw("error materializing built-in roles", "error", err)
+	}
+
+	return respondRequest(ctx, elogger, msg, nil)
 }
 
 func (s *Subscriber) handleDeleteEvent(ctx context.Context, msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) error {
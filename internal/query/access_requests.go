@@ -0,0 +1,485 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// AccessRequestIDPrefix is the ID prefix of access requests.
+	AccessRequestIDPrefix = "permacr"
+
+	// AccessRequestManager is the manager of the role bindings created by approving access requests.
+	AccessRequestManager = "access-requests"
+)
+
+// CreateAccessRequest records a subject's pending request to be bound to a role on a resource.
+// Once approved, the role binding is kept for expiresIn, or until it is deleted if expiresIn is zero.
+func (e *engine) CreateAccessRequest(
+	ctx context.Context,
+	subject, resource, roleResource types.Resource,
+	reason string,
+	expiresIn time.Duration,
+) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateAccessRequest",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", subject.ID),
+			attribute.Stringer("role_id", roleResource.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	req, err := e.createAccessRequest(dbCtx, subject, resource, roleResource, reason, expiresIn, false)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) createAccessRequest(
+	dbCtx context.Context,
+	subject, resource, roleResource types.Resource,
+	reason string,
+	expiresIn time.Duration,
+	breakGlass bool,
+) (types.AccessRequest, error) {
+	if expiresIn < 0 {
+		return types.AccessRequest{}, fmt.Errorf("%w: access request expiry must not be negative", ErrInvalidArgument)
+	}
+
+	if _, err := e.rolebindingSubjectRelationship(subject, ""); err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.isRoleBindable(dbCtx, roleResource, resource); err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	if _, err := e.store.GetRoleByID(dbCtx, roleResource.ID); err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
+		}
+
+		return types.AccessRequest{}, err
+	}
+
+	// requests which could never be approved are rejected right away.
+	if err := e.checkSeparationOfDuties(dbCtx, resource, roleResource.ID, []types.Resource{subject}); err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	id, err := gidx.NewID(AccessRequestIDPrefix)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	return e.store.CreateAccessRequest(dbCtx, types.AccessRequest{
+		ID:         id,
+		ResourceID: resource.ID,
+		RoleID:     roleResource.ID,
+		SubjectID:  subject.ID,
+		Reason:     reason,
+		ExpiresIn:  expiresIn,
+		BreakGlass: breakGlass,
+	})
+}
+
+// GetAccessRequest fetches an access request by its ID.
+func (e *engine) GetAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetAccessRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	req, err := e.getAccessRequest(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) getAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	req, err := e.store.GetAccessRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAccessRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessRequestNotFound, err)
+		}
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+// ListAccessRequests returns a page of the access requests on the resource with the given
+// status, along with the next page cursor. An empty status lists requests of any status.
+func (e *engine) ListAccessRequests(
+	ctx context.Context, resource types.Resource, status types.AccessRequestStatus, opts ListOptions,
+) ([]types.AccessRequest, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListAccessRequests",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("status", string(status)),
+		),
+	)
+	defer span.End()
+
+	reqs, nextCursor, err := e.listAccessRequests(opts, func(storageOpts storage.ListOptions) ([]types.AccessRequest, error) {
+		return e.store.ListResourceAccessRequests(ctx, resource.ID, status, storageOpts)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	return reqs, nextCursor, nil
+}
+
+// ListSubjectAccessRequests returns a page of the access requests made by the subject with the
+// given status, along with the next page cursor. An empty status lists requests of any status.
+func (e *engine) ListSubjectAccessRequests(
+	ctx context.Context, subject types.Resource, status types.AccessRequestStatus, opts ListOptions,
+) ([]types.AccessRequest, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListSubjectAccessRequests",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", subject.ID),
+			attribute.String("status", string(status)),
+		),
+	)
+	defer span.End()
+
+	reqs, nextCursor, err := e.listAccessRequests(opts, func(storageOpts storage.ListOptions) ([]types.AccessRequest, error) {
+		return e.store.ListSubjectAccessRequests(ctx, subject.ID, status, storageOpts)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	return reqs, nextCursor, nil
+}
+
+// listAccessRequests lists a page of access requests with the list function, returning the next page cursor.
+func (e *engine) listAccessRequests(
+	opts ListOptions, list func(storage.ListOptions) ([]types.AccessRequest, error),
+) ([]types.AccessRequest, string, error) {
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		return nil, "", err
+	}
+
+	reqs, err := list(storageOpts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+
+	if len(reqs) != 0 {
+		nextCursor = opts.nextIDCursor(len(reqs), reqs[len(reqs)-1].ID)
+	}
+
+	return reqs, nextCursor, nil
+}
+
+// ApproveAccessRequest approves a pending access request, binding the requesting subject to the
+// role on behalf of the approver. A non-zero expiresIn overrides the expiry of the request.
+// The approver must not be the requesting subject, and must be able to approve role bindings
+// on the resource if the role's bindings require approval.
+func (e *engine) ApproveAccessRequest(
+	ctx context.Context, actor types.Resource, id gidx.PrefixedID, expiresIn time.Duration,
+) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ApproveAccessRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	req, updates, err := e.approveAccessRequest(dbCtx, actor, id, expiresIn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) approveAccessRequest(
+	dbCtx context.Context, actor types.Resource, id gidx.PrefixedID, expiresIn time.Duration,
+) (types.AccessRequest, []*pb.RelationshipUpdate, error) {
+	if expiresIn < 0 {
+		return types.AccessRequest{}, nil, fmt.Errorf("%w: access request expiry must not be negative", ErrInvalidArgument)
+	}
+
+	req, err := e.lockPendingAccessRequest(dbCtx, actor, id)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	subject, err := e.NewResourceFromID(req.SubjectID)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	resource, err := e.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	role, err := e.NewResourceFromID(req.RoleID)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	// the approver is the second subject confirming role bindings which require approval.
+	required, err := e.roleRequiresApproval(dbCtx, role)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	if required {
+		err := e.SubjectHasPermission(dbCtx, actor, string(iapl.RoleBindingActionApprove), resource)
+
+		switch {
+		case err == nil:
+			dbCtx = contextWithRoleBindingApproved(dbCtx)
+		case errors.Is(err, ErrActionNotAssigned):
+			return types.AccessRequest{}, nil, fmt.Errorf("%w: %s is required to approve role %s",
+				ErrAccessRequestReviewForbidden, iapl.RoleBindingActionApprove, role.ID)
+		default:
+			return types.AccessRequest{}, nil, err
+		}
+	}
+
+	subjects := []types.RoleBindingSubject{{SubjectResource: subject}}
+
+	rb, updates, err := e.prepareCreateRoleBinding(dbCtx, actor, resource, role, AccessRequestManager, subjects)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	if expiresIn == 0 {
+		expiresIn = req.ExpiresIn
+	}
+
+	var expiresAt *time.Time
+
+	if expiresIn > 0 {
+		expiry := rb.CreatedAt.Add(expiresIn)
+		expiresAt = &expiry
+	}
+
+	req, err = e.store.UpdateAccessRequestStatus(dbCtx, actor.ID, id, types.AccessRequestApproved, rb.ID, expiresAt)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	return req, updates, nil
+}
+
+// DenyAccessRequest denies a pending access request. The reviewer must not be the requesting subject.
+func (e *engine) DenyAccessRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DenyAccessRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	if _, err := e.lockPendingAccessRequest(dbCtx, actor, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	req, err := e.store.UpdateAccessRequestStatus(dbCtx, actor.ID, id, types.AccessRequestDenied, "", nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+// lockPendingAccessRequest locks the access request for review, ensuring it is still pending
+// and that the reviewer is not the requesting subject.
+func (e *engine) lockPendingAccessRequest(dbCtx context.Context, reviewer types.Resource, id gidx.PrefixedID) (types.AccessRequest, error) {
+	if err := e.store.LockAccessRequestForUpdate(dbCtx, id); err != nil {
+		if errors.Is(err, storage.ErrAccessRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessRequestNotFound, err)
+		}
+
+		return types.AccessRequest{}, err
+	}
+
+	req, err := e.getAccessRequest(dbCtx, id)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	if req.Status != types.AccessRequestPending {
+		return types.AccessRequest{}, fmt.Errorf("%w: request %s is %s", ErrAccessRequestNotPending, id, req.Status)
+	}
+
+	if req.SubjectID == reviewer.ID {
+		return types.AccessRequest{}, fmt.Errorf("%w: request %s is for the reviewer", ErrAccessRequestReviewForbidden, id)
+	}
+
+	return req, nil
+}
+
+// ExpireAccessRequests deletes the role bindings of up to limit approved access requests which
+// have expired, marking the requests as expired. It returns the number of requests expired.
+// A request which fails to expire is logged and skipped so it doesn't hold back the others,
+// and is retried on the next call. Only failures to list the expired requests are returned.
+func (e *engine) ExpireAccessRequests(ctx context.Context, limit int) (int, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.ExpireAccessRequests")
+	defer span.End()
+
+	reqs, err := e.store.ListExpiredAccessRequests(ctx, time.Now(), limit)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return 0, err
+	}
+
+	expired := 0
+
+	for _, req := range reqs {
+		if err := e.expireAccessRequest(ctx, req); err != nil {
+			span.RecordError(err)
+
+			e.logger.Errorw("error expiring access request", "access_request_id", req.ID, "error", err)
+
+			continue
+		}
+
+		expired++
+	}
+
+	span.SetAttributes(
+		attribute.Int("expired", expired),
+		attribute.Int("failed", len(reqs)-expired),
+	)
+
+	return expired, nil
+}
+
+func (e *engine) expireAccessRequest(ctx context.Context, req types.AccessRequest) error {
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := e.store.LockAccessRequestForUpdate(dbCtx, req.ID); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	rb, err := e.NewResourceFromID(req.RoleBindingID)
+	if err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	// the role binding may have been deleted before it expired.
+	updates, err := e.prepareDeleteRoleBinding(dbCtx, rb)
+	if err != nil && !errors.Is(err, storage.ErrRoleBindingNotFound) {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	if _, err := e.store.ExpireAccessRequest(dbCtx, req.ID); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	return e.commitRoleBindingUpdates(ctx, dbCtx, updates)
+}
@@ -0,0 +1,160 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// failingExpiryStore fails to expire the access request with the given ID.
+type failingExpiryStore struct {
+	storage.Storage
+
+	failID gidx.PrefixedID
+}
+
+func (s failingExpiryStore) ExpireAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	if id == s.failID {
+		return types.AccessRequest{}, errors.New("expire failed")
+	}
+
+	return s.Storage.ExpireAccessRequest(ctx, id)
+}
+
+func TestAccessRequests(t *testing.T) {
+	namespace := "testaccessrequests"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	requester, err := e.NewResourceFromIDString("idntusr-requester")
+	require.NoError(t, err)
+
+	approver, err := e.NewResourceFromIDString("idntusr-approver")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, approver, root, "", "viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleResource, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	t.Run("Approve", func(t *testing.T) {
+		req, err := e.CreateAccessRequest(ctx, requester, root, roleResource, "on-call", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestPending, req.Status)
+		assert.Equal(t, time.Hour, req.ExpiresIn)
+
+		_, err = e.ApproveAccessRequest(ctx, requester, req.ID, 0)
+		assert.ErrorIs(t, err, ErrAccessRequestReviewForbidden)
+
+		approved, err := e.ApproveAccessRequest(ctx, approver, req.ID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestApproved, approved.Status)
+		assert.Equal(t, approver.ID, approved.ReviewedBy)
+		require.NotNil(t, approved.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *approved.ExpiresAt, time.Minute)
+
+		rbResource, err := e.NewResourceFromID(approved.RoleBindingID)
+		require.NoError(t, err)
+
+		rb, err := e.GetRoleBinding(ctx, rbResource)
+		require.NoError(t, err)
+		assert.Equal(t, AccessRequestManager, rb.Manager)
+		assert.Equal(t, []gidx.PrefixedID{requester.ID}, rb.SubjectIDs)
+
+		_, err = e.DenyAccessRequest(ctx, approver, req.ID)
+		assert.ErrorIs(t, err, ErrAccessRequestNotPending)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		req, err := e.CreateAccessRequest(ctx, requester, root, roleResource, "", 0)
+		require.NoError(t, err)
+
+		denied, err := e.DenyAccessRequest(ctx, approver, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestDenied, denied.Status)
+		assert.Empty(t, denied.RoleBindingID)
+
+		reqs, _, err := e.ListSubjectAccessRequests(ctx, requester, types.AccessRequestDenied, ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, reqs, 1)
+		assert.Equal(t, req.ID, reqs[0].ID)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		req, err := e.CreateAccessRequest(ctx, requester, root, roleResource, "", 0)
+		require.NoError(t, err)
+
+		approved, err := e.ApproveAccessRequest(ctx, approver, req.ID, time.Nanosecond)
+		require.NoError(t, err)
+
+		expired, err := e.ExpireAccessRequests(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+
+		rbResource, err := e.NewResourceFromID(approved.RoleBindingID)
+		require.NoError(t, err)
+
+		_, err = e.GetRoleBinding(ctx, rbResource)
+		assert.ErrorIs(t, err, ErrRoleBindingNotFound)
+
+		req, err = e.GetAccessRequest(ctx, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestExpired, req.Status)
+	})
+
+	t.Run("ExpireSkipsFailures", func(t *testing.T) {
+		approve := func() types.AccessRequest {
+			req, err := e.CreateAccessRequest(ctx, requester, root, roleResource, "", 0)
+			require.NoError(t, err)
+
+			approved, err := e.ApproveAccessRequest(ctx, approver, req.ID, time.Nanosecond)
+			require.NoError(t, err)
+
+			return approved
+		}
+
+		failing := approve()
+		other := approve()
+
+		store := e.store
+		e.store = failingExpiryStore{Storage: store, failID: failing.ID}
+
+		t.Cleanup(func() { e.store = store })
+
+		expired, err := e.ExpireAccessRequests(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+
+		req, err := e.GetAccessRequest(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestExpired, req.Status)
+
+		req, err = e.GetAccessRequest(ctx, failing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccessRequestApproved, req.Status)
+
+		rbResource, err := e.NewResourceFromID(failing.RoleBindingID)
+		require.NoError(t, err)
+
+		_, err = e.GetRoleBinding(ctx, rbResource)
+		require.NoError(t, err)
+
+		e.store = store
+
+		expired, err = e.ExpireAccessRequests(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+	})
+}
@@ -0,0 +1,505 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// AccessReviewIDPrefix is the ID prefix of access reviews.
+	AccessReviewIDPrefix = "permarv"
+
+	// AccessReviewItemIDPrefix is the ID prefix of access review items.
+	AccessReviewItemIDPrefix = "permari"
+
+	// maxResourcesBeneath is the maximum number of resources access reviews and unused access
+	// reports may cover.
+	maxResourcesBeneath = 10000
+)
+
+// CreateAccessReview opens an access review campaign snapshotting the subjects of every role-binding
+// on the resource and the resources related to it, recursively, as pending items.
+func (e *engine) CreateAccessReview(ctx context.Context, actor, resource types.Resource, name string) (types.AccessReview, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateAccessReview",
+		trace.WithAttributes(attribute.Stringer("resource_id", resource.ID)),
+	)
+	defer span.End()
+
+	review, err := e.createAccessReview(ctx, actor, resource, name)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessReview{}, err
+	}
+
+	return review, nil
+}
+
+func (e *engine) createAccessReview(ctx context.Context, actor, resource types.Resource, name string) (types.AccessReview, error) {
+	reviewID, err := gidx.NewID(AccessReviewIDPrefix)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	resources, err := e.resourcesBeneath(ctx, resource)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	var items []types.AccessReviewItem
+
+	for _, res := range resources {
+		bindings, _, err := e.ListRoleBindings(ctx, res, nil, RoleBindingFilter{}, ListOptions{})
+		if err != nil {
+			return types.AccessReview{}, err
+		}
+
+		for _, rb := range bindings {
+			for _, subjID := range rb.SubjectIDs {
+				itemID, err := gidx.NewID(AccessReviewItemIDPrefix)
+				if err != nil {
+					return types.AccessReview{}, err
+				}
+
+				items = append(items, types.AccessReviewItem{
+					ID:            itemID,
+					ReviewID:      reviewID,
+					RoleBindingID: rb.ID,
+					ResourceID:    rb.ResourceID,
+					RoleID:        rb.RoleID,
+					SubjectID:     subjID,
+				})
+			}
+		}
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	review, err := e.store.CreateAccessReview(dbCtx, types.AccessReview{
+		ID:         reviewID,
+		ResourceID: resource.ID,
+		Name:       name,
+		CreatedBy:  actor.ID,
+	}, items)
+	if err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	return review, nil
+}
+
+// resourcesBeneath returns the resource along with every resource which may be bound to roles
+// beneath it, found by following the relationships whose subject is the resource.
+func (e *engine) resourcesBeneath(ctx context.Context, resource types.Resource) ([]types.Resource, error) {
+	bindable := make(map[string]struct{}, len(e.rbacV2ResourceTypes))
+
+	for _, res := range e.rbacV2ResourceTypes {
+		bindable[res.Name] = struct{}{}
+	}
+
+	queue := []types.Resource{resource}
+	seen := map[gidx.PrefixedID]struct{}{resource.ID: {}}
+
+	var resources []types.Resource
+
+	for i := 0; i < len(queue); i++ {
+		res := queue[i]
+
+		if _, ok := bindable[res.Type]; ok {
+			resources = append(resources, res)
+		}
+
+		if _, ok := e.schemaSubjectRelationMap[res.Type]; !ok {
+			continue
+		}
+
+		rels, _, err := e.ListRelationshipsTo(ctx, res, ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range rels {
+			// roles and role-bindings refer to the resources they are owned by or granted on,
+			// but do not have role-bindings of their own.
+			if rel.Resource.Type == e.rbac.RoleResource.Name || rel.Resource.Type == e.rbac.RoleBindingResource.Name {
+				continue
+			}
+
+			if _, ok := seen[rel.Resource.ID]; ok {
+				continue
+			}
+
+			if len(queue) >= maxResourcesBeneath {
+				return nil, fmt.Errorf("%w: more than %d resources under %s", ErrTooManyResources, maxResourcesBeneath, resource.ID)
+			}
+
+			seen[rel.Resource.ID] = struct{}{}
+			queue = append(queue, rel.Resource)
+		}
+	}
+
+	return resources, nil
+}
+
+// GetAccessReview fetches an access review by its ID.
+func (e *engine) GetAccessReview(ctx context.Context, id gidx.PrefixedID) (types.AccessReview, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetAccessReview",
+		trace.WithAttributes(attribute.Stringer("review_id", id)),
+	)
+	defer span.End()
+
+	review, err := e.getAccessReview(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessReview{}, err
+	}
+
+	return review, nil
+}
+
+func (e *engine) getAccessReview(ctx context.Context, id gidx.PrefixedID) (types.AccessReview, error) {
+	review, err := e.store.GetAccessReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAccessReviewNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessReviewNotFound, err)
+		}
+
+		return types.AccessReview{}, err
+	}
+
+	return review, nil
+}
+
+// ListAccessReviews lists a page of the access reviews of a resource with the given status,
+// along with the next page cursor. An empty status lists reviews of any status.
+func (e *engine) ListAccessReviews(
+	ctx context.Context, resource types.Resource, status types.AccessReviewStatus, opts ListOptions,
+) ([]types.AccessReview, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListAccessReviews",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("status", string(status)),
+		),
+	)
+	defer span.End()
+
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	reviews, err := e.store.ListResourceAccessReviews(ctx, resource.ID, status, storageOpts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	var nextCursor string
+
+	if len(reviews) != 0 {
+		nextCursor = opts.nextIDCursor(len(reviews), reviews[len(reviews)-1].ID)
+	}
+
+	return reviews, nextCursor, nil
+}
+
+// ListAccessReviewItems lists a page of the items of an access review with the given decision,
+// along with the next page cursor. An empty decision lists items with any decision.
+func (e *engine) ListAccessReviewItems(
+	ctx context.Context, id gidx.PrefixedID, decision types.AccessReviewDecision, opts ListOptions,
+) ([]types.AccessReviewItem, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListAccessReviewItems",
+		trace.WithAttributes(
+			attribute.Stringer("review_id", id),
+			attribute.String("decision", string(decision)),
+		),
+	)
+	defer span.End()
+
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	items, err := e.store.ListAccessReviewItems(ctx, id, decision, storageOpts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	var nextCursor string
+
+	if len(items) != 0 {
+		nextCursor = opts.nextIDCursor(len(items), items[len(items)-1].ID)
+	}
+
+	return items, nextCursor, nil
+}
+
+// DecideAccessReviewItem records the decision to keep or revoke an item of an open access review.
+// The decision may be changed until the review is closed.
+func (e *engine) DecideAccessReviewItem(
+	ctx context.Context, actor types.Resource, id, itemID gidx.PrefixedID, decision types.AccessReviewDecision,
+) (types.AccessReviewItem, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DecideAccessReviewItem",
+		trace.WithAttributes(
+			attribute.Stringer("review_id", id),
+			attribute.Stringer("item_id", itemID),
+			attribute.String("decision", string(decision)),
+		),
+	)
+	defer span.End()
+
+	item, err := e.decideAccessReviewItem(ctx, actor, id, itemID, decision)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessReviewItem{}, err
+	}
+
+	return item, nil
+}
+
+func (e *engine) decideAccessReviewItem(
+	ctx context.Context, actor types.Resource, id, itemID gidx.PrefixedID, decision types.AccessReviewDecision,
+) (types.AccessReviewItem, error) {
+	switch decision {
+	case types.AccessReviewPending, types.AccessReviewKeep, types.AccessReviewRevoke:
+	default:
+		return types.AccessReviewItem{}, fmt.Errorf("%w: %q", ErrInvalidAccessReviewDecision, decision)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return types.AccessReviewItem{}, err
+	}
+
+	if _, err := e.lockOpenAccessReview(dbCtx, id); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReviewItem{}, err
+	}
+
+	item, err := e.store.UpdateAccessReviewItemDecision(dbCtx, actor.ID, id, itemID, decision)
+	if err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrAccessReviewItemNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessReviewItemNotFound, err)
+		}
+
+		return types.AccessReviewItem{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReviewItem{}, err
+	}
+
+	return item, nil
+}
+
+// CloseAccessReview closes an open access review, removing the subjects of the items decided
+// to be revoked from their role-bindings. Role-bindings left without subjects are deleted.
+// Items which were not decided are kept.
+//
+// Revocations are applied before the review is closed, so a review which fails to close
+// may be closed again to apply the remaining revocations.
+func (e *engine) CloseAccessReview(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.AccessReview, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CloseAccessReview",
+		trace.WithAttributes(attribute.Stringer("review_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessReview{}, err
+	}
+
+	if _, err := e.lockOpenAccessReview(dbCtx, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	// the review is only closed once every revocation has been applied. If any fails the
+	// review stays open, and closing it again applies the revocations which are left.
+	if err := e.applyAccessReviewRevocations(ctx, dbCtx, actor, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	review, err := e.store.CloseAccessReview(dbCtx, actor.ID, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessReview{}, err
+	}
+
+	return review, nil
+}
+
+// applyAccessReviewRevocations removes the subjects of the review's revoked items from their role-bindings.
+// Each role-binding is updated or deleted in its own transaction, skipping those which no longer exist or
+// whose revoked subjects were already removed. A role-binding which fails to update doesn't stop the
+// others from being revoked, and the failures are returned together.
+func (e *engine) applyAccessReviewRevocations(ctx, dbCtx context.Context, actor types.Resource, id gidx.PrefixedID) error {
+	items, err := e.store.ListAccessReviewItems(dbCtx, id, types.AccessReviewRevoke, storage.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var rbIDs []gidx.PrefixedID
+
+	revoked := map[gidx.PrefixedID]map[gidx.PrefixedID]struct{}{}
+
+	for _, item := range items {
+		if _, ok := revoked[item.RoleBindingID]; !ok {
+			revoked[item.RoleBindingID] = map[gidx.PrefixedID]struct{}{}
+			rbIDs = append(rbIDs, item.RoleBindingID)
+		}
+
+		revoked[item.RoleBindingID][item.SubjectID] = struct{}{}
+	}
+
+	var errs []error
+
+	for _, rbID := range rbIDs {
+		if err := e.revokeRoleBindingSubjects(ctx, actor, rbID, revoked[rbID]); err != nil {
+			e.logger.Errorw("error applying access review revocation", "review_id", id, "rolebinding_id", rbID, "error", err)
+
+			errs = append(errs, fmt.Errorf("%w: %s", err, rbID))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// revokeRoleBindingSubjects removes the revoked subjects from the role-binding, deleting it when no subjects are left.
+func (e *engine) revokeRoleBindingSubjects(
+	ctx context.Context, actor types.Resource, rbID gidx.PrefixedID, revoked map[gidx.PrefixedID]struct{},
+) error {
+	rbResource, err := e.NewResourceFromID(rbID)
+	if err != nil {
+		return err
+	}
+
+	rb, err := e.GetRoleBinding(ctx, rbResource)
+	if err != nil {
+		if errors.Is(err, ErrRoleBindingNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	var subjects []types.RoleBindingSubject
+
+	for _, subjID := range rb.SubjectIDs {
+		if _, ok := revoked[subjID]; ok {
+			continue
+		}
+
+		subj, err := e.NewResourceFromID(subjID)
+		if err != nil {
+			return err
+		}
+
+		subjects = append(subjects, types.RoleBindingSubject{SubjectResource: subj})
+	}
+
+	switch {
+	case len(subjects) == len(rb.SubjectIDs):
+		return nil
+	case len(subjects) == 0:
+		err = e.DeleteRoleBinding(ctx, rbResource)
+	default:
+		_, err = e.UpdateRoleBinding(ctx, actor, rbResource, subjects)
+	}
+
+	if err != nil && !errors.Is(err, storage.ErrRoleBindingNotFound) && !errors.Is(err, ErrRoleBindingNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (e *engine) lockOpenAccessReview(dbCtx context.Context, id gidx.PrefixedID) (types.AccessReview, error) {
+	if err := e.store.LockAccessReviewForUpdate(dbCtx, id); err != nil {
+		if errors.Is(err, storage.ErrAccessReviewNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessReviewNotFound, err)
+		}
+
+		return types.AccessReview{}, err
+	}
+
+	review, err := e.getAccessReview(dbCtx, id)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	if review.Status != types.AccessReviewOpen {
+		return types.AccessReview{}, fmt.Errorf("%w: review %s", ErrAccessReviewClosed, id)
+	}
+
+	return review, nil
+}
@@ -0,0 +1,202 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// failingRoleBindingLockStore fails to lock the role binding with the given ID for updates.
+type failingRoleBindingLockStore struct {
+	storage.Storage
+
+	failID gidx.PrefixedID
+}
+
+func (s failingRoleBindingLockStore) LockRoleBindingForUpdate(ctx context.Context, id gidx.PrefixedID) error {
+	if id == s.failID {
+		return errors.New("lock failed")
+	}
+
+	return s.Storage.LockRoleBindingForUpdate(ctx, id)
+}
+
+func TestAccessReviews(t *testing.T) {
+	namespace := "testaccessreviews"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, namespace),
+	})
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user1, err := e.NewResourceFromIDString("idntusr-user1")
+	require.NoError(t, err)
+
+	user2, err := e.NewResourceFromIDString("idntusr-user2")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, root, "", "viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleResource, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	rootRB, err := e.CreateRoleBinding(ctx, actor, root, roleResource, "", []types.RoleBindingSubject{
+		{SubjectResource: user1}, {SubjectResource: user2},
+	})
+	require.NoError(t, err)
+
+	childRB, err := e.CreateRoleBinding(ctx, actor, child, roleResource, "", []types.RoleBindingSubject{
+		{SubjectResource: user1},
+	})
+	require.NoError(t, err)
+
+	review, err := e.CreateAccessReview(ctx, actor, root, "quarterly")
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessReviewOpen, review.Status)
+
+	items, _, err := e.ListAccessReviewItems(ctx, review.ID, types.AccessReviewPending, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	for _, item := range items {
+		decision := types.AccessReviewKeep
+
+		if item.SubjectID == user1.ID {
+			decision = types.AccessReviewRevoke
+		}
+
+		_, err := e.DecideAccessReviewItem(ctx, actor, review.ID, item.ID, decision)
+		require.NoError(t, err)
+	}
+
+	_, err = e.DecideAccessReviewItem(ctx, actor, review.ID, items[0].ID, "maybe")
+	assert.ErrorIs(t, err, ErrInvalidAccessReviewDecision)
+
+	closed, err := e.CloseAccessReview(ctx, actor, review.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessReviewClosed, closed.Status)
+	assert.Equal(t, actor.ID, closed.ClosedBy)
+
+	rbResource, err := e.NewResourceFromID(rootRB.ID)
+	require.NoError(t, err)
+
+	rb, err := e.GetRoleBinding(ctx, rbResource)
+	require.NoError(t, err)
+	assert.Equal(t, []gidx.PrefixedID{user2.ID}, rb.SubjectIDs)
+
+	rbResource, err = e.NewResourceFromID(childRB.ID)
+	require.NoError(t, err)
+
+	_, err = e.GetRoleBinding(ctx, rbResource)
+	assert.ErrorIs(t, err, ErrRoleBindingNotFound)
+
+	_, err = e.DecideAccessReviewItem(ctx, actor, review.ID, items[0].ID, types.AccessReviewKeep)
+	assert.ErrorIs(t, err, ErrAccessReviewClosed)
+
+	_, err = e.CloseAccessReview(ctx, actor, review.ID)
+	assert.ErrorIs(t, err, ErrAccessReviewClosed)
+}
+
+func TestCloseAccessReviewRetry(t *testing.T) {
+	namespace := "testaccessreviews"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user1, err := e.NewResourceFromIDString("idntusr-user1")
+	require.NoError(t, err)
+
+	user2, err := e.NewResourceFromIDString("idntusr-user2")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, root, "", "viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleResource, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	failingRB, err := e.CreateRoleBinding(ctx, actor, root, roleResource, "", []types.RoleBindingSubject{{SubjectResource: user1}})
+	require.NoError(t, err)
+
+	otherRB, err := e.CreateRoleBinding(ctx, actor, root, roleResource, "", []types.RoleBindingSubject{
+		{SubjectResource: user1}, {SubjectResource: user2},
+	})
+	require.NoError(t, err)
+
+	review, err := e.CreateAccessReview(ctx, actor, root, "quarterly")
+	require.NoError(t, err)
+
+	items, _, err := e.ListAccessReviewItems(ctx, review.ID, types.AccessReviewPending, ListOptions{})
+	require.NoError(t, err)
+
+	for _, item := range items {
+		if item.SubjectID != user1.ID {
+			continue
+		}
+
+		_, err := e.DecideAccessReviewItem(ctx, actor, review.ID, item.ID, types.AccessReviewRevoke)
+		require.NoError(t, err)
+	}
+
+	store := e.store
+	e.store = failingRoleBindingLockStore{Storage: store, failID: failingRB.ID}
+
+	_, err = e.CloseAccessReview(ctx, actor, review.ID)
+	e.store = store
+
+	require.Error(t, err)
+
+	review, err = e.GetAccessReview(ctx, review.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessReviewOpen, review.Status)
+
+	failingRes, err := e.NewResourceFromID(failingRB.ID)
+	require.NoError(t, err)
+
+	otherRes, err := e.NewResourceFromID(otherRB.ID)
+	require.NoError(t, err)
+
+	// the other role-binding is revoked even though the first one failed.
+	rb, err := e.GetRoleBinding(ctx, otherRes)
+	require.NoError(t, err)
+	assert.Equal(t, []gidx.PrefixedID{user2.ID}, rb.SubjectIDs)
+
+	_, err = e.GetRoleBinding(ctx, failingRes)
+	require.NoError(t, err)
+
+	closed, err := e.CloseAccessReview(ctx, actor, review.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessReviewClosed, closed.Status)
+
+	_, err = e.GetRoleBinding(ctx, failingRes)
+	assert.ErrorIs(t, err, ErrRoleBindingNotFound)
+
+	rb, err = e.GetRoleBinding(ctx, otherRes)
+	require.NoError(t, err)
+	assert.Equal(t, []gidx.PrefixedID{user2.ID}, rb.SubjectIDs)
+}
@@ -0,0 +1,194 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// BreakGlassManager is the manager of the role bindings created by breaking glass.
+	BreakGlassManager = "break-glass"
+
+	// BreakGlassEventType is the event type published when a subject breaks glass.
+	BreakGlassEventType = "break-glass"
+
+	// BreakGlassAbortedEventType is the event type published when a break-glass role binding whose
+	// event was already published fails to be created.
+	BreakGlassAbortedEventType = "break-glass-aborted"
+
+	// breakGlassEventSource is the source of published break-glass events.
+	breakGlassEventSource = "permissions-api"
+)
+
+// BreakGlassConfig configures break-glass access, which binds subjects to an emergency role
+// on a resource for a fixed duration.
+type BreakGlassConfig struct {
+	// RoleID is the emergency role subjects are bound to. Break-glass access is disabled if empty.
+	RoleID gidx.PrefixedID
+	// Duration is how long the role binding is kept before it is removed. It must be positive.
+	Duration time.Duration
+	// Publisher publishes an event for every break-glass role binding, to the topic of the resource's type.
+	Publisher events.Publisher
+}
+
+// BreakGlass binds the actor to the emergency role on the resource for the configured duration.
+// The justification is recorded in a break-glass access request, approved by the actor, whose role
+// binding is removed once it expires like any other access request's. Role bindings of the emergency
+// role are created regardless of the actions the actor holds and of the role requiring approval.
+//
+// The break-glass event is published before the changes are committed, so no role binding is
+// ever created without an event, even if publishing fails after the commit. The trade-off is
+// that an event may announce a role binding which then fails to be committed, so a
+// break-glass-aborted event is published for the same access request when the commit fails.
+func (e *engine) BreakGlass(ctx context.Context, actor, resource types.Resource, justification string) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.BreakGlass",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", actor.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	if e.breakGlass.RoleID == "" || e.breakGlass.Duration <= 0 || e.breakGlass.Publisher == nil {
+		span.RecordError(ErrBreakGlassNotConfigured)
+		span.SetStatus(codes.Error, ErrBreakGlassNotConfigured.Error())
+
+		return types.AccessRequest{}, ErrBreakGlassNotConfigured
+	}
+
+	justification = strings.TrimSpace(justification)
+
+	if justification == "" {
+		span.RecordError(ErrBreakGlassJustificationRequired)
+		span.SetStatus(codes.Error, ErrBreakGlassJustificationRequired.Error())
+
+		return types.AccessRequest{}, ErrBreakGlassJustificationRequired
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	req, updates, err := e.breakGlassAccessRequest(dbCtx, actor, resource, justification)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.publishBreakGlassEvent(ctx, BreakGlassEventType, resource, req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		// the request may have been canceled, the aborted event must still be published.
+		if pubErr := e.publishBreakGlassEvent(context.WithoutCancel(ctx), BreakGlassAbortedEventType, resource, req); pubErr != nil {
+			span.RecordError(pubErr)
+
+			e.logger.Errorw("break-glass aborted but the aborted event failed to publish, the published break-glass event is stale",
+				"subject_id", actor.ID,
+				"resource_id", resource.ID,
+				"access_request_id", req.ID,
+				"error", pubErr,
+			)
+		}
+
+		return types.AccessRequest{}, err
+	}
+
+	e.logger.Warnw("break-glass role binding created",
+		"subject_id", actor.ID,
+		"resource_id", resource.ID,
+		"role_id", req.RoleID,
+		"rolebinding_id", req.RoleBindingID,
+		"access_request_id", req.ID,
+		"justification", justification,
+	)
+
+	return req, nil
+}
+
+func (e *engine) breakGlassAccessRequest(
+	dbCtx context.Context, actor, resource types.Resource, justification string,
+) (types.AccessRequest, []*pb.RelationshipUpdate, error) {
+	role, err := e.NewResourceFromID(e.breakGlass.RoleID)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	req, err := e.createAccessRequest(dbCtx, actor, resource, role, justification, e.breakGlass.Duration, true)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	// the emergency role is granted by configuration rather than by the actor or an approver.
+	rbCtx := contextWithoutEscalationGuard(contextWithRoleBindingApproved(dbCtx))
+	subjects := []types.RoleBindingSubject{{SubjectResource: actor}}
+
+	rb, updates, err := e.prepareCreateRoleBinding(rbCtx, actor, resource, role, BreakGlassManager, subjects)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	expiresAt := rb.CreatedAt.Add(e.breakGlass.Duration)
+
+	req, err = e.store.UpdateAccessRequestStatus(dbCtx, actor.ID, req.ID, types.AccessRequestApproved, rb.ID, &expiresAt)
+	if err != nil {
+		return types.AccessRequest{}, nil, err
+	}
+
+	return req, updates, nil
+}
+
+func (e *engine) publishBreakGlassEvent(ctx context.Context, eventType string, resource types.Resource, req types.AccessRequest) error {
+	msg := events.EventMessage{
+		SubjectID:            req.ResourceID,
+		EventType:            eventType,
+		AdditionalSubjectIDs: []gidx.PrefixedID{req.SubjectID, req.RoleID, req.RoleBindingID, req.ID},
+		Source:               breakGlassEventSource,
+		Timestamp:            time.Now().UTC(),
+		Data: map[string]interface{}{
+			"priority":          "high",
+			"subject_id":        req.SubjectID.String(),
+			"role_id":           req.RoleID.String(),
+			"role_binding_id":   req.RoleBindingID.String(),
+			"access_request_id": req.ID.String(),
+			"justification":     req.Reason,
+		},
+	}
+
+	if req.ExpiresAt != nil {
+		msg.Data["expires_at"] = req.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if _, err := e.breakGlass.Publisher.PublishEvent(ctx, resource.Type, msg); err != nil {
+		return fmt.Errorf("error publishing %s event: %w", eventType, err)
+	}
+
+	return nil
+}
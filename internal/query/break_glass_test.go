@@ -0,0 +1,147 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+var (
+	errPublishFailed = errors.New("publish failed")
+	errCommitFailed  = errors.New("commit failed")
+)
+
+// failingCommitStore fails to commit transactions.
+type failingCommitStore struct {
+	storage.Storage
+}
+
+func (s failingCommitStore) CommitContext(context.Context) error {
+	return errCommitFailed
+}
+
+// testEventPublisher records the events published, failing them if err is set.
+type testEventPublisher struct {
+	topics []string
+	events []events.EventMessage
+	err    error
+}
+
+func (p *testEventPublisher) PublishChange(context.Context, string, events.ChangeMessage) (events.Message[events.ChangeMessage], error) {
+	return nil, nil
+}
+
+func (p *testEventPublisher) PublishEvent(_ context.Context, topic string, msg events.EventMessage) (events.Message[events.EventMessage], error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	p.topics = append(p.topics, topic)
+	p.events = append(p.events, msg)
+
+	return nil, nil
+}
+
+func TestBreakGlass(t *testing.T) {
+	namespace := "testbreakglass"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	admin, err := e.NewResourceFromIDString("idntusr-admin")
+	require.NoError(t, err)
+
+	oncall, err := e.NewResourceFromIDString("idntusr-oncall")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, admin, root, "", "emergency", []string{"loadbalancer_get", "loadbalancer_update"})
+	require.NoError(t, err)
+
+	_, err = e.BreakGlass(ctx, oncall, root, "incident")
+	assert.ErrorIs(t, err, ErrBreakGlassNotConfigured)
+
+	publisher := &testEventPublisher{}
+
+	e.breakGlass = BreakGlassConfig{
+		RoleID:    role.ID,
+		Duration:  time.Hour,
+		Publisher: publisher,
+	}
+
+	t.Run("JustificationRequired", func(t *testing.T) {
+		_, err := e.BreakGlass(ctx, oncall, root, " ")
+		assert.ErrorIs(t, err, ErrBreakGlassJustificationRequired)
+	})
+
+	t.Run("PublishFailed", func(t *testing.T) {
+		publisher.err = errPublishFailed
+		defer func() { publisher.err = nil }()
+
+		_, err := e.BreakGlass(ctx, oncall, root, "incident")
+		assert.ErrorIs(t, err, errPublishFailed)
+
+		reqs, _, err := e.ListSubjectAccessRequests(ctx, oncall, "", ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, reqs)
+	})
+
+	t.Run("BreakGlass", func(t *testing.T) {
+		req, err := e.BreakGlass(ctx, oncall, root, "incident")
+		require.NoError(t, err)
+		assert.True(t, req.BreakGlass)
+		assert.Equal(t, types.AccessRequestApproved, req.Status)
+		assert.Equal(t, oncall.ID, req.ReviewedBy)
+		assert.Equal(t, "incident", req.Reason)
+		require.NotNil(t, req.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *req.ExpiresAt, time.Minute)
+
+		rbResource, err := e.NewResourceFromID(req.RoleBindingID)
+		require.NoError(t, err)
+
+		rb, err := e.GetRoleBinding(ctx, rbResource)
+		require.NoError(t, err)
+		assert.Equal(t, BreakGlassManager, rb.Manager)
+		assert.Equal(t, []gidx.PrefixedID{oncall.ID}, rb.SubjectIDs)
+
+		require.Len(t, publisher.events, 1)
+		assert.Equal(t, []string{"tenant"}, publisher.topics)
+		assert.Equal(t, BreakGlassEventType, publisher.events[0].EventType)
+		assert.Equal(t, root.ID, publisher.events[0].SubjectID)
+		assert.Equal(t, "high", publisher.events[0].Data["priority"])
+	})
+
+	t.Run("CommitFailed", func(t *testing.T) {
+		responder, err := e.NewResourceFromIDString("idntusr-responder")
+		require.NoError(t, err)
+
+		publisher.topics, publisher.events = nil, nil
+
+		store := e.store
+		e.store = failingCommitStore{Storage: store}
+
+		_, err = e.BreakGlass(ctx, responder, root, "incident")
+		e.store = store
+
+		assert.ErrorIs(t, err, errCommitFailed)
+
+		reqs, _, err := e.ListSubjectAccessRequests(ctx, responder, "", ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, reqs)
+
+		require.Len(t, publisher.events, 2)
+		assert.Equal(t, BreakGlassEventType, publisher.events[0].EventType)
+		assert.Equal(t, BreakGlassAbortedEventType, publisher.events[1].EventType)
+		assert.Equal(t, publisher.events[0].Data["access_request_id"], publisher.events[1].Data["access_request_id"])
+	})
+}
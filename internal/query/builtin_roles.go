@@ -0,0 +1,227 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// MaterializeBuiltinRoles creates the policy's built-in roles for the owner and updates
+// the actions of existing built-in roles which differ from the policy. Built-in roles are
+// managed by iapl.BuiltinRoleManager and are recorded as created by their owner.
+// Roles removed from the policy are left in place, as they may still be bound.
+func (e *engine) MaterializeBuiltinRoles(ctx context.Context, owner types.Resource) ([]types.Role, error) {
+	builtins := e.rbac.BuiltinRolesForOwner(owner.Type)
+	if len(builtins) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := e.tracer.Start(
+		ctx,
+		"engine.MaterializeBuiltinRoles",
+		trace.WithAttributes(
+			attribute.Stringer("owner", owner.ID),
+			attribute.Int("roles", len(builtins)),
+		),
+	)
+	defer span.End()
+
+	// built-in roles are defined by the policy rather than granted by an actor.
+	ctx = contextWithoutEscalationGuard(contextWithRoleBindingApproved(ctx))
+
+	roles, err := e.materializeBuiltinRoles(ctx, owner, builtins)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (e *engine) materializeBuiltinRoles(ctx context.Context, owner types.Resource, builtins []iapl.BuiltinRole) ([]types.Role, error) {
+	existingByName, err := e.ownedBuiltinRoles(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]types.Role, 0, len(builtins))
+
+	for _, builtin := range builtins {
+		current, ok := existingByName[builtin.Name]
+		if !ok {
+			role, err := e.CreateRoleV2(ctx, owner, owner, iapl.BuiltinRoleManager, builtin.Name, builtin.Actions)
+
+			switch {
+			case err == nil:
+				e.logger.Infow("created built-in role", "owner", owner.ID, "role", builtin.Name, "role_id", role.ID)
+
+				roles = append(roles, role)
+
+				continue
+			case !errors.Is(err, storage.ErrRoleNameTaken):
+				return nil, fmt.Errorf("creating built-in role %s: %w", builtin.Name, err)
+			}
+
+			// another replica materialized the role after it was listed, so reconcile
+			// the role it created instead.
+			existingByName, err = e.ownedBuiltinRoles(ctx, owner)
+			if err != nil {
+				return nil, err
+			}
+
+			if current, ok = existingByName[builtin.Name]; !ok {
+				return nil, fmt.Errorf("creating built-in role %s: %w", builtin.Name, storage.ErrRoleNameTaken)
+			}
+		}
+
+		roleResource, err := e.NewResourceFromID(current.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		current, err = e.GetRoleV2(ctx, roleResource)
+		if err != nil {
+			return nil, err
+		}
+
+		if sameActions(current.Actions, builtin.Actions) {
+			roles = append(roles, current)
+
+			continue
+		}
+
+		role, err := e.UpdateRoleV2(ctx, owner, roleResource, "", builtin.Actions)
+		if err != nil {
+			return nil, fmt.Errorf("updating built-in role %s: %w", builtin.Name, err)
+		}
+
+		e.logger.Infow("updated built-in role", "owner", owner.ID, "role", builtin.Name, "role_id", role.ID)
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// ownedBuiltinRoles returns the built-in roles owned by the owner itself, keyed by name.
+// Built-in roles available from the owner's ancestors belong to those ancestors.
+func (e *engine) ownedBuiltinRoles(ctx context.Context, owner types.Resource) (map[string]types.Role, error) {
+	existing, _, err := e.ListManagerRolesV2(ctx, iapl.BuiltinRoleManager, owner, RoleFilter{}, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]types.Role, len(existing))
+
+	for _, role := range existing {
+		if role.ResourceID != owner.ID {
+			continue
+		}
+
+		if _, ok := byName[role.Name]; !ok {
+			byName[role.Name] = role
+		}
+	}
+
+	return byName, nil
+}
+
+// MaterializeAllBuiltinRoles materializes the policy's built-in roles for every role owner
+// which is the resource of a relationship in SpiceDB, returning the number of owners.
+func (e *engine) MaterializeAllBuiltinRoles(ctx context.Context) (int, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.MaterializeAllBuiltinRoles")
+	defer span.End()
+
+	var owners int
+
+	for _, ownerType := range e.rbac.RoleOwners {
+		if len(e.rbac.BuiltinRolesForOwner(ownerType)) == 0 {
+			continue
+		}
+
+		ownerIDs, err := e.relationshipResourceIDs(ctx, ownerType)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return owners, err
+		}
+
+		for _, id := range ownerIDs {
+			owner, err := e.NewResourceFromIDString(id)
+			if err != nil {
+				return owners, err
+			}
+
+			if _, err := e.MaterializeBuiltinRoles(ctx, owner); err != nil {
+				return owners, fmt.Errorf("owner %s: %w", id, err)
+			}
+
+			owners++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("owners", owners))
+
+	return owners, nil
+}
+
+// relationshipResourceIDs returns the IDs of the resources of the given type which have
+// at least one relationship, in the order they were first read.
+func (e *engine) relationshipResourceIDs(ctx context.Context, resourceType string) ([]string, error) {
+	rels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+		ResourceType: e.namespaced(resourceType),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(rels))
+	ids := make([]string, 0, len(rels))
+
+	for _, rel := range rels {
+		id := rel.Resource.ObjectId
+
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		seen[id] = struct{}{}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// sameActions reports whether both lists contain the same set of actions.
+func sameActions(a, b []string) bool {
+	set := make(map[string]struct{}, len(a))
+
+	for _, action := range a {
+		set[action] = struct{}{}
+	}
+
+	other := make(map[string]struct{}, len(b))
+
+	for _, action := range b {
+		if _, ok := set[action]; !ok {
+			return false
+		}
+
+		other[action] = struct{}{}
+	}
+
+	return len(set) == len(other)
+}
@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func builtinRolesTestPolicy() iapl.Policy {
+	doc := DefaultPolicyDocumentV2()
+	doc.RBAC.BuiltinRoles = []iapl.BuiltinRole{
+		{
+			Name:       "Viewer",
+			Actions:    []string{"loadbalancer_get", "loadbalancer_list"},
+			RoleOwners: []string{"tenant"},
+		},
+		{
+			Name:       "Admin",
+			Actions:    []string{"loadbalancer_get", "loadbalancer_list", "loadbalancer_update", "loadbalancer_delete"},
+			RoleOwners: []string{"tenant"},
+		},
+	}
+
+	p := iapl.NewPolicy(doc)
+
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestMaterializeBuiltinRoles(t *testing.T) {
+	namespace := "testbuiltinroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, builtinRolesTestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	group, err := e.NewResourceFromIDString("idntgrp-group")
+	require.NoError(t, err)
+
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+
+	err = e.CreateRelationships(ctx, []types.Relationship{
+		{
+			Resource: child,
+			Relation: "parent",
+			Subject:  tenant,
+		},
+	})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[types.Resource, []types.Role]{
+		{
+			Name:  "NotARoleOwner",
+			Input: group,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+		{
+			Name:  "Create",
+			Input: tenant,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+				assert.Equal(t, "Viewer", res.Success[0].Name)
+				assert.Equal(t, iapl.BuiltinRoleManager, res.Success[0].Manager)
+
+				roles, _, err := e.ListManagerRolesV2(ctx, iapl.BuiltinRoleManager, tenant, RoleFilter{}, ListOptions{})
+				require.NoError(t, err)
+				assert.Len(t, roles, 2)
+			},
+		},
+		{
+			Name:  "Idempotent",
+			Input: tenant,
+			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
+				_, err := e.MaterializeBuiltinRoles(ctx, tenant)
+				require.NoError(t, err)
+
+				return ctx
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+
+				roles, _, err := e.ListManagerRolesV2(ctx, iapl.BuiltinRoleManager, tenant, RoleFilter{}, ListOptions{})
+				require.NoError(t, err)
+				assert.Len(t, roles, 2)
+			},
+		},
+		{
+			Name:  "UpdateDrifted",
+			Input: tenant,
+			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
+				roles, err := e.MaterializeBuiltinRoles(ctx, tenant)
+				require.NoError(t, err)
+
+				viewer, err := e.NewResourceFromID(roles[0].ID)
+				require.NoError(t, err)
+
+				_, err = e.UpdateRoleV2(ctx, tenant, viewer, "", []string{"loadbalancer_get"})
+				require.NoError(t, err)
+
+				return ctx
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+				assert.ElementsMatch(t, []string{"loadbalancer_get", "loadbalancer_list"}, res.Success[0].Actions)
+			},
+		},
+		{
+			Name:  "IgnoresAncestorRoles",
+			Input: child,
+			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
+				_, err := e.MaterializeBuiltinRoles(ctx, tenant)
+				require.NoError(t, err)
+
+				return ctx
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+
+				for _, role := range res.Success {
+					assert.Equal(t, child.ID, role.ResourceID)
+				}
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, owner types.Resource) testingx.TestResult[[]types.Role] {
+		roles, err := e.MaterializeBuiltinRoles(ctx, owner)
+
+		return testingx.TestResult[[]types.Role]{Success: roles, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestMaterializeBuiltinRolesConcurrent(t *testing.T) {
+	namespace := "testbuiltinrolesconcurrent"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, builtinRolesTestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	const replicas = 4
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, replicas)
+
+	for i := range replicas {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, errs[i] = e.MaterializeBuiltinRoles(ctx, tenant)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	roles, _, err := e.ListManagerRolesV2(ctx, iapl.BuiltinRoleManager, tenant, RoleFilter{}, ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, roles, 2)
+}
+
+func TestSameActions(t *testing.T) {
+	assert.True(t, sameActions([]string{"a", "b"}, []string{"b", "a"}))
+	assert.True(t, sameActions(nil, []string{}))
+	assert.False(t, sameActions([]string{"a"}, []string{"a", "b"}))
+	assert.False(t, sameActions([]string{"a", "b"}, []string{"a"}))
+	assert.False(t, sameActions([]string{"a", "a"}, []string{"a", "b"}))
+}
@@ -0,0 +1,43 @@
+package query
+
+import (
+	"context"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+type checkDetailsCtxKey struct{}
+
+// CheckDetails describes how SubjectHasPermission evaluated a permission check.
+type CheckDetails struct {
+	// Consistency is the consistency requirement the check was made with.
+	Consistency string
+	// ZedToken is the SpiceDB revision the check was evaluated at. It is empty if the
+	// check was not made, such as for actions which do not exist on the resource.
+	ZedToken string
+}
+
+// ContextWithCheckDetails returns a context with which SubjectHasPermission fills in details.
+func ContextWithCheckDetails(ctx context.Context, details *CheckDetails) context.Context {
+	return context.WithValue(ctx, checkDetailsCtxKey{}, details)
+}
+
+func checkDetailsFromContext(ctx context.Context) *CheckDetails {
+	details, _ := ctx.Value(checkDetailsCtxKey{}).(*CheckDetails)
+
+	return details
+}
+
+// setCheckConsistency records the consistency requirement of the check made with the context.
+func setCheckConsistency(ctx context.Context, consistency string) {
+	if details := checkDetailsFromContext(ctx); details != nil {
+		details.Consistency = consistency
+	}
+}
+
+// setCheckedAt records the revision the check made with the context was evaluated at.
+func setCheckedAt(ctx context.Context, resp *pb.CheckPermissionResponse) {
+	if details := checkDetailsFromContext(ctx); details != nil {
+		details.ZedToken = resp.GetCheckedAt().GetToken()
+	}
+}
@@ -10,6 +10,16 @@ var (
 	// the given request.
 	ErrActionNotAssigned = errors.New("the subject does not have permissions to complete this request")
 
+	// ErrPrivilegeEscalation represents an error condition where an actor grants actions they do not hold themselves.
+	ErrPrivilegeEscalation = fmt.Errorf("%w: actor does not hold every action granted", ErrActionNotAssigned)
+
+	// ErrRoleBindingRequestSelfReview represents an error condition where a subject reviews a role binding request
+	// which they made or which grants them the role.
+	ErrRoleBindingRequestSelfReview = errors.New("role binding requests can not be reviewed by their requester or subjects")
+
+	// ErrAccessRequestReviewForbidden represents an error condition where a subject may not review an access request.
+	ErrAccessRequestReviewForbidden = errors.New("access request can not be reviewed by the subject")
+
 	// ErrInvalidAction represents an error condition where the action provided is not valid for the provided resource.
 	ErrInvalidAction = errors.New("invalid action for resource")
 
@@ -34,6 +44,30 @@ var (
 	// ErrRoleBindingNotFound represents an error when no matching role binding was found
 	ErrRoleBindingNotFound = errors.New("role binding not found")
 
+	// ErrRoleBindingRequestNotFound represents an error when no matching role binding request was found
+	ErrRoleBindingRequestNotFound = errors.New("role binding request not found")
+
+	// ErrRoleBindingRequestNotPending represents an error when a role binding request has already been reviewed
+	ErrRoleBindingRequestNotPending = errors.New("role binding request is not pending")
+
+	// ErrAccessRequestNotFound represents an error when no matching access request was found
+	ErrAccessRequestNotFound = errors.New("access request not found")
+
+	// ErrAccessRequestNotPending represents an error when an access request has already been reviewed
+	ErrAccessRequestNotPending = errors.New("access request is not pending")
+
+	// ErrBreakGlassNotConfigured represents an error when break-glass access is requested but no emergency role is configured
+	ErrBreakGlassNotConfigured = errors.New("break-glass access is not configured")
+
+	// ErrAccessReviewNotFound represents an error when no matching access review was found
+	ErrAccessReviewNotFound = errors.New("access review not found")
+
+	// ErrAccessReviewItemNotFound represents an error when no matching access review item was found
+	ErrAccessReviewItemNotFound = errors.New("access review item not found")
+
+	// ErrAccessReviewClosed represents an error when an access review has already been closed
+	ErrAccessReviewClosed = errors.New("access review is closed")
+
 	// ErrRoleHasTooManyResources represents an error which a role has too many resources
 	ErrRoleHasTooManyResources = errors.New("role has too many resources")
 
@@ -47,6 +81,15 @@ var (
 	// ErrDeleteRoleInUse represents an error when a role is in use and cannot be deleted
 	ErrDeleteRoleInUse = fmt.Errorf("%w: role is in use", ErrInvalidArgument)
 
+	// ErrRoleIncludeCycle represents an error when a role would include itself, directly or through other roles
+	ErrRoleIncludeCycle = fmt.Errorf("%w: role include cycle", ErrInvalidArgument)
+
+	// ErrRoleBindingApprovalRequired represents an error when a role binding must be requested and approved instead
+	ErrRoleBindingApprovalRequired = fmt.Errorf("%w: role binding requires approval", ErrInvalidArgument)
+
+	// ErrSeparationOfDutiesViolation represents an error when a role-binding would give a subject mutually exclusive actions
+	ErrSeparationOfDutiesViolation = fmt.Errorf("%w: separation of duties violation", ErrInvalidArgument)
+
 	// ErrRoleAlreadyExists represents an error when a role already exists
 	ErrRoleAlreadyExists = fmt.Errorf("%w: role already exists", ErrInvalidArgument)
 
@@ -57,6 +100,29 @@ var (
 	// request attempts to use a resource that does not support role binding v2
 	ErrResourceDoesNotSupportRoleBindingV2 = fmt.Errorf("%w: resource does not support role binding v2", ErrInvalidArgument)
 
+	// ErrIdempotencyKeyReused represents an error when an idempotency key is reused for a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different parameters")
+
+	// ErrPreconditionFailed represents an error when a role or role binding has changed since the version
+	// a conditional update or delete was made against
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrTooManyResources represents an error when an access review or unused access report
+	// would cover too many resources
+	ErrTooManyResources = fmt.Errorf("%w: too many resources", ErrInvalidArgument)
+
+	// ErrInvalidUnusedPeriod represents an error when unused access is listed for a non-positive period
+	ErrInvalidUnusedPeriod = fmt.Errorf("%w: unused period must be positive", ErrInvalidArgument)
+
+	// ErrInvalidAccessReviewDecision represents an error when an access review decision is unknown
+	ErrInvalidAccessReviewDecision = fmt.Errorf("%w: invalid access review decision", ErrInvalidArgument)
+
+	// ErrBreakGlassJustificationRequired represents an error when break-glass access is requested without a justification
+	ErrBreakGlassJustificationRequired = fmt.Errorf("%w: break-glass access requires a justification", ErrInvalidArgument)
+
+	// ErrInvalidCursor represents an error when a list cursor is malformed
+	ErrInvalidCursor = fmt.Errorf("%w: invalid cursor", ErrInvalidArgument)
+
 	// ErrRoleBindingHasNoRelationships represents an internal error when a
 	// role binding has no relationships
 	ErrRoleBindingHasNoRelationships = errors.New("role binding has no relationships")
@@ -0,0 +1,124 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+type escalationGuardCtxKey struct{}
+
+// contextWithoutEscalationGuard disables the privilege escalation guard for changes made
+// by the engine itself, such as materializing built-in roles.
+func contextWithoutEscalationGuard(ctx context.Context) context.Context {
+	return context.WithValue(ctx, escalationGuardCtxKey{}, false)
+}
+
+// escalationGuardEnabled reports whether changes made with the context are guarded.
+func (e *engine) escalationGuardEnabled(ctx context.Context) bool {
+	if enabled, ok := ctx.Value(escalationGuardCtxKey{}).(bool); ok {
+		return e.escalationGuard && enabled
+	}
+
+	return e.escalationGuard
+}
+
+// checkPrivilegeEscalation ensures the actor can perform each of the actions on the resource,
+// so that the actor can not grant more than they hold. Actions which the resource type does
+// not define are ignored, and all the actions are checked with a single bulk check.
+func (e *engine) checkPrivilegeEscalation(ctx context.Context, actor, resource types.Resource, actions []string) error {
+	if !e.escalationGuardEnabled(ctx) || len(actions) == 0 {
+		return nil
+	}
+
+	held, err := e.EffectiveActions(ctx, actor, resource)
+	if err != nil {
+		return err
+	}
+
+	heldSet := make(map[string]struct{}, len(held))
+
+	for _, action := range held {
+		heldSet[action] = struct{}{}
+	}
+
+	var missing []string
+
+	for _, action := range actions {
+		if e.validateResourceActions(resource, action) != nil {
+			continue
+		}
+
+		if _, ok := heldSet[action]; !ok {
+			missing = append(missing, action)
+		}
+	}
+
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: %s on %s", ErrPrivilegeEscalation, strings.Join(missing, ", "), resource.ID)
+	}
+
+	return nil
+}
+
+// checkRoleV2Escalation ensures the actor holds the actions being added to the role on the
+// role's owner.
+func (e *engine) checkRoleV2Escalation(ctx context.Context, actor types.Resource, role types.Role, actions []string) error {
+	if !e.escalationGuardEnabled(ctx) || len(actions) == 0 {
+		return nil
+	}
+
+	owner, err := e.NewResourceFromID(role.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	return e.checkPrivilegeEscalation(ctx, actor, owner, actions)
+}
+
+// roleV2EffectiveActions returns the actions of the given V2 roles along with the actions
+// of the roles they include, directly or transitively.
+func (e *engine) roleV2EffectiveActions(ctx context.Context, roleIDs ...gidx.PrefixedID) ([]string, error) {
+	var actions []string
+
+	visited := make(map[gidx.PrefixedID]struct{}, len(roleIDs))
+	seen := make(map[string]struct{})
+	queue := append([]gidx.PrefixedID{}, roleIDs...)
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if _, ok := visited[id]; ok {
+			continue
+		}
+
+		visited[id] = struct{}{}
+
+		roleActions, err := e.listRoleV2Actions(ctx, types.Role{ID: id})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, action := range roleActions {
+			if _, ok := seen[action]; !ok {
+				seen[action] = struct{}{}
+
+				actions = append(actions, action)
+			}
+		}
+
+		includes, err := e.listRoleV2Includes(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		queue = append(queue, includes...)
+	}
+
+	return actions, nil
+}
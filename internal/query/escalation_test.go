@@ -0,0 +1,232 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestPrivilegeEscalationGuard(t *testing.T) {
+	namespace := "testescalationguard"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+	e.escalationGuard = true
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	// the setup is done by the engine itself, which is not guarded.
+	setupCtx := contextWithoutEscalationGuard(ctx)
+
+	newRole := func(name string, actions ...string) types.Resource {
+		role, err := e.CreateRoleV2(setupCtx, actor, root, "", name, actions)
+		require.NoError(t, err)
+
+		res, err := e.NewResourceFromID(role.ID)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	viewer := newRole("viewer", "role_get")
+	editor := newRole("editor", "role_get", "role_update")
+	admin := newRole("admin", "role_delete")
+	lister := newRole("lister", "role_list")
+	lead := newRole("lead", "role_get")
+	auditor := newRole("auditor", "role_get")
+
+	// the actor holds role_list only through the role included by lead, and auditor
+	// grants role_delete only through the included admin role.
+	_, err = e.UpdateRoleV2Includes(setupCtx, actor, lead, []types.Resource{lister})
+	require.NoError(t, err)
+
+	_, err = e.UpdateRoleV2Includes(setupCtx, actor, auditor, []types.Resource{admin})
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(setupCtx, actor, root, editor, "", []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(setupCtx, actor, root, lead, "", []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	t.Run("CreateRoleBinding", func(t *testing.T) {
+		tc := []testingx.TestCase[types.Resource, types.RoleBinding]{
+			{
+				Name:  "HeldActions",
+				Input: viewer,
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.NoError(t, res.Err)
+				},
+			},
+			{
+				Name:  "MissingActions",
+				Input: admin,
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.ErrorIs(t, res.Err, ErrPrivilegeEscalation)
+					assert.ErrorContains(t, res.Err, "role_delete")
+				},
+			},
+			{
+				Name:  "ActionsHeldThroughIncludes",
+				Input: lister,
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.NoError(t, res.Err)
+				},
+			},
+			{
+				Name:  "IncludedMissingActions",
+				Input: auditor,
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.ErrorIs(t, res.Err, ErrPrivilegeEscalation)
+					assert.ErrorContains(t, res.Err, "role_delete")
+				},
+			},
+		}
+
+		testFn := func(ctx context.Context, role types.Resource) testingx.TestResult[types.RoleBinding] {
+			rb, err := e.CreateRoleBinding(ctx, actor, root, role, "", []types.RoleBindingSubject{{SubjectResource: user}})
+
+			return testingx.TestResult[types.RoleBinding]{Success: rb, Err: err}
+		}
+
+		testingx.RunTests(ctx, t, tc, testFn)
+	})
+
+	t.Run("UpdateRoleBinding", func(t *testing.T) {
+		// a binding of the admin role made by the engine, which the actor may update
+		adminRB, err := e.CreateRoleBinding(setupCtx, actor, root, admin, "", []types.RoleBindingSubject{{SubjectResource: user}})
+		require.NoError(t, err)
+
+		adminRBRes, err := e.NewResourceFromID(adminRB.ID)
+		require.NoError(t, err)
+
+		// removing subjects grants nothing
+		_, err = e.UpdateRoleBinding(ctx, actor, adminRBRes, []types.RoleBindingSubject{})
+		require.NoError(t, err)
+
+		// adding the actor itself grants role_delete, which the actor does not hold
+		_, err = e.UpdateRoleBinding(ctx, actor, adminRBRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+		assert.ErrorIs(t, err, ErrPrivilegeEscalation)
+
+		rb, err := e.GetRoleBinding(ctx, adminRBRes)
+		require.NoError(t, err)
+		assert.Empty(t, rb.SubjectIDs)
+
+		// auditor grants role_delete through the admin role it includes
+		auditorRB, err := e.CreateRoleBinding(setupCtx, actor, root, auditor, "", []types.RoleBindingSubject{{SubjectResource: user}})
+		require.NoError(t, err)
+
+		auditorRBRes, err := e.NewResourceFromID(auditorRB.ID)
+		require.NoError(t, err)
+
+		_, err = e.UpdateRoleBinding(ctx, actor, auditorRBRes, []types.RoleBindingSubject{{SubjectResource: user}, {SubjectResource: actor}})
+		assert.ErrorIs(t, err, ErrPrivilegeEscalation)
+	})
+
+	t.Run("UpdateRoleV2", func(t *testing.T) {
+		tc := []testingx.TestCase[[]string, types.Role]{
+			{
+				Name:  "HeldActions",
+				Input: []string{"role_get", "role_update"},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+					require.NoError(t, res.Err)
+					assert.ElementsMatch(t, []string{"role_get", "role_update"}, res.Success.Actions)
+				},
+				Sync: true,
+			},
+			{
+				Name:  "MissingActions",
+				Input: []string{"role_get", "role_delete"},
+				CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+					assert.ErrorIs(t, res.Err, ErrPrivilegeEscalation)
+
+					role, err := e.GetRoleV2(ctx, viewer)
+					require.NoError(t, err)
+					assert.NotContains(t, role.Actions, "role_delete")
+				},
+				Sync: true,
+			},
+			{
+				Name:  "ActionsHeldThroughIncludes",
+				Input: []string{"role_get", "role_list"},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+					require.NoError(t, res.Err)
+					assert.ElementsMatch(t, []string{"role_get", "role_list"}, res.Success.Actions)
+				},
+				Sync: true,
+			},
+			{
+				Name:  "RemoveActions",
+				Input: []string{"role_get"},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+					assert.NoError(t, res.Err)
+				},
+				Sync: true,
+			},
+		}
+
+		testFn := func(ctx context.Context, actions []string) testingx.TestResult[types.Role] {
+			role, err := e.UpdateRoleV2(ctx, actor, viewer, "", actions)
+
+			return testingx.TestResult[types.Role]{Success: role, Err: err}
+		}
+
+		testingx.RunTests(ctx, t, tc, testFn)
+	})
+
+	t.Run("UpdateRoleV2Includes", func(t *testing.T) {
+		_, err := e.UpdateRoleV2Includes(ctx, actor, viewer, []types.Resource{admin})
+		assert.ErrorIs(t, err, ErrPrivilegeEscalation)
+	})
+
+	t.Run("UpdateRoleV2IncludesHeld", func(t *testing.T) {
+		_, err := e.UpdateRoleV2Includes(ctx, actor, viewer, []types.Resource{lister})
+		assert.NoError(t, err)
+	})
+}
+
+func TestPrivilegeEscalationGuardDisabled(t *testing.T) {
+	namespace := "testescalationguarddisabled"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+	e.escalationGuard = false
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	// the actor holds no roles at all
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, root, "", "admin", []string{"role_delete"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	_, err = e.UpdateRoleV2(ctx, actor, roleRes, "", []string{"role_delete", "role_update"})
+	require.NoError(t, err)
+
+	rb, err := e.CreateRoleBinding(ctx, actor, root, roleRes, "", []types.RoleBindingSubject{{SubjectResource: user}})
+	require.NoError(t, err)
+
+	rbRes, err := e.NewResourceFromID(rb.ID)
+	require.NoError(t, err)
+
+	_, err = e.UpdateRoleBinding(ctx, actor, rbRes, []types.RoleBindingSubject{{SubjectResource: user}, {SubjectResource: actor}})
+	require.NoError(t, err)
+}
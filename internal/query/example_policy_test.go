@@ -57,6 +57,9 @@ func TestExamplePolicy(t *testing.T) {
 
 	// all actions
 	allactions := []string{
+		"iam_relationship_list",
+		"iam_rolebinding_approve",
+		"iam_rolebinding_breakglass",
 		"iam_rolebinding_create",
 		"iam_rolebinding_delete",
 		"iam_rolebinding_get",
@@ -75,6 +78,9 @@ func TestExamplePolicy(t *testing.T) {
 	}
 
 	iamactions := []string{
+		"iam_relationship_list",
+		"iam_rolebinding_approve",
+		"iam_rolebinding_breakglass",
 		"iam_rolebinding_create",
 		"iam_rolebinding_delete",
 		"iam_rolebinding_get",
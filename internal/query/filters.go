@@ -0,0 +1,79 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+// RoleFilter restricts the roles returned by role listings.
+// Zero value fields are not filtered on.
+type RoleFilter struct {
+	// NamePrefix matches roles whose name starts with the prefix.
+	NamePrefix string
+	// Action matches roles which allow the action.
+	Action string
+	// CreatedBy matches roles created by the subject.
+	CreatedBy gidx.PrefixedID
+
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+func (f RoleFilter) storageFilter(optionalManager *string) storage.RoleFilter {
+	return storage.RoleFilter{
+		TimeFilter: storage.TimeFilter{
+			CreatedAfter:  f.CreatedAfter,
+			CreatedBefore: f.CreatedBefore,
+			UpdatedAfter:  f.UpdatedAfter,
+			UpdatedBefore: f.UpdatedBefore,
+		},
+		NamePrefix: f.NamePrefix,
+		Manager:    optionalManager,
+		CreatedBy:  f.CreatedBy,
+	}
+}
+
+// RoleBindingFilter restricts the role-bindings returned by role-binding listings.
+// Zero value fields are not filtered on.
+type RoleBindingFilter struct {
+	// SubjectID matches role-bindings which include the subject.
+	SubjectID gidx.PrefixedID
+	// Action matches role-bindings whose role allows the action.
+	Action string
+	// CreatedBy matches role-bindings created by the subject.
+	CreatedBy gidx.PrefixedID
+
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+func (f RoleBindingFilter) storageFilter(optionalManager *string) storage.RoleBindingFilter {
+	return storage.RoleBindingFilter{
+		TimeFilter: storage.TimeFilter{
+			CreatedAfter:  f.CreatedAfter,
+			CreatedBefore: f.CreatedBefore,
+			UpdatedAfter:  f.UpdatedAfter,
+			UpdatedBefore: f.UpdatedBefore,
+		},
+		Manager:   optionalManager,
+		CreatedBy: f.CreatedBy,
+	}
+}
+
+// validateFilterAction ensures the filtered action is a role action known to the policy.
+func (e *engine) validateFilterAction(action string) error {
+	if action == "" || slices.Contains(e.AllActions(), action) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidAction, action)
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+type idempotencyKeyCtxKey struct{}
+
+// ContextWithIdempotencyKey returns a copy of ctx which makes CreateRoleV2, CreateRoleBinding and
+// CreateRoleBindingRequest idempotent. Repeating a create with the same actor and key returns the role,
+// role-binding or role-binding request created by the first call, rather than creating another. Reusing
+// a key for a different role, role-binding or request returns ErrIdempotencyKeyReused.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+
+	return key
+}
+
+// idempotentRoleV2 returns the role previously created by the actor with the idempotency key in ctx.
+// The returned bool is false when there is no key or no role was created with it.
+func (e *engine) idempotentRoleV2(ctx context.Context, actor, owner types.Resource, roleName string) (types.Role, bool, error) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" {
+		return types.Role{}, false, nil
+	}
+
+	dbRole, err := e.store.GetRoleByIdempotencyKey(ctx, actor.ID, key)
+
+	switch {
+	case errors.Is(err, storage.ErrNoRoleFound):
+		return types.Role{}, false, nil
+	case err != nil:
+		return types.Role{}, false, err
+	case dbRole.ResourceID != owner.ID || dbRole.Name != roleName:
+		return types.Role{}, false, fmt.Errorf("%w: role %s", ErrIdempotencyKeyReused, dbRole.ID)
+	}
+
+	roleResource, err := e.NewResourceFromID(dbRole.ID)
+	if err != nil {
+		return types.Role{}, false, err
+	}
+
+	role, err := e.GetRoleV2(ctx, roleResource)
+	if err != nil {
+		return types.Role{}, false, err
+	}
+
+	return role, true, nil
+}
+
+// idempotentRoleBinding returns the role-binding previously created by the actor with the idempotency key in ctx.
+// The returned bool is false when there is no key or no role-binding was created with it.
+func (e *engine) idempotentRoleBinding(ctx context.Context, actor, resource, roleResource types.Resource) (types.RoleBinding, bool, error) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" {
+		return types.RoleBinding{}, false, nil
+	}
+
+	rb, err := e.store.GetRoleBindingByIdempotencyKey(ctx, actor.ID, key)
+
+	switch {
+	case errors.Is(err, storage.ErrRoleBindingNotFound):
+		return types.RoleBinding{}, false, nil
+	case err != nil:
+		return types.RoleBinding{}, false, err
+	}
+
+	rb, err = e.loadRoleBindingRelationships(ctx, rb)
+	if err != nil {
+		return types.RoleBinding{}, false, err
+	}
+
+	if rb.ResourceID != resource.ID || rb.RoleID != roleResource.ID {
+		return types.RoleBinding{}, false, fmt.Errorf("%w: role-binding %s", ErrIdempotencyKeyReused, rb.ID)
+	}
+
+	return rb, true, nil
+}
+
+// idempotentRoleBindingRequest returns the role-binding request previously made by the actor with the idempotency
+// key in ctx. The returned bool is false when there is no key or no request was made with it.
+func (e *engine) idempotentRoleBindingRequest(
+	ctx context.Context, actor, resource, roleResource types.Resource,
+) (types.RoleBindingRequest, bool, error) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" {
+		return types.RoleBindingRequest{}, false, nil
+	}
+
+	req, err := e.store.GetRoleBindingRequestByIdempotencyKey(ctx, actor.ID, key)
+
+	switch {
+	case errors.Is(err, storage.ErrRoleBindingRequestNotFound):
+		return types.RoleBindingRequest{}, false, nil
+	case err != nil:
+		return types.RoleBindingRequest{}, false, err
+	case req.ResourceID != resource.ID || req.RoleID != roleResource.ID:
+		return types.RoleBindingRequest{}, false, fmt.Errorf("%w: role-binding request %s", ErrIdempotencyKeyReused, req.ID)
+	}
+
+	return req, true, nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestCreateRoleV2Idempotent(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	keyCtx := ContextWithIdempotencyKey(ctx, t.Name())
+
+	role, err := e.CreateRoleV2(keyCtx, actor, tenant, t.Name(), "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	retried, err := e.CreateRoleV2(keyCtx, actor, tenant, t.Name(), "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	assert.Equal(t, role.ID, retried.ID)
+	assert.Equal(t, []string{"loadbalancer_get"}, retried.Actions)
+
+	_, err = e.CreateRoleV2(keyCtx, actor, tenant, t.Name(), "lb_editor", []string{"loadbalancer_update"})
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	roles, _, err := e.ListRolesV2(ctx, tenant, RoleFilter{}, ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, roles, 1)
+}
+
+func TestCreateRoleBindingIdempotent(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, tenant, t.Name(), "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	editor, err := e.CreateRoleV2(ctx, actor, tenant, t.Name(), "lb_editor", []string{"loadbalancer_update"})
+	require.NoError(t, err)
+	editorRes, err := e.NewResourceFromID(editor.ID)
+	require.NoError(t, err)
+
+	subjects := []types.RoleBindingSubject{{SubjectResource: subj}}
+	keyCtx := ContextWithIdempotencyKey(ctx, t.Name())
+
+	rb, err := e.CreateRoleBinding(keyCtx, actor, tenant, viewerRes, t.Name(), subjects)
+	require.NoError(t, err)
+
+	retried, err := e.CreateRoleBinding(keyCtx, actor, tenant, viewerRes, t.Name(), subjects)
+	require.NoError(t, err)
+	assert.Equal(t, rb.ID, retried.ID)
+	assert.Equal(t, viewer.ID, retried.RoleID)
+	assert.Equal(t, rb.SubjectIDs, retried.SubjectIDs)
+
+	_, err = e.CreateRoleBinding(keyCtx, actor, tenant, editorRes, t.Name(), subjects)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	rbs, _, err := e.ListRoleBindings(ctx, tenant, nil, RoleBindingFilter{}, ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, rbs, 1)
+}
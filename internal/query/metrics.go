@@ -0,0 +1,46 @@
+package query
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	outcomeError = "error"
+
+	// invalidActionLabel is the action label of checks of actions which do not exist on the resource.
+	invalidActionLabel = "invalid"
+)
+
+var (
+	checksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Name:      "checks_total",
+		Help:      "Permission checks by outcome, action and resource type.",
+	}, []string{"outcome", "action", "resource_type"})
+
+	checkDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "permissions_api",
+		Name:      "check_duration_seconds",
+		Help:      "Latency of permission checks by outcome and resource type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome", "resource_type"})
+
+	consistencyTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Name:      "consistency_total",
+		Help:      "SpiceDB requests by the consistency requirement they were made with.",
+	}, []string{"consistency"})
+
+	zedTokenLookupErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Name:      "zedtoken_lookup_errors_total",
+		Help:      "Errors looking up the ZedTokens of resources, falling back to minimize_latency consistency.",
+	})
+
+	rollbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Name:      "relationship_rollbacks_total",
+		Help:      "Rollbacks of SpiceDB relationship updates by result.",
+	}, []string{"result"})
+)
@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
@@ -65,15 +66,34 @@ func (e *Engine) CreateRoleV2(context.Context, types.Resource, types.Resource, s
 }
 
 // ListRolesV2 list roles
-func (e *Engine) ListRolesV2(context.Context, types.Resource) ([]types.Role, error) {
-	return nil, nil
+func (e *Engine) ListRolesV2(context.Context, types.Resource, query.RoleFilter, query.ListOptions) ([]types.Role, string, error) {
+	return nil, "", nil
 }
 
 // ListManagerRolesV2 list roles
-func (e *Engine) ListManagerRolesV2(context.Context, string, types.Resource) ([]types.Role, error) {
+func (e *Engine) ListManagerRolesV2(context.Context, string, types.Resource, query.RoleFilter, query.ListOptions) ([]types.Role, string, error) {
+	return nil, "", nil
+}
+
+// UpdateRoleV2Includes returns the provided mock results.
+func (e *Engine) UpdateRoleV2Includes(context.Context, types.Resource, types.Resource, []types.Resource) (types.Role, error) {
+	args := e.Called()
+
+	retRole := args.Get(0).(types.Role)
+
+	return retRole, args.Error(1)
+}
+
+// MaterializeBuiltinRoles does nothing but satisfies the Engine interface.
+func (e *Engine) MaterializeBuiltinRoles(context.Context, types.Resource) ([]types.Role, error) {
 	return nil, nil
 }
 
+// MaterializeAllBuiltinRoles does nothing but satisfies the Engine interface.
+func (e *Engine) MaterializeAllBuiltinRoles(context.Context) (int, error) {
+	return 0, nil
+}
+
 // UpdateRole returns the provided mock results.
 func (e *Engine) UpdateRole(context.Context, types.Resource, types.Resource, string, []string) (types.Role, error) {
 	args := e.Called()
@@ -112,32 +132,40 @@ func (e *Engine) GetRoleResource(context.Context, types.Resource) (types.Resourc
 }
 
 // ListAssignments returns nothing but satisfies the Engine interface.
-func (e *Engine) ListAssignments(context.Context, types.Role) ([]types.Resource, error) {
+func (e *Engine) ListAssignments(context.Context, types.Role, query.ListOptions) ([]types.Resource, string, error) {
 	args := e.Called()
 
 	ret := args.Get(0).([]types.Resource)
 
-	return ret, args.Error(1)
+	return ret, args.String(1), args.Error(2)
 }
 
-// ListRelationshipsFrom returns nothing but satisfies the Engine interface.
-func (e *Engine) ListRelationshipsFrom(context.Context, types.Resource) ([]types.Relationship, error) {
-	return nil, nil
+// ListRelationshipsFrom returns the provided mock results.
+func (e *Engine) ListRelationshipsFrom(context.Context, types.Resource, query.ListOptions) ([]types.Relationship, string, error) {
+	args := e.Called()
+
+	retRels := args.Get(0).([]types.Relationship)
+
+	return retRels, args.String(1), args.Error(2)
 }
 
-// ListRelationshipsTo returns nothing but satisfies the Engine interface.
-func (e *Engine) ListRelationshipsTo(context.Context, types.Resource) ([]types.Relationship, error) {
-	return nil, nil
+// ListRelationshipsTo returns the provided mock results.
+func (e *Engine) ListRelationshipsTo(context.Context, types.Resource, query.ListOptions) ([]types.Relationship, string, error) {
+	args := e.Called()
+
+	retRels := args.Get(0).([]types.Relationship)
+
+	return retRels, args.String(1), args.Error(2)
 }
 
 // ListRoles returns nothing but satisfies the Engine interface.
-func (e *Engine) ListRoles(context.Context, types.Resource) ([]types.Role, error) {
-	return nil, nil
+func (e *Engine) ListRoles(context.Context, types.Resource, query.ListOptions) ([]types.Role, string, error) {
+	return nil, "", nil
 }
 
 // ListManagerRoles returns nothing but satisfies the Engine interface.
-func (e *Engine) ListManagerRoles(context.Context, string, types.Resource) ([]types.Role, error) {
-	return nil, nil
+func (e *Engine) ListManagerRoles(context.Context, string, types.Resource, query.ListOptions) ([]types.Role, string, error) {
+	return nil, "", nil
 }
 
 // DeleteRelationships does nothing but satisfies the Engine interface.
@@ -211,11 +239,20 @@ func (e *Engine) GetResourceType(name string) *types.ResourceType {
 	return nil
 }
 
-// SubjectHasPermission returns nil to satisfy the Engine interface.
+// EffectiveActions returns the provided mock results.
+func (e *Engine) EffectiveActions(context.Context, types.Resource, types.Resource) ([]string, error) {
+	args := e.Called()
+
+	retActions := args.Get(0).([]string)
+
+	return retActions, args.Error(1)
+}
+
+// SubjectHasPermission returns the provided mock results.
 func (e *Engine) SubjectHasPermission(context.Context, types.Resource, string, types.Resource) error {
-	e.Called()
+	args := e.Called()
 
-	return nil
+	return args.Error(0)
 }
 
 // CreateRoleBinding returns nothing but satisfies the Engine interface.
@@ -224,13 +261,33 @@ func (e *Engine) CreateRoleBinding(context.Context, types.Resource, types.Resour
 }
 
 // ListRoleBindings returns nothing but satisfies the Engine interface.
-func (e *Engine) ListRoleBindings(context.Context, types.Resource, *types.Resource) ([]types.RoleBinding, error) {
-	return nil, nil
+func (e *Engine) ListRoleBindings(context.Context, types.Resource, *types.Resource, query.RoleBindingFilter, query.ListOptions) ([]types.RoleBinding, string, error) {
+	return nil, "", nil
 }
 
 // ListManagerRoleBindings returns nothing but satisfies the Engine interface.
-func (e *Engine) ListManagerRoleBindings(context.Context, string, types.Resource, *types.Resource) ([]types.RoleBinding, error) {
-	return nil, nil
+func (e *Engine) ListManagerRoleBindings(
+	context.Context, string, types.Resource, *types.Resource, query.RoleBindingFilter, query.ListOptions,
+) ([]types.RoleBinding, string, error) {
+	return nil, "", nil
+}
+
+// ListSubjectRoleBindings returns the provided mock results.
+func (e *Engine) ListSubjectRoleBindings(context.Context, types.Resource, query.ListOptions) ([]types.SubjectRoleBinding, string, error) {
+	args := e.Called()
+
+	retRbs := args.Get(0).([]types.SubjectRoleBinding)
+
+	return retRbs, args.String(1), args.Error(2)
+}
+
+// BatchRoleBindings returns the provided mock results.
+func (e *Engine) BatchRoleBindings(context.Context, types.Resource, []query.RoleBindingOperation) ([]types.RoleBinding, error) {
+	args := e.Called()
+
+	retRbs := args.Get(0).([]types.RoleBinding)
+
+	return retRbs, args.Error(1)
 }
 
 // GetRoleBinding returns nothing but satisfies the Engine interface.
@@ -253,6 +310,228 @@ func (e *Engine) GetRoleBindingResource(context.Context, types.Resource) (types.
 	return types.Resource{}, nil
 }
 
+// ListSeparationOfDutiesViolations returns the provided mock results.
+func (e *Engine) ListSeparationOfDutiesViolations(context.Context, types.Resource) ([]types.SeparationOfDutiesViolation, error) {
+	args := e.Called()
+
+	retViolations := args.Get(0).([]types.SeparationOfDutiesViolation)
+
+	return retViolations, args.Error(1)
+}
+
+// FlushRoleBindingUsage returns the provided mock results.
+func (e *Engine) FlushRoleBindingUsage(context.Context) (int, error) {
+	args := e.Called()
+
+	return args.Int(0), args.Error(1)
+}
+
+// ListUnusedAccess returns the provided mock results.
+func (e *Engine) ListUnusedAccess(context.Context, types.Resource, time.Duration) ([]types.UnusedAccess, error) {
+	args := e.Called()
+
+	retUnused := args.Get(0).([]types.UnusedAccess)
+
+	return retUnused, args.Error(1)
+}
+
+// RoleBindingUsageSampleRate returns the provided mock results.
+func (e *Engine) RoleBindingUsageSampleRate() float64 {
+	args := e.Called()
+
+	return args.Get(0).(float64)
+}
+
+// RoleBindingRequiresApproval returns the provided mock results.
+func (e *Engine) RoleBindingRequiresApproval(context.Context, types.Resource) (bool, error) {
+	args := e.Called()
+
+	return args.Bool(0), args.Error(1)
+}
+
+// CreateRoleBindingRequest returns the provided mock results.
+func (e *Engine) CreateRoleBindingRequest(
+	context.Context, types.Resource, types.Resource, types.Resource, string, []types.RoleBindingSubject,
+) (types.RoleBindingRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.RoleBindingRequest)
+
+	return retReq, args.Error(1)
+}
+
+// GetRoleBindingRequest returns the provided mock results.
+func (e *Engine) GetRoleBindingRequest(context.Context, gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.RoleBindingRequest)
+
+	return retReq, args.Error(1)
+}
+
+// ListRoleBindingRequests returns the provided mock results.
+func (e *Engine) ListRoleBindingRequests(
+	context.Context, types.Resource, types.RoleBindingRequestStatus, query.ListOptions,
+) ([]types.RoleBindingRequest, string, error) {
+	args := e.Called()
+
+	retReqs := args.Get(0).([]types.RoleBindingRequest)
+
+	return retReqs, args.String(1), args.Error(2)
+}
+
+// ApproveRoleBindingRequest returns the provided mock results.
+func (e *Engine) ApproveRoleBindingRequest(context.Context, types.Resource, gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.RoleBindingRequest)
+
+	return retReq, args.Error(1)
+}
+
+// RejectRoleBindingRequest returns the provided mock results.
+func (e *Engine) RejectRoleBindingRequest(context.Context, types.Resource, gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.RoleBindingRequest)
+
+	return retReq, args.Error(1)
+}
+
+// CreateAccessRequest returns the provided mock results.
+func (e *Engine) CreateAccessRequest(
+	context.Context, types.Resource, types.Resource, types.Resource, string, time.Duration,
+) (types.AccessRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.AccessRequest)
+
+	return retReq, args.Error(1)
+}
+
+// GetAccessRequest returns the provided mock results.
+func (e *Engine) GetAccessRequest(context.Context, gidx.PrefixedID) (types.AccessRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.AccessRequest)
+
+	return retReq, args.Error(1)
+}
+
+// ListAccessRequests returns the provided mock results.
+func (e *Engine) ListAccessRequests(
+	context.Context, types.Resource, types.AccessRequestStatus, query.ListOptions,
+) ([]types.AccessRequest, string, error) {
+	args := e.Called()
+
+	retReqs := args.Get(0).([]types.AccessRequest)
+
+	return retReqs, args.String(1), args.Error(2)
+}
+
+// ListSubjectAccessRequests returns the provided mock results.
+func (e *Engine) ListSubjectAccessRequests(
+	context.Context, types.Resource, types.AccessRequestStatus, query.ListOptions,
+) ([]types.AccessRequest, string, error) {
+	args := e.Called()
+
+	retReqs := args.Get(0).([]types.AccessRequest)
+
+	return retReqs, args.String(1), args.Error(2)
+}
+
+// ApproveAccessRequest returns the provided mock results.
+func (e *Engine) ApproveAccessRequest(context.Context, types.Resource, gidx.PrefixedID, time.Duration) (types.AccessRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.AccessRequest)
+
+	return retReq, args.Error(1)
+}
+
+// DenyAccessRequest returns the provided mock results.
+func (e *Engine) DenyAccessRequest(context.Context, types.Resource, gidx.PrefixedID) (types.AccessRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.AccessRequest)
+
+	return retReq, args.Error(1)
+}
+
+// ExpireAccessRequests returns nothing but satisfies the Engine interface.
+func (e *Engine) ExpireAccessRequests(context.Context, int) (int, error) {
+	return 0, nil
+}
+
+// BreakGlass returns the provided mock results.
+func (e *Engine) BreakGlass(context.Context, types.Resource, types.Resource, string) (types.AccessRequest, error) {
+	args := e.Called()
+
+	retReq := args.Get(0).(types.AccessRequest)
+
+	return retReq, args.Error(1)
+}
+
+// CreateAccessReview returns the provided mock results.
+func (e *Engine) CreateAccessReview(context.Context, types.Resource, types.Resource, string) (types.AccessReview, error) {
+	args := e.Called()
+
+	retReview := args.Get(0).(types.AccessReview)
+
+	return retReview, args.Error(1)
+}
+
+// GetAccessReview returns the provided mock results.
+func (e *Engine) GetAccessReview(context.Context, gidx.PrefixedID) (types.AccessReview, error) {
+	args := e.Called()
+
+	retReview := args.Get(0).(types.AccessReview)
+
+	return retReview, args.Error(1)
+}
+
+// ListAccessReviews returns the provided mock results.
+func (e *Engine) ListAccessReviews(
+	context.Context, types.Resource, types.AccessReviewStatus, query.ListOptions,
+) ([]types.AccessReview, string, error) {
+	args := e.Called()
+
+	retReviews := args.Get(0).([]types.AccessReview)
+
+	return retReviews, args.String(1), args.Error(2)
+}
+
+// ListAccessReviewItems returns the provided mock results.
+func (e *Engine) ListAccessReviewItems(
+	context.Context, gidx.PrefixedID, types.AccessReviewDecision, query.ListOptions,
+) ([]types.AccessReviewItem, string, error) {
+	args := e.Called()
+
+	retItems := args.Get(0).([]types.AccessReviewItem)
+
+	return retItems, args.String(1), args.Error(2)
+}
+
+// DecideAccessReviewItem returns the provided mock results.
+func (e *Engine) DecideAccessReviewItem(
+	context.Context, types.Resource, gidx.PrefixedID, gidx.PrefixedID, types.AccessReviewDecision,
+) (types.AccessReviewItem, error) {
+	args := e.Called()
+
+	retItem := args.Get(0).(types.AccessReviewItem)
+
+	return retItem, args.Error(1)
+}
+
+// CloseAccessReview returns the provided mock results.
+func (e *Engine) CloseAccessReview(context.Context, types.Resource, gidx.PrefixedID) (types.AccessReview, error) {
+	args := e.Called()
+
+	retReview := args.Get(0).(types.AccessReview)
+
+	return retReview, args.Error(1)
+}
+
 // AllActions returns nothing but satisfies the Engine interface.
 func (e *Engine) AllActions() []string {
 	return nil
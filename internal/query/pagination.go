@@ -0,0 +1,91 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+// ListOptions defines the page of results returned by list operations.
+// Pages may contain fewer results than the limit. An empty next cursor is returned once the final page is reached.
+type ListOptions struct {
+	// Limit is the maximum number of results to return. A limit of zero returns all results.
+	Limit int
+	// Cursor is the next cursor returned by a previous list call, continuing the listing after that page.
+	Cursor string
+}
+
+// cursor is the decoded form of the opaque cursor returned to clients.
+type cursor struct {
+	// AfterID continues listings ordered by ID after the provided ID.
+	AfterID gidx.PrefixedID `json:"a,omitempty"`
+	// Token continues relationship listings from the SpiceDB cursor token.
+	Token string `json:"t,omitempty"`
+	// Filter is the index of the relationship filter the token applies to for listings which read multiple filters.
+	Filter int `json:"f,omitempty"`
+}
+
+func (c cursor) encode() string {
+	if c == (cursor{}) {
+		return ""
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		// a cursor is always serializable.
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// cursor decodes the options cursor.
+func (o ListOptions) cursor() (cursor, error) {
+	var c cursor
+
+	if o.Cursor == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if c.Filter < 0 {
+		return c, fmt.Errorf("%w: invalid filter index", ErrInvalidCursor)
+	}
+
+	return c, nil
+}
+
+// storageOptions converts the options into storage list options.
+func (o ListOptions) storageOptions() (storage.ListOptions, error) {
+	c, err := o.cursor()
+	if err != nil {
+		return storage.ListOptions{}, err
+	}
+
+	return storage.ListOptions{
+		Limit:   o.Limit,
+		AfterID: c.AfterID,
+	}, nil
+}
+
+// nextIDCursor returns the cursor for the page following a page of ID ordered results.
+// If the page isn't full, no more results exist and an empty cursor is returned.
+func (o ListOptions) nextIDCursor(count int, lastID gidx.PrefixedID) string {
+	if o.Limit <= 0 || count < o.Limit {
+		return ""
+	}
+
+	return cursor{AfterID: lastID}.encode()
+}
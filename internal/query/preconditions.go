@@ -0,0 +1,38 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type versionPreconditionCtxKey struct{}
+
+// ContextWithVersionPrecondition returns a copy of ctx which makes UpdateRoleV2, DeleteRoleV2,
+// UpdateRoleBinding and DeleteRoleBinding conditional. The change is only made if the UpdatedAt of the
+// role or role-binding equals one of the given versions, otherwise ErrPreconditionFailed is returned.
+// The version is checked while the role or role-binding is locked for update.
+func ContextWithVersionPrecondition(ctx context.Context, versions ...time.Time) context.Context {
+	if versions == nil {
+		versions = []time.Time{}
+	}
+
+	return context.WithValue(ctx, versionPreconditionCtxKey{}, versions)
+}
+
+// checkVersionPrecondition returns ErrPreconditionFailed if ctx has a version precondition which
+// updatedAt does not match.
+func checkVersionPrecondition(ctx context.Context, updatedAt time.Time) error {
+	versions, ok := ctx.Value(versionPreconditionCtxKey{}).([]time.Time)
+	if !ok {
+		return nil
+	}
+
+	for _, version := range versions {
+		if version.Equal(updatedAt) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: current version updated at %s", ErrPreconditionFailed, updatedAt.Format(time.RFC3339Nano))
+}
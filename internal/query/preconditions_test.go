@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestCheckVersionPrecondition(t *testing.T) {
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 5, 6, 16, 0, 46, 0, time.UTC)
+
+	assert.NoError(t, checkVersionPrecondition(ctx, updatedAt))
+	assert.NoError(t, checkVersionPrecondition(ContextWithVersionPrecondition(ctx, updatedAt.In(time.Local)), updatedAt))
+	assert.ErrorIs(t, checkVersionPrecondition(ContextWithVersionPrecondition(ctx, updatedAt.Add(time.Microsecond)), updatedAt), ErrPreconditionFailed)
+	assert.ErrorIs(t, checkVersionPrecondition(ContextWithVersionPrecondition(ctx), updatedAt), ErrPreconditionFailed)
+}
+
+func TestUpdateRoleV2Precondition(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, t.Name(), "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	// the first update against the created version succeeds
+	updated, err := e.UpdateRoleV2(ContextWithVersionPrecondition(ctx, role.UpdatedAt), actor, roleRes, "", []string{"loadbalancer_get", "loadbalancer_list"})
+	require.NoError(t, err)
+
+	// a concurrent update made against the same version is rejected
+	_, err = e.UpdateRoleV2(ContextWithVersionPrecondition(ctx, role.UpdatedAt), actor, roleRes, "", []string{"loadbalancer_update"})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	current, err := e.GetRoleV2(ctx, roleRes)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"loadbalancer_get", "loadbalancer_list"}, current.Actions)
+
+	err = e.DeleteRoleV2(ContextWithVersionPrecondition(ctx, role.UpdatedAt), roleRes)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	err = e.DeleteRoleV2(ContextWithVersionPrecondition(ctx, updated.UpdatedAt), roleRes)
+	assert.NoError(t, err)
+}
+
+func TestUpdateRoleBindingPrecondition(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, t.Name(), "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	rb, err := e.CreateRoleBinding(ctx, actor, tenant, roleRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+	rbRes, err := e.NewResourceFromID(rb.ID)
+	require.NoError(t, err)
+
+	updated, err := e.UpdateRoleBinding(ContextWithVersionPrecondition(ctx, rb.UpdatedAt), actor, rbRes, []types.RoleBindingSubject{{SubjectResource: subj}})
+	require.NoError(t, err)
+
+	_, err = e.UpdateRoleBinding(ContextWithVersionPrecondition(ctx, rb.UpdatedAt), actor, rbRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	err = e.DeleteRoleBinding(ContextWithVersionPrecondition(ctx, rb.UpdatedAt), rbRes)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	err = e.DeleteRoleBinding(ContextWithVersionPrecondition(ctx, updated.UpdatedAt), rbRes)
+	assert.NoError(t, err)
+}
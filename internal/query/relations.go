@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"go.infratographer.com/x/gidx"
@@ -15,6 +16,7 @@ import (
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/status"
 
 	"go.infratographer.com/permissions-api/internal/storage"
 	"go.infratographer.com/permissions-api/internal/types"
@@ -113,6 +115,8 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 
 	defer span.End()
 
+	start := time.Now()
+
 	consistency, consName := e.determineConsistency(ctx, resource)
 	span.SetAttributes(
 		attribute.String(
@@ -121,6 +125,8 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 		),
 	)
 
+	setCheckConsistency(ctx, consName)
+
 	err := e.validateResourceActions(resource, action)
 
 	// Only check permissions if the requested action exists in the policy.
@@ -134,11 +140,15 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 			},
 		}
 
-		err = e.checkPermission(ctx, req)
+		err = e.checkPermissionRecordingUsage(ctx, req, subject)
 	}
 
+	outcome := outcomeError
+
 	switch {
 	case err == nil:
+		outcome = outcomeAllowed
+
 		span.SetAttributes(
 			attribute.String(
 				"permissions.outcome",
@@ -146,6 +156,8 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 			),
 		)
 	case errors.Is(err, ErrActionNotAssigned), errors.Is(err, ErrInvalidAction):
+		outcome = outcomeDenied
+
 		span.SetAttributes(
 			attribute.String(
 				"permissions.outcome",
@@ -156,9 +168,95 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 		span.SetStatus(codes.Error, err.Error())
 	}
 
+	// actions which do not exist are not labeled, as they are provided by callers.
+	actionLabel := action
+	if errors.Is(err, ErrInvalidAction) {
+		actionLabel = invalidActionLabel
+	}
+
+	checksTotal.WithLabelValues(outcome, actionLabel, resource.Type).Inc()
+	checkDuration.WithLabelValues(outcome, resource.Type).Observe(time.Since(start).Seconds())
+
 	return err
 }
 
+// EffectiveActions returns the role actions the given subject can perform on the given resource,
+// checking all actions available to the resource type with a single bulk check.
+func (e *engine) EffectiveActions(ctx context.Context, subject, resource types.Resource) ([]string, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"EffectiveActions",
+		trace.WithAttributes(
+			attribute.Stringer("permissions.actor", subject.ID),
+			attribute.Stringer("permissions.resource", resource.ID),
+		),
+	)
+
+	defer span.End()
+
+	resourceActions := []string{}
+
+	for _, action := range e.AllActions() {
+		if e.validateResourceActions(resource, action) == nil {
+			resourceActions = append(resourceActions, action)
+		}
+	}
+
+	if len(resourceActions) == 0 {
+		return resourceActions, nil
+	}
+
+	consistency, consName := e.determineConsistency(ctx, resource)
+	span.SetAttributes(
+		attribute.String(
+			"permissions.consistency",
+			consName,
+		),
+	)
+
+	req := &pb.CheckBulkPermissionsRequest{
+		Consistency: consistency,
+		Items:       make([]*pb.CheckBulkPermissionsRequestItem, len(resourceActions)),
+	}
+
+	for i, action := range resourceActions {
+		req.Items[i] = &pb.CheckBulkPermissionsRequestItem{
+			Resource:   resourceToSpiceDBRef(e.namespace, resource),
+			Permission: action,
+			Subject: &pb.SubjectReference{
+				Object: resourceToSpiceDBRef(e.namespace, subject),
+			},
+		}
+	}
+
+	resp, err := e.client.CheckBulkPermissions(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	actions := []string{}
+
+	for _, pair := range resp.Pairs {
+		if pairErr := pair.GetError(); pairErr != nil {
+			err := status.ErrorProto(pairErr)
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		if pair.GetItem().GetPermissionship() == pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+			actions = append(actions, pair.GetRequest().GetPermission())
+		}
+	}
+
+	return actions, nil
+}
+
 // AssignSubjectRole assigns the given role to the given subject.
 func (e *engine) AssignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error {
 	request := &pb.WriteRelationshipsRequest{
@@ -187,8 +285,13 @@ func (e *engine) UnassignSubjectRole(ctx context.Context, subject types.Resource
 	return nil
 }
 
-// ListAssignments returns the assigned subjects for a given role.
-func (e *engine) ListAssignments(ctx context.Context, role types.Role) ([]types.Resource, error) {
+// ListAssignments returns a page of the assigned subjects for a given role.
+func (e *engine) ListAssignments(ctx context.Context, role types.Role, opts ListOptions) ([]types.Resource, string, error) {
+	cur, err := opts.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
 	roleType := e.namespace + "/role"
 	filter := &pb.RelationshipFilter{
 		ResourceType:       roleType,
@@ -196,9 +299,9 @@ func (e *engine) ListAssignments(ctx context.Context, role types.Role) ([]types.
 		OptionalRelation:   roleSubjectRelation,
 	}
 
-	relationships, err := e.readRelationships(ctx, filter)
+	relationships, nextToken, err := e.readRelationshipsPage(ctx, filter, opts.Limit, cur.Token)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	out := make([]types.Resource, len(relationships))
@@ -206,18 +309,18 @@ func (e *engine) ListAssignments(ctx context.Context, role types.Role) ([]types.
 	for i, rel := range relationships {
 		id, err := gidx.Parse(rel.Subject.Object.ObjectId)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		res, err := e.NewResourceFromID(id)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		out[i] = res
 	}
 
-	return out, nil
+	return out, cursor{Token: nextToken}.encode(), nil
 }
 
 func (e *engine) subjectRoleRelCreate(subject types.Resource, role types.Role) *pb.RelationshipUpdate {
@@ -261,6 +364,8 @@ func (e *engine) checkPermission(ctx context.Context, req *pb.CheckPermissionReq
 		return err
 	}
 
+	setCheckedAt(ctx, resp)
+
 	if resp.Permissionship == pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
 		return nil
 	}
@@ -616,6 +721,15 @@ func (e *engine) relationshipsToUpdates(rels []types.Relationship, operation pb.
 }
 
 func (e *engine) readRelationships(ctx context.Context, filter *pb.RelationshipFilter) ([]*pb.Relationship, error) {
+	relationships, _, err := e.readRelationshipsPage(ctx, filter, 0, "")
+
+	return relationships, err
+}
+
+// readRelationshipsPage reads up to limit relationships matching the filter, continuing from the provided cursor token.
+// A limit of zero reads all relationships. The returned token continues reading after the last relationship returned,
+// and is empty once all relationships have been read.
+func (e *engine) readRelationshipsPage(ctx context.Context, filter *pb.RelationshipFilter, limit int, token string) ([]*pb.Relationship, string, error) {
 	req := pb.ReadRelationshipsRequest{
 		Consistency: &pb.Consistency{
 			Requirement: &pb.Consistency_FullyConsistent{
@@ -626,13 +740,24 @@ func (e *engine) readRelationships(ctx context.Context, filter *pb.RelationshipF
 
 	req.RelationshipFilter = filter
 
+	if limit > 0 {
+		req.OptionalLimit = uint32(limit) //nolint:gosec // limit is bounded by the api pagination limits.
+	}
+
+	if token != "" {
+		req.OptionalCursor = &pb.Cursor{
+			Token: token,
+		}
+	}
+
 	r, err := e.client.ReadRelationships(ctx, &req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var (
 		responses []*pb.Relationship
+		nextToken string
 		done      bool
 	)
 
@@ -641,14 +766,19 @@ func (e *engine) readRelationships(ctx context.Context, filter *pb.RelationshipF
 		switch err {
 		case nil:
 			responses = append(responses, rel.Relationship)
+			nextToken = rel.GetAfterResultCursor().GetToken()
 		case io.EOF:
 			done = true
 		default:
-			return nil, err
+			return nil, "", err
 		}
 	}
 
-	return responses, nil
+	if limit <= 0 || len(responses) < limit {
+		nextToken = ""
+	}
+
+	return responses, nextToken, nil
 }
 
 // DeleteRelationships removes the specified relationships.
@@ -798,8 +928,14 @@ func (e *engine) relationshipsToNonRoles(rels []*pb.Relationship) ([]types.Relat
 	return out, nil
 }
 
-// ListRelationshipsFrom returns all non-role relationships bound to a given resource.
-func (e *engine) ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+// ListRelationshipsFrom returns a page of the non-role relationships bound to a given resource.
+// As role relationships are excluded, pages may contain fewer relationships than the limit.
+func (e *engine) ListRelationshipsFrom(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Relationship, string, error) {
+	cur, err := opts.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
 	resType := e.namespace + "/" + resource.Type
 
 	filter := &pb.RelationshipFilter{
@@ -807,48 +943,115 @@ func (e *engine) ListRelationshipsFrom(ctx context.Context, resource types.Resou
 		OptionalResourceId: resource.ID.String(),
 	}
 
-	relationships, err := e.readRelationships(ctx, filter)
+	relationships, nextToken, err := e.readRelationshipsPage(ctx, filter, opts.Limit, cur.Token)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return e.relationshipsToNonRoles(relationships)
+	out, err := e.relationshipsToNonRoles(relationships)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, cursor{Token: nextToken}.encode(), nil
 }
 
-// ListRelationshipsTo returns all non-role relationships destined for a given resource.
-func (e *engine) ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+// ListRelationshipsTo returns a page of the non-role relationships destined for a given resource.
+// As role relationships are excluded, pages may contain fewer relationships than the limit.
+func (e *engine) ListRelationshipsTo(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Relationship, string, error) {
 	relTypes, ok := e.schemaSubjectRelationMap[resource.Type]
 	if !ok {
-		return nil, ErrInvalidType
+		return nil, "", ErrInvalidType
+	}
+
+	cur, err := opts.cursor()
+	if err != nil {
+		return nil, "", err
 	}
 
-	var relationships []*pb.Relationship
+	// filters are sorted so the filter index in the cursor is stable between requests.
+	relations := make([]string, 0, len(relTypes))
+
+	for relation := range relTypes {
+		relations = append(relations, relation)
+	}
 
-	for _, types := range relTypes {
-		for _, relType := range types {
-			rels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+	slices.Sort(relations)
+
+	var filters []*pb.RelationshipFilter
+
+	for _, relation := range relations {
+		for _, relType := range relTypes[relation] {
+			filters = append(filters, &pb.RelationshipFilter{
 				ResourceType: e.namespace + "/" + relType,
 				OptionalSubjectFilter: &pb.SubjectFilter{
 					SubjectType:       e.namespace + "/" + resource.Type,
 					OptionalSubjectId: resource.ID.String(),
 				},
 			})
-			if err != nil {
-				return nil, err
+		}
+	}
+
+	if cur.Filter >= len(filters) {
+		return nil, "", fmt.Errorf("%w: filter index out of range", ErrInvalidCursor)
+	}
+
+	var (
+		relationships []*pb.Relationship
+		next          cursor
+	)
+
+	token := cur.Token
+
+	for i := cur.Filter; i < len(filters); i++ {
+		var limit int
+
+		if opts.Limit > 0 {
+			limit = opts.Limit - len(relationships)
+		}
+
+		rels, nextToken, err := e.readRelationshipsPage(ctx, filters[i], limit, token)
+		if err != nil {
+			return nil, "", err
+		}
+
+		relationships = append(relationships, rels...)
+		token = ""
+
+		if nextToken != "" {
+			next = cursor{Filter: i, Token: nextToken}
+
+			break
+		}
+
+		if opts.Limit > 0 && len(relationships) >= opts.Limit {
+			if i+1 < len(filters) {
+				next = cursor{Filter: i + 1}
 			}
 
-			relationships = append(relationships, rels...)
+			break
 		}
 	}
 
-	return e.relationshipsToNonRoles(relationships)
+	out, err := e.relationshipsToNonRoles(relationships)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, next.encode(), nil
 }
 
-// ListRoles returns all roles bound to a given resource.
-func (e *engine) ListRoles(ctx context.Context, resource types.Resource) ([]types.Role, error) {
-	dbRoles, err := e.store.ListResourceRoles(ctx, resource.ID)
+// ListRoles returns a page of the roles bound to a given resource.
+// As v2 roles are excluded, pages may contain fewer roles than the limit.
+func (e *engine) ListRoles(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Role, string, error) {
+	storageOpts, err := opts.storageOptions()
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	dbRoles, err := e.store.ListResourceRoles(ctx, resource.ID, storageOpts)
+	if err != nil {
+		return nil, "", err
 	}
 
 	dbRolesv1 := make([]storage.Role, 0, len(dbRoles))
@@ -856,7 +1059,7 @@ func (e *engine) ListRoles(ctx context.Context, resource types.Resource) ([]type
 	for _, dbRole := range dbRoles {
 		res, err := e.NewResourceFromID(dbRole.ID)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		if res.Type == e.rbac.RoleResource.Name {
@@ -882,7 +1085,7 @@ func (e *engine) ListRoles(ctx context.Context, resource types.Resource) ([]type
 
 	relationships, err := e.readRelationships(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	spicedbRoles := relationshipsToRoles(relationships)
@@ -911,26 +1114,34 @@ func (e *engine) ListRoles(ctx context.Context, resource types.Resource) ([]type
 		}
 	}
 
-	return out, nil
+	var lastID gidx.PrefixedID
+
+	if len(dbRoles) != 0 {
+		lastID = dbRoles[len(dbRoles)-1].ID
+	}
+
+	return out, opts.nextIDCursor(len(dbRoles), lastID), nil
 }
 
-// ListManagerRoles returns all roles bound to a given resource.
-func (e *engine) ListManagerRoles(ctx context.Context, manager string, resource types.Resource) ([]types.Role, error) {
-	dbRoles, err := e.store.ListResourceRoles(ctx, resource.ID)
+// ListManagerRoles returns a page of the roles bound to a given resource with the given manager.
+// As v2 roles are excluded, pages may contain fewer roles than the limit.
+func (e *engine) ListManagerRoles(ctx context.Context, manager string, resource types.Resource, opts ListOptions) ([]types.Role, string, error) {
+	storageOpts, err := opts.storageOptions()
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	dbRoles, err := e.store.ListManagerResourceRoles(ctx, manager, resource.ID, storageOpts)
+	if err != nil {
+		return nil, "", err
 	}
 
 	dbRolesv1 := make([]storage.Role, 0, len(dbRoles))
 
 	for _, dbRole := range dbRoles {
-		if dbRole.Manager != manager {
-			continue
-		}
-
 		res, err := e.NewResourceFromID(dbRole.ID)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		if res.Type == e.rbac.RoleResource.Name {
@@ -956,7 +1167,7 @@ func (e *engine) ListManagerRoles(ctx context.Context, manager string, resource
 
 	relationships, err := e.readRelationships(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	spicedbRoles := relationshipsToRoles(relationships)
@@ -985,7 +1196,13 @@ func (e *engine) ListManagerRoles(ctx context.Context, manager string, resource
 		}
 	}
 
-	return out, nil
+	var lastID gidx.PrefixedID
+
+	if len(dbRoles) != 0 {
+		lastID = dbRoles[len(dbRoles)-1].ID
+	}
+
+	return out, opts.nextIDCursor(len(dbRoles), lastID), nil
 }
 
 // listRoleResourceActions returns all resources and action relations for the provided resource type to the provided role.
@@ -1253,9 +1470,13 @@ func (e *engine) rollbackUpdates(ctx context.Context, updates []*pb.Relationship
 
 	_, err := e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: rollbacks})
 	if err != nil {
+		rollbacksTotal.WithLabelValues(outcomeError).Inc()
+
 		return err
 	}
 
+	rollbacksTotal.WithLabelValues("success").Inc()
+
 	return nil
 }
 
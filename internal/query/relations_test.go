@@ -235,7 +235,7 @@ func TestRoleUpdate(t *testing.T) {
 
 	role, err := e.CreateRole(ctx, actorRes, tenRes, t.Name(), "test", []string{"loadbalancer_get"})
 	require.NoError(t, err)
-	roles, err := e.ListRoles(ctx, tenRes)
+	roles, _, err := e.ListRoles(ctx, tenRes, ListOptions{})
 	require.NoError(t, err)
 	require.NotEmpty(t, roles)
 
@@ -363,7 +363,7 @@ func TestListRoles(t *testing.T) {
 	testFn := func(ctx context.Context, _ any) testingx.TestResult[[]types.Role] {
 		tenRes := ctx.Value(tenCtx).(types.Resource)
 
-		roles, err := e.ListRoles(ctx, tenRes)
+		roles, _, err := e.ListRoles(ctx, tenRes, ListOptions{})
 
 		return testingx.TestResult[[]types.Role]{
 			Success: roles,
@@ -388,7 +388,7 @@ func TestRoleDelete(t *testing.T) {
 
 	role, err := e.CreateRole(ctx, actorRes, tenRes, t.Name(), "test", []string{"loadbalancer_get"})
 	require.NoError(t, err)
-	roles, err := e.ListRoles(ctx, tenRes)
+	roles, _, err := e.ListRoles(ctx, tenRes, ListOptions{})
 	require.NoError(t, err)
 	require.NotEmpty(t, roles)
 
@@ -425,7 +425,7 @@ func TestRoleDelete(t *testing.T) {
 			}
 		}
 
-		roles, err := e.ListRoles(ctx, tenRes)
+		roles, _, err := e.ListRoles(ctx, tenRes, ListOptions{})
 
 		return testingx.TestResult[[]types.Role]{
 			Success: roles,
@@ -486,7 +486,7 @@ func TestAssignments(t *testing.T) {
 			}
 		}
 
-		resources, err := e.ListAssignments(ctx, role)
+		resources, _, err := e.ListAssignments(ctx, role, ListOptions{})
 
 		return testingx.TestResult[[]types.Resource]{
 			Success: resources,
@@ -497,6 +497,50 @@ func TestAssignments(t *testing.T) {
 	testingx.RunTests(ctx, t, testCases, testFn)
 }
 
+func TestAssignmentsPagination(t *testing.T) {
+	namespace := "testassignmentspagination"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, testPolicy())
+
+	tenRes, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	actorRes, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+	role, err := e.CreateRole(
+		ctx,
+		actorRes,
+		tenRes,
+		t.Name(),
+		"test",
+		[]string{
+			"loadbalancer_update",
+		},
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		subjRes, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+		require.NoError(t, err)
+
+		require.NoError(t, e.AssignSubjectRole(ctx, subjRes, role))
+	}
+
+	firstPage, cursor, err := e.ListAssignments(ctx, role, ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, firstPage, 2)
+	require.NotEmpty(t, cursor, "expected a cursor for the next page")
+
+	secondPage, cursor, err := e.ListAssignments(ctx, role, ListOptions{Limit: 2, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Len(t, secondPage, 1)
+	assert.Empty(t, cursor, "expected no cursor after the final page")
+
+	assert.NotContains(t, firstPage, secondPage[0])
+
+	_, _, err = e.ListAssignments(ctx, role, ListOptions{Limit: 2, Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
 func TestUnassignments(t *testing.T) {
 	namespace := "testassignments"
 	ctx := context.Background()
@@ -543,7 +587,7 @@ func TestUnassignments(t *testing.T) {
 			}
 		}
 
-		resources, err := e.ListAssignments(ctx, role)
+		resources, _, err := e.ListAssignments(ctx, role, ListOptions{})
 		if err != nil {
 			return testingx.TestResult[[]types.Resource]{
 				Err: err,
@@ -569,7 +613,7 @@ func TestUnassignments(t *testing.T) {
 			}
 		}
 
-		resources, err = e.ListAssignments(ctx, role)
+		resources, _, err = e.ListAssignments(ctx, role, ListOptions{})
 
 		return testingx.TestResult[[]types.Resource]{
 			Success: resources,
@@ -660,7 +704,7 @@ func TestRelationships(t *testing.T) {
 			}
 		}
 
-		rels, err := e.ListRelationshipsFrom(ctx, input.Resource)
+		rels, _, err := e.ListRelationshipsFrom(ctx, input.Resource, ListOptions{})
 
 		return testingx.TestResult[[]types.Relationship]{
 			Success: rels,
@@ -694,7 +738,7 @@ func TestRelationshipDelete(t *testing.T) {
 	err = e.CreateRelationships(ctx, []types.Relationship{relReq})
 	require.NoError(t, err)
 
-	createdResources, err := e.ListRelationshipsFrom(ctx, childRes)
+	createdResources, _, err := e.ListRelationshipsFrom(ctx, childRes, ListOptions{})
 	require.NoError(t, err)
 	require.NotEmpty(t, createdResources)
 
@@ -732,7 +776,7 @@ func TestRelationshipDelete(t *testing.T) {
 			}
 		}
 
-		rels, err := e.ListRelationshipsFrom(ctx, input.Resource)
+		rels, _, err := e.ListRelationshipsFrom(ctx, input.Resource, ListOptions{})
 
 		return testingx.TestResult[[]types.Relationship]{
 			Success: rels,
@@ -0,0 +1,554 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// RoleBindingRequestIDPrefix is the ID prefix of role binding requests.
+const RoleBindingRequestIDPrefix = "permrbr"
+
+type roleBindingApprovedCtxKey struct{}
+
+// contextWithRoleBindingApproved marks role bindings created with the context as approved.
+func contextWithRoleBindingApproved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, roleBindingApprovedCtxKey{}, true)
+}
+
+// checkRoleBindingApproval ensures role bindings of roles granting actions which require
+// approval are only created from approved role binding requests.
+func (e *engine) checkRoleBindingApproval(ctx context.Context, role types.Resource) error {
+	if approved, _ := ctx.Value(roleBindingApprovedCtxKey{}).(bool); approved {
+		return nil
+	}
+
+	required, err := e.roleRequiresApproval(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	if required {
+		return fmt.Errorf("%w: role %s", ErrRoleBindingApprovalRequired, role.ID)
+	}
+
+	return nil
+}
+
+// RoleBindingRequiresApproval reports whether role bindings of the role must be approved
+// before they are created.
+func (e *engine) RoleBindingRequiresApproval(ctx context.Context, role types.Resource) (bool, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.RoleBindingRequiresApproval",
+		trace.WithAttributes(attribute.Stringer("role_id", role.ID)),
+	)
+	defer span.End()
+
+	required, err := e.roleRequiresApproval(ctx, role)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return false, err
+	}
+
+	return required, nil
+}
+
+// roleRequiresApproval reports whether the role, or any role it includes, contains an
+// action which requires approval.
+func (e *engine) roleRequiresApproval(ctx context.Context, role types.Resource) (bool, error) {
+	if len(e.approvalActions) == 0 {
+		return false, nil
+	}
+
+	actions, err := e.roleV2EffectiveActions(ctx, role.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, action := range actions {
+		if _, ok := e.approvalActions[action]; ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// checkRoleUpdateApproval ensures that adding actions to a role, directly or through included roles,
+// does not grant actions which require approval to subjects already bound to the role, or to a role
+// including it, without their role bindings being reviewed.
+func (e *engine) checkRoleUpdateApproval(ctx context.Context, role types.Role, added []string) error {
+	if approved, _ := ctx.Value(roleBindingApprovedCtxKey{}).(bool); approved || len(e.approvalActions) == 0 {
+		return nil
+	}
+
+	var sensitive []string
+
+	for _, action := range added {
+		if _, ok := e.approvalActions[action]; ok {
+			sensitive = append(sensitive, action)
+		}
+	}
+
+	if len(sensitive) == 0 {
+		return nil
+	}
+
+	// actions the role already grants through its includes are not granted by the change.
+	current, err := e.roleV2EffectiveActions(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+
+	sensitive = slices.DeleteFunc(sensitive, func(action string) bool {
+		return slices.Contains(current, action)
+	})
+
+	if len(sensitive) == 0 {
+		return nil
+	}
+
+	bound, err := e.roleV2Bound(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+
+	if bound {
+		return fmt.Errorf(
+			"%w: role %s is bound and would grant %s",
+			ErrRoleBindingApprovalRequired, role.ID, strings.Join(sensitive, ", "),
+		)
+	}
+
+	return nil
+}
+
+// roleV2Bound reports whether the role, or any role including it directly or transitively, is bound.
+func (e *engine) roleV2Bound(ctx context.Context, roleID gidx.PrefixedID) (bool, error) {
+	roleType := e.namespaced(e.rbac.RoleResource.Name)
+
+	visited := make(map[gidx.PrefixedID]struct{})
+	queue := []gidx.PrefixedID{roleID}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if _, ok := visited[id]; ok {
+			continue
+		}
+
+		visited[id] = struct{}{}
+
+		bindings, _, err := e.readRelationshipsPage(ctx, &pb.RelationshipFilter{
+			ResourceType:     e.namespaced(e.rbac.RoleBindingResource.Name),
+			OptionalRelation: iapl.RolebindingRoleRelation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       roleType,
+				OptionalSubjectId: id.String(),
+			},
+		}, 1, "")
+		if err != nil {
+			return false, err
+		}
+
+		if len(bindings) != 0 {
+			return true, nil
+		}
+
+		includedBy, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+			ResourceType:     roleType,
+			OptionalRelation: iapl.RoleIncludeRelation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       roleType,
+				OptionalSubjectId: id.String(),
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, rel := range includedBy {
+			parentID, err := gidx.Parse(rel.Resource.ObjectId)
+			if err != nil {
+				return false, err
+			}
+
+			queue = append(queue, parentID)
+		}
+	}
+
+	return false, nil
+}
+
+// CreateRoleBindingRequest records a pending role binding request after validating that the
+// role binding could be created. The role binding is created once the request is approved.
+func (e *engine) CreateRoleBindingRequest(
+	ctx context.Context,
+	actor, resource, roleResource types.Resource,
+	manager string,
+	subjects []types.RoleBindingSubject,
+) (types.RoleBindingRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateRoleBindingRequest",
+		trace.WithAttributes(
+			attribute.Stringer("role_id", roleResource.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	existing, ok, err := e.idempotentRoleBindingRequest(dbCtx, actor, resource, roleResource)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	if ok {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return existing, nil
+	}
+
+	req, err := e.createRoleBindingRequest(dbCtx, actor, resource, roleResource, manager, subjects)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		if err := e.store.SetRoleBindingRequestIdempotencyKey(dbCtx, actor.ID, req.ID, key); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.RoleBindingRequest{}, err
+		}
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) createRoleBindingRequest(
+	dbCtx context.Context,
+	actor, resource, roleResource types.Resource,
+	manager string,
+	subjects []types.RoleBindingSubject,
+) (types.RoleBindingRequest, error) {
+	if len(subjects) == 0 {
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: role binding must have at least one subject", ErrInvalidArgument)
+	}
+
+	// validate the role binding with the same checks as on approval, without writing it.
+	if err := e.isRoleBindable(dbCtx, roleResource, resource); err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	if _, err := e.store.GetRoleByID(dbCtx, roleResource.ID); err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
+		}
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	subjectIDs := make([]gidx.PrefixedID, len(subjects))
+	subjectResources := make([]types.Resource, len(subjects))
+
+	for i, subj := range subjects {
+		if _, err := e.rolebindingSubjectRelationship(subj.SubjectResource, ""); err != nil {
+			return types.RoleBindingRequest{}, err
+		}
+
+		subjectIDs[i] = subj.SubjectResource.ID
+		subjectResources[i] = subj.SubjectResource
+	}
+
+	if e.escalationGuardEnabled(dbCtx) {
+		actions, err := e.roleV2EffectiveActions(dbCtx, roleResource.ID)
+		if err != nil {
+			return types.RoleBindingRequest{}, err
+		}
+
+		if err := e.checkPrivilegeEscalation(dbCtx, actor, resource, actions); err != nil {
+			return types.RoleBindingRequest{}, err
+		}
+	}
+
+	if err := e.checkSeparationOfDuties(dbCtx, resource, roleResource.ID, subjectResources); err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	id, err := gidx.NewID(RoleBindingRequestIDPrefix)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	return e.store.CreateRoleBindingRequest(dbCtx, actor.ID, types.RoleBindingRequest{
+		ID:         id,
+		ResourceID: resource.ID,
+		RoleID:     roleResource.ID,
+		SubjectIDs: subjectIDs,
+		Manager:    manager,
+	})
+}
+
+// GetRoleBindingRequest fetches a role binding request by its ID.
+func (e *engine) GetRoleBindingRequest(ctx context.Context, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetRoleBindingRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	req, err := e.getRoleBindingRequest(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) getRoleBindingRequest(ctx context.Context, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	req, err := e.store.GetRoleBindingRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrRoleBindingRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, err)
+		}
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+// ListRoleBindingRequests returns a page of the role binding requests on the resource with
+// the given status, along with the next page cursor. An empty status lists requests of any status.
+func (e *engine) ListRoleBindingRequests(
+	ctx context.Context, resource types.Resource, status types.RoleBindingRequestStatus, opts ListOptions,
+) ([]types.RoleBindingRequest, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListRoleBindingRequests",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("status", string(status)),
+		),
+	)
+	defer span.End()
+
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	reqs, err := e.store.ListResourceRoleBindingRequests(ctx, resource.ID, status, storageOpts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	var nextCursor string
+
+	if len(reqs) != 0 {
+		nextCursor = opts.nextIDCursor(len(reqs), reqs[len(reqs)-1].ID)
+	}
+
+	return reqs, nextCursor, nil
+}
+
+// ApproveRoleBindingRequest approves a pending role binding request, creating its role
+// binding on behalf of the requester. The approver must not be the requester.
+func (e *engine) ApproveRoleBindingRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ApproveRoleBindingRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, updates, err := e.approveRoleBindingRequest(dbCtx, actor, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) approveRoleBindingRequest(
+	dbCtx context.Context, actor types.Resource, id gidx.PrefixedID,
+) (types.RoleBindingRequest, []*pb.RelationshipUpdate, error) {
+	req, err := e.lockPendingRoleBindingRequest(dbCtx, actor, id)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	requester, err := e.NewResourceFromID(req.RequestedBy)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	resource, err := e.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	role, err := e.NewResourceFromID(req.RoleID)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	subjects := make([]types.RoleBindingSubject, len(req.SubjectIDs))
+
+	for i, subjID := range req.SubjectIDs {
+		subj, err := e.NewResourceFromID(subjID)
+		if err != nil {
+			return types.RoleBindingRequest{}, nil, err
+		}
+
+		subjects[i] = types.RoleBindingSubject{SubjectResource: subj}
+	}
+
+	rb, updates, err := e.prepareCreateRoleBinding(contextWithRoleBindingApproved(dbCtx), requester, resource, role, req.Manager, subjects)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	req, err = e.store.UpdateRoleBindingRequestStatus(dbCtx, actor.ID, id, types.RoleBindingRequestApproved, rb.ID)
+	if err != nil {
+		return types.RoleBindingRequest{}, nil, err
+	}
+
+	return req, updates, nil
+}
+
+// RejectRoleBindingRequest rejects a pending role binding request. The reviewer must not be
+// the requester.
+func (e *engine) RejectRoleBindingRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.RejectRoleBindingRequest",
+		trace.WithAttributes(attribute.Stringer("request_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	if _, err := e.lockPendingRoleBindingRequest(dbCtx, actor, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, err := e.store.UpdateRoleBindingRequestStatus(dbCtx, actor.ID, id, types.RoleBindingRequestRejected, "")
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+// lockPendingRoleBindingRequest locks the role binding request for review, ensuring it is
+// still pending and that the reviewer is neither the requester nor one of the subjects.
+func (e *engine) lockPendingRoleBindingRequest(dbCtx context.Context, reviewer types.Resource, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	if err := e.store.LockRoleBindingRequestForUpdate(dbCtx, id); err != nil {
+		if errors.Is(err, storage.ErrRoleBindingRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, err)
+		}
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, err := e.getRoleBindingRequest(dbCtx, id)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	if req.Status != types.RoleBindingRequestPending {
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: request %s is %s", ErrRoleBindingRequestNotPending, id, req.Status)
+	}
+
+	if req.RequestedBy == reviewer.ID || slices.Contains(req.SubjectIDs, reviewer.ID) {
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: request %s", ErrRoleBindingRequestSelfReview, id)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,183 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func roleBindingApprovalTestPolicy() iapl.Policy {
+	doc := DefaultPolicyDocumentV2()
+
+	for i, action := range doc.Actions {
+		if action.Name == "role_delete" {
+			doc.Actions[i].RequiresApproval = true
+		}
+	}
+
+	p := iapl.NewPolicy(doc)
+
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestRoleBindingRequests(t *testing.T) {
+	namespace := "testrolebindingrequests"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, roleBindingApprovalTestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	requester, err := e.NewResourceFromIDString("idntusr-requester")
+	require.NoError(t, err)
+
+	approver, err := e.NewResourceFromIDString("idntusr-approver")
+	require.NoError(t, err)
+
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	newRole := func(name string, actions ...string) types.Resource {
+		role, err := e.CreateRoleV2(ctx, requester, root, "", name, actions)
+		require.NoError(t, err)
+
+		res, err := e.NewResourceFromID(role.ID)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	viewer := newRole("viewer", "role_get")
+	deleter := newRole("deleter", "role_get", "role_delete")
+
+	subjects := []types.RoleBindingSubject{{SubjectResource: user}}
+
+	t.Run("RequiresApproval", func(t *testing.T) {
+		required, err := e.RoleBindingRequiresApproval(ctx, viewer)
+		require.NoError(t, err)
+		assert.False(t, required)
+
+		required, err = e.RoleBindingRequiresApproval(ctx, deleter)
+		require.NoError(t, err)
+		assert.True(t, required)
+	})
+
+	t.Run("CreateRoleBindingWithoutApproval", func(t *testing.T) {
+		_, err := e.CreateRoleBinding(ctx, requester, root, deleter, "", subjects)
+		assert.ErrorIs(t, err, ErrRoleBindingApprovalRequired)
+
+		_, err = e.CreateRoleBinding(ctx, requester, root, viewer, "", subjects)
+		assert.NoError(t, err)
+	})
+
+	t.Run("UpdateBoundRole", func(t *testing.T) {
+		// viewer is bound by CreateRoleBindingWithoutApproval
+		_, err := e.UpdateRoleV2(ctx, requester, viewer, "", []string{"role_get", "role_delete"})
+		assert.ErrorIs(t, err, ErrRoleBindingApprovalRequired)
+
+		_, err = e.UpdateRoleV2Includes(ctx, requester, viewer, []types.Resource{deleter})
+		assert.ErrorIs(t, err, ErrRoleBindingApprovalRequired)
+
+		role, err := e.GetRoleV2(ctx, viewer)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"role_get"}, role.Actions)
+		assert.Empty(t, role.Includes)
+
+		// a role including viewer grants its actions to viewer's subjects too
+		lead := newRole("lead", "role_get")
+
+		_, err = e.UpdateRoleV2Includes(ctx, requester, viewer, []types.Resource{lead})
+		require.NoError(t, err)
+
+		_, err = e.UpdateRoleV2(ctx, requester, lead, "", []string{"role_get", "role_delete"})
+		assert.ErrorIs(t, err, ErrRoleBindingApprovalRequired)
+
+		// unbound roles may be changed freely
+		spare := newRole("spare", "role_get")
+
+		_, err = e.UpdateRoleV2(ctx, requester, spare, "", []string{"role_get", "role_delete"})
+		require.NoError(t, err)
+
+		_, err = e.UpdateRoleV2Includes(ctx, requester, spare, []types.Resource{deleter})
+		require.NoError(t, err)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		req, err := e.CreateRoleBindingRequest(ctx, requester, root, deleter, "", subjects)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleBindingRequestPending, req.Status)
+
+		_, err = e.ApproveRoleBindingRequest(ctx, requester, req.ID)
+		assert.ErrorIs(t, err, ErrRoleBindingRequestSelfReview)
+
+		approved, err := e.ApproveRoleBindingRequest(ctx, approver, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleBindingRequestApproved, approved.Status)
+		assert.Equal(t, approver.ID, approved.ReviewedBy)
+
+		rbResource, err := e.NewResourceFromID(approved.RoleBindingID)
+		require.NoError(t, err)
+
+		rb, err := e.GetRoleBinding(ctx, rbResource)
+		require.NoError(t, err)
+		assert.Equal(t, deleter.ID, rb.RoleID)
+		assert.Equal(t, requester.ID, rb.CreatedBy)
+
+		_, err = e.RejectRoleBindingRequest(ctx, approver, req.ID)
+		assert.ErrorIs(t, err, ErrRoleBindingRequestNotPending)
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		keyCtx := ContextWithIdempotencyKey(ctx, "request-deleter")
+
+		req, err := e.CreateRoleBindingRequest(keyCtx, requester, root, deleter, "", subjects)
+		require.NoError(t, err)
+
+		retried, err := e.CreateRoleBindingRequest(keyCtx, requester, root, deleter, "", subjects)
+		require.NoError(t, err)
+		assert.Equal(t, req.ID, retried.ID)
+
+		_, err = e.CreateRoleBindingRequest(keyCtx, requester, root, viewer, "", subjects)
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+		_, err = e.RejectRoleBindingRequest(ctx, approver, req.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("SubjectReview", func(t *testing.T) {
+		req, err := e.CreateRoleBindingRequest(ctx, requester, root, deleter, "", []types.RoleBindingSubject{{SubjectResource: approver}})
+		require.NoError(t, err)
+
+		_, err = e.ApproveRoleBindingRequest(ctx, approver, req.ID)
+		assert.ErrorIs(t, err, ErrRoleBindingRequestSelfReview)
+
+		_, err = e.RejectRoleBindingRequest(ctx, approver, req.ID)
+		assert.ErrorIs(t, err, ErrRoleBindingRequestSelfReview)
+
+		_, err = e.RejectRoleBindingRequest(ctx, user, req.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		req, err := e.CreateRoleBindingRequest(ctx, requester, root, deleter, "", subjects)
+		require.NoError(t, err)
+
+		rejected, err := e.RejectRoleBindingRequest(ctx, approver, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleBindingRequestRejected, rejected.Status)
+		assert.Empty(t, rejected.RoleBindingID)
+
+		pending, _, err := e.ListRoleBindingRequests(ctx, root, types.RoleBindingRequestPending, ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+}
@@ -0,0 +1,339 @@
+package query
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// maxBufferedRoleBindingUsage is the maximum number of role binding and subject pairs whose usage
+// is buffered between flushes. Usage of further pairs is dropped until the buffer is flushed.
+const maxBufferedRoleBindingUsage = 10000
+
+type roleBindingUsageKey struct {
+	roleBindingID gidx.PrefixedID
+	subjectID     gidx.PrefixedID
+}
+
+// roleBindingUsageBuffer buffers the sampled usage of role bindings until it is flushed to storage.
+type roleBindingUsageBuffer struct {
+	sampleRate float64
+
+	mu    sync.Mutex
+	usage map[roleBindingUsageKey]time.Time
+}
+
+func newRoleBindingUsageBuffer(sampleRate float64) *roleBindingUsageBuffer {
+	return &roleBindingUsageBuffer{
+		sampleRate: sampleRate,
+		usage:      make(map[roleBindingUsageKey]time.Time),
+	}
+}
+
+// sample reports whether the usage of a permission check should be recorded.
+func (b *roleBindingUsageBuffer) sample() bool {
+	if b == nil {
+		return false
+	}
+
+	return rand.Float64() < b.sampleRate //nolint:gosec // sampling does not need a secure source
+}
+
+// add buffers the usage unless it is older than the usage already buffered.
+// It must be called with mu held.
+func (b *roleBindingUsageBuffer) add(key roleBindingUsageKey, usedAt time.Time) {
+	if last, ok := b.usage[key]; ok {
+		if usedAt.After(last) {
+			b.usage[key] = usedAt
+		}
+
+		return
+	}
+
+	if len(b.usage) < maxBufferedRoleBindingUsage {
+		b.usage[key] = usedAt
+	}
+}
+
+func (b *roleBindingUsageBuffer) record(keys []roleBindingUsageKey, usedAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		b.add(key, usedAt)
+	}
+}
+
+// take empties the buffer, returning the usage it held.
+func (b *roleBindingUsageBuffer) take() []types.RoleBindingUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usage := make([]types.RoleBindingUsage, 0, len(b.usage))
+
+	for key, usedAt := range b.usage {
+		usage = append(usage, types.RoleBindingUsage{
+			RoleBindingID: key.roleBindingID,
+			SubjectID:     key.subjectID,
+			LastUsedAt:    usedAt,
+		})
+	}
+
+	b.usage = make(map[roleBindingUsageKey]time.Time)
+
+	return usage
+}
+
+// restore returns usage which failed to be flushed to the buffer.
+func (b *roleBindingUsageBuffer) restore(usage []types.RoleBindingUsage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, u := range usage {
+		b.add(roleBindingUsageKey{roleBindingID: u.RoleBindingID, subjectID: u.SubjectID}, u.LastUsedAt)
+	}
+}
+
+// checkPermissionRecordingUsage checks the permission like checkPermission. Sampled checks are traced,
+// and the role bindings through which they are allowed are recorded as used by the subject.
+func (e *engine) checkPermissionRecordingUsage(ctx context.Context, req *pb.CheckPermissionRequest, subject types.Resource) error {
+	if !e.usage.sample() {
+		return e.checkPermission(ctx, req)
+	}
+
+	req.WithTracing = true
+
+	resp, err := e.client.CheckPermission(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	setCheckedAt(ctx, resp)
+
+	if resp.Permissionship != pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+		return ErrActionNotAssigned
+	}
+
+	keys := e.roleBindingUsageFromTrace(resp.GetDebugTrace().GetCheck(), subject.ID)
+
+	e.usage.record(keys, time.Now().UTC())
+
+	return nil
+}
+
+// roleBindingUsageFromTrace returns the role bindings through which a traced check was allowed, along
+// with the subject of each role binding which allowed it: either the checked subject or a group it is
+// a member of.
+func (e *engine) roleBindingUsageFromTrace(check *pb.CheckDebugTrace, subjectID gidx.PrefixedID) []roleBindingUsageKey {
+	rbType := e.namespaced(e.rbac.RoleBindingResource.Name)
+
+	var (
+		keys []roleBindingUsageKey
+		walk func(*pb.CheckDebugTrace)
+	)
+
+	walk = func(t *pb.CheckDebugTrace) {
+		if t.GetResult() != pb.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION {
+			return
+		}
+
+		if t.GetResource().GetObjectType() == rbType {
+			rbID, err := gidx.Parse(t.GetResource().GetObjectId())
+			if err != nil {
+				return
+			}
+
+			keys = append(keys, roleBindingUsageKey{
+				roleBindingID: rbID,
+				subjectID:     e.roleBindingSubjectFromTrace(t, subjectID),
+			})
+
+			return
+		}
+
+		for _, sub := range t.GetSubProblems().GetTraces() {
+			walk(sub)
+		}
+	}
+
+	walk(check)
+
+	return keys
+}
+
+// roleBindingSubjectFromTrace returns the group nearest to the role binding in its trace through which
+// the checked subject was allowed, or the checked subject if it is bound directly.
+func (e *engine) roleBindingSubjectFromTrace(rbTrace *pb.CheckDebugTrace, subjectID gidx.PrefixedID) gidx.PrefixedID {
+	roleType := e.namespaced(e.rbac.RoleResource.Name)
+
+	queue := rbTrace.GetSubProblems().GetTraces()
+
+	for i := 0; i < len(queue); i++ {
+		t := queue[i]
+
+		if t.GetResult() != pb.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION || t.GetResource().GetObjectType() == roleType {
+			continue
+		}
+
+		for _, subj := range e.rbac.RoleBindingSubjects {
+			if subj.SubjectRelation == "" ||
+				t.GetResource().GetObjectType() != e.namespaced(subj.Name) ||
+				t.GetPermission() != subj.SubjectRelation {
+				continue
+			}
+
+			if id, err := gidx.Parse(t.GetResource().GetObjectId()); err == nil {
+				return id
+			}
+		}
+
+		queue = append(queue, t.GetSubProblems().GetTraces()...)
+	}
+
+	return subjectID
+}
+
+// RoleBindingUsageSampleRate returns the fraction of allowed permission checks whose role bindings are
+// recorded as used. Only sampled checks are traced to find their role bindings, so usage is recorded
+// probabilistically and rarely used access may not be recorded at all.
+func (e *engine) RoleBindingUsageSampleRate() float64 {
+	if e.usage == nil {
+		return 0
+	}
+
+	return e.usage.sampleRate
+}
+
+// FlushRoleBindingUsage writes the buffered usage of role bindings to storage, returning the number
+// of role binding and subject pairs written. Usage which fails to be written is kept to be retried.
+func (e *engine) FlushRoleBindingUsage(ctx context.Context) (int, error) {
+	if e.usage == nil {
+		return 0, nil
+	}
+
+	ctx, span := e.tracer.Start(ctx, "engine.FlushRoleBindingUsage")
+	defer span.End()
+
+	usage := e.usage.take()
+
+	span.SetAttributes(attribute.Int("usage", len(usage)))
+
+	if len(usage) == 0 {
+		return 0, nil
+	}
+
+	if err := e.store.RecordRoleBindingUsage(ctx, usage); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		e.usage.restore(usage)
+
+		return 0, err
+	}
+
+	return len(usage), nil
+}
+
+// ListUnusedAccess returns the subjects of the role bindings on the resource and the resources beneath it
+// whose use of the role binding was not recorded within unusedFor. Role bindings created within unusedFor
+// are not reported.
+func (e *engine) ListUnusedAccess(ctx context.Context, resource types.Resource, unusedFor time.Duration) ([]types.UnusedAccess, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListUnusedAccess",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.Stringer("unused_for", unusedFor),
+		),
+	)
+	defer span.End()
+
+	unused, err := e.listUnusedAccess(ctx, resource, unusedFor)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return unused, nil
+}
+
+func (e *engine) listUnusedAccess(ctx context.Context, resource types.Resource, unusedFor time.Duration) ([]types.UnusedAccess, error) {
+	if unusedFor <= 0 {
+		return nil, ErrInvalidUnusedPeriod
+	}
+
+	cutoff := time.Now().Add(-unusedFor)
+
+	resources, err := e.resourcesBeneath(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bindings []types.RoleBinding
+		ids      []gidx.PrefixedID
+	)
+
+	for _, res := range resources {
+		rbs, _, err := e.ListRoleBindings(ctx, res, nil, RoleBindingFilter{}, ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rb := range rbs {
+			if rb.CreatedAt.After(cutoff) {
+				continue
+			}
+
+			bindings = append(bindings, rb)
+			ids = append(ids, rb.ID)
+		}
+	}
+
+	usage, err := e.store.ListRoleBindingUsage(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lastUsed := make(map[roleBindingUsageKey]time.Time, len(usage))
+
+	for _, u := range usage {
+		lastUsed[roleBindingUsageKey{roleBindingID: u.RoleBindingID, subjectID: u.SubjectID}] = u.LastUsedAt
+	}
+
+	unused := []types.UnusedAccess{}
+
+	for _, rb := range bindings {
+		for _, subjID := range rb.SubjectIDs {
+			access := types.UnusedAccess{
+				RoleBindingID: rb.ID,
+				ResourceID:    rb.ResourceID,
+				RoleID:        rb.RoleID,
+				SubjectID:     subjID,
+				CreatedAt:     rb.CreatedAt,
+			}
+
+			if last, ok := lastUsed[roleBindingUsageKey{roleBindingID: rb.ID, subjectID: subjID}]; ok {
+				if last.After(cutoff) {
+					continue
+				}
+
+				access.LastUsedAt = &last
+			}
+
+			unused = append(unused, access)
+		}
+	}
+
+	return unused, nil
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRoleBindingUsage(t *testing.T) {
+	namespace := "testrolebindingusage"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	// record the usage of every check
+	e.usage = newRoleBindingUsageBuffer(1)
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user1, err := e.NewResourceFromIDString("idntusr-user1")
+	require.NoError(t, err)
+
+	user2, err := e.NewResourceFromIDString("idntusr-user2")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, root, "", "viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleResource, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	rb, err := e.CreateRoleBinding(ctx, actor, root, roleResource, "", []types.RoleBindingSubject{
+		{SubjectResource: user1}, {SubjectResource: user2},
+	})
+	require.NoError(t, err)
+
+	unused, err := e.ListUnusedAccess(ctx, root, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, unused, "role-bindings created within the period are not reported")
+
+	time.Sleep(time.Second)
+
+	require.NoError(t, e.SubjectHasPermission(ctx, user1, "loadbalancer_get", root))
+
+	flushed, err := e.FlushRoleBindingUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+
+	unused, err = e.ListUnusedAccess(ctx, root, time.Second/2)
+	require.NoError(t, err)
+	require.Len(t, unused, 1)
+	assert.Equal(t, rb.ID, unused[0].RoleBindingID)
+	assert.Equal(t, user2.ID, unused[0].SubjectID)
+	assert.Nil(t, unused[0].LastUsedAt)
+
+	_, err = e.ListUnusedAccess(ctx, root, 0)
+	assert.ErrorIs(t, err, ErrInvalidUnusedPeriod)
+}
+
+func TestRoleBindingUsageSampling(t *testing.T) {
+	disabled := &engine{}
+	WithRoleBindingUsage(0)(disabled)
+
+	assert.Nil(t, disabled.usage)
+	assert.Zero(t, disabled.RoleBindingUsageSampleRate())
+	assert.False(t, disabled.usage.sample(), "usage is not recorded when disabled")
+
+	sampled := &engine{}
+	WithRoleBindingUsage(0.01)(sampled)
+
+	assert.InDelta(t, 0.01, sampled.RoleBindingUsageSampleRate(), 0)
+
+	// only a fraction of the checks are traced and recorded, so the report is probabilistic.
+	var recorded int
+
+	for range 10000 {
+		if sampled.usage.sample() {
+			recorded++
+		}
+	}
+
+	assert.Less(t, recorded, 10000/2)
+
+	always := &engine{}
+	WithRoleBindingUsage(1)(always)
+
+	assert.True(t, always.usage.sample())
+}
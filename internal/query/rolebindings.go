@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"go.infratographer.com/x/gidx"
@@ -16,6 +18,9 @@ import (
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
+// maxRoleBindingsPage is the number of grants read at a time when listing all the role-bindings of a resource.
+const maxRoleBindingsPage = 1000
+
 func (e *engine) GetRoleBinding(ctx context.Context, roleBinding types.Resource) (types.RoleBinding, error) {
 	ctx, span := e.tracer.Start(
 		ctx, "engine.GetRoleBinding",
@@ -35,10 +40,17 @@ func (e *engine) GetRoleBinding(ctx context.Context, roleBinding types.Resource)
 		return types.RoleBinding{}, err
 	}
 
+	return e.loadRoleBindingRelationships(ctx, rb)
+}
+
+// loadRoleBindingRelationships populates the role and subjects of the role-binding from its relationships.
+func (e *engine) loadRoleBindingRelationships(ctx context.Context, rb types.RoleBinding) (types.RoleBinding, error) {
+	span := trace.SpanFromContext(ctx)
+
 	// gather all relationships from this role-binding
 	rbRelFilter := &pb.RelationshipFilter{
 		ResourceType:       e.namespaced(e.rbac.RoleBindingResource.Name),
-		OptionalResourceId: roleBinding.ID.String(),
+		OptionalResourceId: rb.ID.String(),
 	}
 
 	rbRel, err := e.readRelationships(ctx, rbRelFilter)
@@ -104,36 +116,30 @@ func (e *engine) CreateRoleBinding(
 	)
 	defer span.End()
 
-	if err := e.isRoleBindable(ctx, roleResource, resource); err != nil {
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
 		return types.RoleBinding{}, err
 	}
 
-	dbrole, err := e.store.GetRoleByID(ctx, roleResource.ID)
+	existing, ok, err := e.idempotentRoleBinding(dbCtx, actor, resource, roleResource)
 	if err != nil {
-		if errors.Is(err, storage.ErrNoRoleFound) {
-			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
-		}
-
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
 
-	dbCtx, err := e.store.BeginContext(ctx)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+	if ok {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
 
-		return types.RoleBinding{}, nil
+		return existing, nil
 	}
 
-	rbResourceType := e.schemaTypeMap[e.rbac.RoleBindingResource.Name]
-
-	rbid, err := gidx.NewID(rbResourceType.IDPrefix)
+	rb, updates, err := e.prepareCreateRoleBinding(dbCtx, actor, resource, roleResource, manager, subjects)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -142,26 +148,91 @@ func (e *engine) CreateRoleBinding(
 		return types.RoleBinding{}, err
 	}
 
-	rb, err := e.store.CreateRoleBinding(dbCtx, actor.ID, rbid, resource.ID, manager)
-	if err != nil {
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		if err := e.store.SetRoleBindingIdempotencyKey(dbCtx, actor.ID, rb.ID, key); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.RoleBinding{}, err
+		}
+	}
+
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
 
+	return rb, nil
+}
+
+// prepareCreateRoleBinding creates the role-binding within the storage transaction in dbCtx,
+// returning the relationship updates which must be applied before the transaction is committed.
+func (e *engine) prepareCreateRoleBinding(
+	dbCtx context.Context,
+	actor, resource, roleResource types.Resource,
+	manager string,
+	subjects []types.RoleBindingSubject,
+) (types.RoleBinding, []*pb.RelationshipUpdate, error) {
+	if err := e.isRoleBindable(dbCtx, roleResource, resource); err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	if err := e.checkRoleBindingApproval(dbCtx, roleResource); err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	if e.escalationGuardEnabled(dbCtx) {
+		actions, err := e.roleV2EffectiveActions(dbCtx, roleResource.ID)
+		if err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+
+		if err := e.checkPrivilegeEscalation(dbCtx, actor, resource, actions); err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+	}
+
+	subjectResources := make([]types.Resource, len(subjects))
+
+	for i, subj := range subjects {
+		subjectResources[i] = subj.SubjectResource
+	}
+
+	if err := e.checkSeparationOfDuties(dbCtx, resource, roleResource.ID, subjectResources); err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	dbrole, err := e.store.GetRoleByID(dbCtx, roleResource.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
+		}
+
+		return types.RoleBinding{}, nil, err
+	}
+
+	rbResourceType := e.schemaTypeMap[e.rbac.RoleBindingResource.Name]
+
+	rbid, err := gidx.NewID(rbResourceType.IDPrefix)
+	if err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	rb, err := e.store.CreateRoleBinding(dbCtx, actor.ID, rbid, resource.ID, manager)
+	if err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
 	rb.RoleID = dbrole.ID
 
 	roleRel := e.rolebindingRoleRelationship(dbrole.ID.String(), rb.ID.String())
 
 	grantRel, err := e.rolebindingGrantResourceRelationship(resource, rb.ID.String())
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-		return types.RoleBinding{}, err
+		return types.RoleBinding{}, nil, err
 	}
 
 	updates := []*pb.RelationshipUpdate{
@@ -175,46 +246,47 @@ func (e *engine) CreateRoleBinding(
 		},
 	}
 
-	subjUpdates := make([]*pb.RelationshipUpdate, len(subjects))
 	rb.SubjectIDs = make([]gidx.PrefixedID, len(subjects))
 
 	for i, subj := range subjects {
 		rel, err := e.rolebindingSubjectRelationship(subj.SubjectResource, rb.ID.String())
 		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-			return types.RoleBinding{}, err
+			return types.RoleBinding{}, nil, err
 		}
 
 		rb.SubjectIDs[i] = subj.SubjectResource.ID
-		subjUpdates[i] = &pb.RelationshipUpdate{
+
+		updates = append(updates, &pb.RelationshipUpdate{
 			Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
 			Relationship: rel,
-		}
+		})
 	}
 
-	updates = append(updates, subjUpdates...)
+	return rb, updates, nil
+}
 
-	if err := e.applyUpdates(dbCtx, updates); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+// commitRoleBindingUpdates applies the relationship updates and commits the storage transaction in dbCtx.
+// On failure the transaction is rolled back, along with any relationship updates already applied.
+func (e *engine) commitRoleBindingUpdates(ctx, dbCtx context.Context, updates []*pb.RelationshipUpdate) error {
+	if len(updates) != 0 {
+		if err := e.applyUpdates(dbCtx, updates); err != nil {
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
 
-		return types.RoleBinding{}, err
+			return err
+		}
 	}
 
 	if err := e.store.CommitContext(dbCtx); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
 
-		return types.RoleBinding{}, err
+		if len(updates) != 0 {
+			logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		}
+
+		return err
 	}
 
-	return rb, nil
+	return nil
 }
 
 func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error {
@@ -234,7 +306,8 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 		return err
 	}
 
-	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
+	updates, err := e.prepareDeleteRoleBinding(dbCtx, rb)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
@@ -242,37 +315,48 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 		return err
 	}
 
-	rbFromDB, err := e.store.GetRoleBindingByID(dbCtx, rb.ID)
-	if err != nil {
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
 		return err
 	}
 
-	res, err := e.NewResourceFromID(rbFromDB.ResourceID)
+	return nil
+}
+
+// prepareDeleteRoleBinding deletes the role-binding within the storage transaction in dbCtx,
+// returning the relationship updates which must be applied before the transaction is committed.
+func (e *engine) prepareDeleteRoleBinding(dbCtx context.Context, rb types.Resource) ([]*pb.RelationshipUpdate, error) {
+	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
+		return nil, err
+	}
+
+	rbFromDB, err := e.store.GetRoleBindingByID(dbCtx, rb.ID)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
-		return err
+	if err := checkVersionPrecondition(dbCtx, rbFromDB.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	res, err := e.NewResourceFromID(rbFromDB.ResourceID)
+	if err != nil {
+		return nil, err
 	}
 
 	// gather all relationships from the role-binding resource
-	fromRels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+	fromRels, err := e.readRelationships(dbCtx, &pb.RelationshipFilter{
 		ResourceType:       e.namespaced(e.rbac.RoleBindingResource.Name),
 		OptionalResourceId: rb.ID.String(),
 	})
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-		return err
+		return nil, err
 	}
 
 	// gather relationships to the role-binding
-	toRels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+	toRels, err := e.readRelationships(dbCtx, &pb.RelationshipFilter{
 		ResourceType:     e.namespaced(res.Type),
 		OptionalRelation: iapl.GrantRelationship,
 		OptionalSubjectFilter: &pb.SubjectFilter{
@@ -281,11 +365,7 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 		},
 	})
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-		return err
+		return nil, err
 	}
 
 	// create a list of delete updates for these relationships
@@ -298,37 +378,17 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 		}
 	}
 
-	// apply changes
-	if err := e.applyUpdates(dbCtx, updates); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-		return err
-	}
-
 	if err := e.store.DeleteRoleBinding(dbCtx, rb.ID); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
-
-		return err
-	}
-
-	if err := e.store.CommitContext(dbCtx); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
-
-		return err
+		return nil, err
 	}
 
-	return nil
+	return updates, nil
 }
 
-func (e *engine) ListRoleBindings(ctx context.Context, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error) {
+func (e *engine) ListRoleBindings(
+	ctx context.Context, resource types.Resource,
+	optionalRole *types.Resource, filter RoleBindingFilter, opts ListOptions,
+) ([]types.RoleBinding, string, error) {
 	ctx, span := e.tracer.Start(
 		ctx, "engine.ListRoleBinding",
 		trace.WithAttributes(
@@ -339,10 +399,13 @@ func (e *engine) ListRoleBindings(ctx context.Context, resource types.Resource,
 
 	e.logger.Debugf("listing role-bindings for resource: %s, optionalRole: %v", resource.ID, optionalRole)
 
-	return e.listRoleBindings(ctx, resource, optionalRole, nil)
+	return e.listRoleBindings(ctx, resource, optionalRole, nil, filter, opts)
 }
 
-func (e *engine) ListManagerRoleBindings(ctx context.Context, manager string, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error) {
+func (e *engine) ListManagerRoleBindings(
+	ctx context.Context, manager string, resource types.Resource,
+	optionalRole *types.Resource, filter RoleBindingFilter, opts ListOptions,
+) ([]types.RoleBinding, string, error) {
 	ctx, span := e.tracer.Start(
 		ctx, "engine.ListManagerRoleBinding",
 		trace.WithAttributes(
@@ -354,13 +417,78 @@ func (e *engine) ListManagerRoleBindings(ctx context.Context, manager string, re
 
 	e.logger.Debugf("listing manager %s role-bindings for resource: %s, optionalRole: %v", manager, resource.ID, optionalRole)
 
-	return e.listRoleBindings(ctx, resource, optionalRole, &manager)
+	return e.listRoleBindings(ctx, resource, optionalRole, &manager, filter, opts)
 }
 
-func (e *engine) listRoleBindings(ctx context.Context, resource types.Resource, optionalRole *types.Resource, optionalManager *string) ([]types.RoleBinding, error) {
+// listRoleBindings returns a page of the role-bindings granted on the resource which match
+// the optional role, optional manager and filter. Pages follow the grants of the resource in
+// SpiceDB, and role-bindings not matching are excluded from the page, so pages may contain
+// fewer role-bindings than the limit.
+func (e *engine) listRoleBindings(
+	ctx context.Context, resource types.Resource,
+	optionalRole *types.Resource, optionalManager *string, filter RoleBindingFilter, opts ListOptions,
+) ([]types.RoleBinding, string, error) {
 	span := trace.SpanFromContext(ctx)
 
-	// 1. list all grants on the resource
+	if err := e.validateFilterAction(filter.Action); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	cur, err := opts.cursor()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	if opts.Limit > 0 {
+		bindings, nextToken, err := e.listRoleBindingsPage(ctx, resource, optionalRole, optionalManager, filter, opts.Limit, cur.Token)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, "", err
+		}
+
+		return bindings, cursor{Token: nextToken}.encode(), nil
+	}
+
+	// without a limit, all role-bindings are listed in pages to bound the size of each storage query
+	bindings := []types.RoleBinding{}
+	token := cur.Token
+
+	for {
+		page, nextToken, err := e.listRoleBindingsPage(ctx, resource, optionalRole, optionalManager, filter, maxRoleBindingsPage, token)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, "", err
+		}
+
+		bindings = append(bindings, page...)
+
+		if nextToken == "" {
+			return bindings, "", nil
+		}
+
+		token = nextToken
+	}
+}
+
+// listRoleBindingsPage returns the role-bindings matching the optional role, optional manager
+// and filter among a page of up to limit grants on the resource, starting from the SpiceDB
+// cursor token. The token of the following page is returned, or an empty token after the last page.
+func (e *engine) listRoleBindingsPage(
+	ctx context.Context, resource types.Resource,
+	optionalRole *types.Resource, optionalManager *string, filter RoleBindingFilter,
+	limit int, token string,
+) ([]types.RoleBinding, string, error) {
+	// 1. list a page of the grants on the resource
 	listRbFilter := &pb.RelationshipFilter{
 		ResourceType:       e.namespaced(resource.Type),
 		OptionalResourceId: resource.ID.String(),
@@ -370,47 +498,39 @@ func (e *engine) listRoleBindings(ctx context.Context, resource types.Resource,
 		},
 	}
 
-	grantRel, err := e.readRelationships(ctx, listRbFilter)
+	grantRel, nextToken, err := e.readRelationshipsPage(ctx, listRbFilter, limit, token)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-
-		return nil, err
+		return nil, "", err
 	}
 
-	// 2. fetch role-binding details for each grant
-	bindings := make([]types.RoleBinding, 0, len(grantRel))
-	errs := make([]error, 0, len(grantRel))
+	rbIDs := make([]gidx.PrefixedID, 0, len(grantRel))
 
 	for _, rel := range grantRel {
-		rbRes, err := e.NewResourceFromIDString(rel.Subject.Object.ObjectId)
+		id, err := gidx.Parse(rel.Subject.Object.ObjectId)
 		if err != nil {
-			errs = append(errs, err)
-			continue
+			return nil, "", err
 		}
 
-		rb, err := e.GetRoleBinding(ctx, rbRes)
-		if err != nil {
-			if errors.Is(err, ErrRoleBindingNotFound) {
-				// print and record a warning message when there's a grant points
-				// to a role-binding that not longer exists.
-				//
-				// this should not happen in normal circumstances, but it's possible
-				// if some role-binding relationships are deleted directly through
-				// spiceDB
-				err := fmt.Errorf("%w: dangling grant relationship: %s", err, rel.String())
+		rbIDs = append(rbIDs, id)
+	}
 
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
-				e.logger.Warnf(err.Error())
-			}
+	// 2. fetch the role-bindings of the page matching the storage filters
+	storageRbs, err := e.store.ListRoleBindingsByID(ctx, rbIDs, filter.storageFilter(optionalManager), storage.ListOptions{})
+	if err != nil {
+		return nil, "", err
+	}
 
-			errs = append(errs, err)
+	// 3. fetch the role and subjects of each role-binding, keeping those matching the role, subject and action
+	bindings := make([]types.RoleBinding, 0, len(storageRbs))
+	roleActions := map[gidx.PrefixedID]bool{}
 
-			continue
+	for _, rb := range storageRbs {
+		rb, err := e.loadRoleBindingRelationships(ctx, rb)
+		if err != nil {
+			return nil, "", err
 		}
 
-		if optionalManager != nil && rb.Manager != *optionalManager {
+		if len(rb.SubjectIDs) == 0 {
 			continue
 		}
 
@@ -418,23 +538,253 @@ func (e *engine) listRoleBindings(ctx context.Context, resource types.Resource,
 			continue
 		}
 
-		if len(rb.SubjectIDs) == 0 {
+		if filter.SubjectID != "" && !slices.Contains(rb.SubjectIDs, filter.SubjectID) {
 			continue
 		}
 
+		if filter.Action != "" {
+			allowed, ok := roleActions[rb.RoleID]
+			if !ok {
+				actions, err := e.roleV2EffectiveActions(ctx, rb.RoleID)
+				if err != nil {
+					return nil, "", err
+				}
+
+				allowed = slices.Contains(actions, filter.Action)
+				roleActions[rb.RoleID] = allowed
+			}
+
+			if !allowed {
+				continue
+			}
+		}
+
 		bindings = append(bindings, rb)
 	}
 
-	for _, err := range errs {
+	return bindings, nextToken, nil
+}
+
+func (e *engine) ListSubjectRoleBindings(ctx context.Context, subject types.Resource, opts ListOptions) ([]types.SubjectRoleBinding, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListSubjectRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", subject.ID),
+		),
+	)
+	defer span.End()
+
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	// 1. find the role-bindings including the subject, directly or through its groups
+	rbGroups, err := e.subjectRoleBindingIDs(ctx, subject)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	rbIDs := make([]gidx.PrefixedID, 0, len(rbGroups))
+
+	for id := range rbGroups {
+		rbIDs = append(rbIDs, id)
+	}
+
+	// 2. fetch a page of the role-bindings from the store
+	storageRbs, err := e.store.ListRoleBindingsByID(ctx, rbIDs, storage.RoleBindingFilter{}, storageOpts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	if len(storageRbs) == 0 {
+		return []types.SubjectRoleBinding{}, "", nil
+	}
+
+	nextCursor := opts.nextIDCursor(len(storageRbs), storageRbs[len(storageRbs)-1].ID)
+
+	// 3. fetch the role, subjects and resource of each role-binding
+	bindings := make([]types.SubjectRoleBinding, 0, len(storageRbs))
+	roleIDs := make([]gidx.PrefixedID, 0, len(storageRbs))
+
+	for _, rb := range storageRbs {
+		rb, err := e.loadRoleBindingRelationships(ctx, rb)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, "", err
+		}
+
+		resource, err := e.NewResourceFromID(rb.ResourceID)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 
+			return nil, "", err
+		}
+
+		bindings = append(bindings, types.SubjectRoleBinding{
+			RoleBinding: rb,
+			Resource:    resource,
+			GroupIDs:    rbGroups[rb.ID],
+		})
+
+		roleIDs = append(roleIDs, rb.RoleID)
+	}
+
+	roles, err := e.store.BatchGetRoleByID(ctx, roleIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	rolesByID := make(map[gidx.PrefixedID]storage.Role, len(roles))
+
+	for _, role := range roles {
+		rolesByID[role.ID] = role
+	}
+
+	for i, rb := range bindings {
+		role := rolesByID[rb.RoleID]
+
+		bindings[i].Role = types.Role{
+			ID:         role.ID,
+			Name:       role.Name,
+			Manager:    role.Manager,
+			ResourceID: role.ResourceID,
+			CreatedBy:  role.CreatedBy,
+			UpdatedBy:  role.UpdatedBy,
+			CreatedAt:  role.CreatedAt,
+			UpdatedAt:  role.UpdatedAt,
+		}
+	}
+
+	return bindings, nextCursor, nil
+}
+
+// subjectRoleBindingIDs returns the IDs of the role-bindings which include the subject,
+// mapped to the groups through which the subject is included.
+// Role-bindings including the subject directly map to the groups it's also included through, if any.
+func (e *engine) subjectRoleBindingIDs(ctx context.Context, subject types.Resource) (map[gidx.PrefixedID][]gidx.PrefixedID, error) {
+	rbGroups := map[gidx.PrefixedID][]gidx.PrefixedID{}
+
+	addRoleBindings := func(subj types.Resource, viaGroup bool) error {
+		filter := &pb.RelationshipFilter{
+			ResourceType:     e.namespaced(e.rbac.RoleBindingResource.Name),
+			OptionalRelation: iapl.RolebindingSubjectRelation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       e.namespaced(subj.Type),
+				OptionalSubjectId: subj.ID.String(),
+			},
+		}
+
+		relationships, err := e.readRelationships(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range relationships {
+			rbID, err := gidx.Parse(rel.Resource.ObjectId)
+			if err != nil {
+				return err
+			}
+
+			if viaGroup {
+				rbGroups[rbID] = append(rbGroups[rbID], subj.ID)
+			} else if _, ok := rbGroups[rbID]; !ok {
+				rbGroups[rbID] = nil
+			}
+		}
+
+		return nil
+	}
+
+	if _, ok := e.rolebindingSubjectsMap[subject.Type]; ok {
+		if err := addRoleBindings(subject, false); err != nil {
 			return nil, err
 		}
 	}
 
-	return bindings, nil
+	// groups are role-binding subjects through a subject relation, like group#member
+	for _, subjConf := range e.rbac.RoleBindingSubjects {
+		if subjConf.SubjectRelation == "" {
+			continue
+		}
+
+		groupIDs, err := e.lookupResourceIDs(ctx, subjConf.Name, subjConf.SubjectRelation, subject)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, groupID := range groupIDs {
+			group := types.Resource{Type: subjConf.Name, ID: groupID}
+
+			if err := addRoleBindings(group, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rbGroups, nil
+}
+
+// lookupResourceIDs returns the IDs of the resources of the given type on which the subject has the permission.
+func (e *engine) lookupResourceIDs(ctx context.Context, resourceType, permission string, subject types.Resource) ([]gidx.PrefixedID, error) {
+	return e.lookupResourceIDsForRef(ctx, resourceType, permission, resourceToSpiceDBRef(e.namespace, subject))
+}
+
+// lookupResourceIDsForRef returns the IDs of the resources of the given type on which the
+// referenced object has the permission.
+func (e *engine) lookupResourceIDsForRef(ctx context.Context, resourceType, permission string, subject *pb.ObjectReference) ([]gidx.PrefixedID, error) {
+	lookupClient, err := e.client.LookupResources(ctx, &pb.LookupResourcesRequest{
+		Consistency: &pb.Consistency{
+			Requirement: &pb.Consistency_FullyConsistent{
+				FullyConsistent: true,
+			},
+		},
+		ResourceObjectType: e.namespaced(resourceType),
+		Permission:         permission,
+		Subject: &pb.SubjectReference{
+			Object: subject,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []gidx.PrefixedID{}
+
+	for {
+		lookup, err := lookupClient.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := gidx.Parse(lookup.ResourceObjectId)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
 }
 
 func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource, subjects []types.RoleBindingSubject) (types.RoleBinding, error) {
@@ -454,7 +804,8 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 		return types.RoleBinding{}, err
 	}
 
-	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
+	rolebinding, updates, err := e.prepareUpdateRoleBinding(dbCtx, actor, rb, subjects)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
@@ -462,15 +813,35 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 		return types.RoleBinding{}, err
 	}
 
-	rolebinding, err := e.GetRoleBinding(dbCtx, rb)
-	if err != nil {
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
 
+	return rolebinding, nil
+}
+
+// prepareUpdateRoleBinding updates the subjects of the role-binding within the storage transaction in dbCtx,
+// returning the relationship updates which must be applied before the transaction is committed.
+// If the subjects are unchanged, the role-binding is returned without any updates.
+func (e *engine) prepareUpdateRoleBinding(
+	dbCtx context.Context, actor, rb types.Resource, subjects []types.RoleBindingSubject,
+) (types.RoleBinding, []*pb.RelationshipUpdate, error) {
+	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	rolebinding, err := e.GetRoleBinding(dbCtx, rb)
+	if err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
+	if err := checkVersionPrecondition(dbCtx, rolebinding.UpdatedAt); err != nil {
+		return types.RoleBinding{}, nil, err
+	}
+
 	// 1. find the subjects to add or remove
 	current := make([]string, len(rolebinding.SubjectIDs))
 	incoming := make([]string, len(subjects))
@@ -489,20 +860,58 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 
 	// return if there are no changes
 	if (len(add) + len(remove)) == 0 {
-		return rolebinding, nil
+		return rolebinding, nil, nil
 	}
 
-	// 2. create relationship updates
+	// 2. ensure the added subjects don't need approval, escalate the actor's privileges
+	// or violate separation of duties constraints
+	if len(add) != 0 {
+		role, err := e.NewResourceFromID(rolebinding.RoleID)
+		if err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+
+		if err := e.checkRoleBindingApproval(dbCtx, role); err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+
+		resource, err := e.NewResourceFromID(rolebinding.ResourceID)
+		if err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+
+		if e.escalationGuardEnabled(dbCtx) {
+			actions, err := e.roleV2EffectiveActions(dbCtx, rolebinding.RoleID)
+			if err != nil {
+				return types.RoleBinding{}, nil, err
+			}
+
+			if err := e.checkPrivilegeEscalation(dbCtx, actor, resource, actions); err != nil {
+				return types.RoleBinding{}, nil, err
+			}
+		}
+
+		addSubjects := make([]types.Resource, len(add))
+
+		for i, id := range add {
+			addSubjects[i], err = e.NewResourceFromIDString(id)
+			if err != nil {
+				return types.RoleBinding{}, nil, err
+			}
+		}
+
+		if err := e.checkSeparationOfDuties(dbCtx, resource, rolebinding.RoleID, addSubjects); err != nil {
+			return types.RoleBinding{}, nil, err
+		}
+	}
+
+	// 3. create relationship updates
 	updates := make([]*pb.RelationshipUpdate, 0, len(add)+len(remove))
 
 	for _, id := range add {
 		update, err := e.rolebindingRelationshipUpdateForSubject(id, rb.ID.String(), pb.RelationshipUpdate_OPERATION_TOUCH)
 		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-			return types.RoleBinding{}, err
+			return types.RoleBinding{}, nil, err
 		}
 
 		updates = append(updates, update)
@@ -511,47 +920,23 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	for _, id := range remove {
 		update, err := e.rolebindingRelationshipUpdateForSubject(id, rb.ID.String(), pb.RelationshipUpdate_OPERATION_DELETE)
 		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-			return types.RoleBinding{}, err
+			return types.RoleBinding{}, nil, err
 		}
 
 		updates = append(updates, update)
 	}
 
-	if err := e.applyUpdates(dbCtx, updates); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-
-		return types.RoleBinding{}, err
-	}
-
-	// 3. update the role-binding in the database to record latest `updatedBy` and `updatedAt`
+	// 4. update the role-binding in the database to record latest `updatedBy` and `updatedAt`
 	rbFromDB, err := e.store.UpdateRoleBinding(dbCtx, actor.ID, rb.ID)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
-	}
-
-	if err := e.store.CommitContext(dbCtx); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
-
-		return types.RoleBinding{}, err
+		return types.RoleBinding{}, nil, err
 	}
 
 	rolebinding.SubjectIDs = newSubjectIDs
 	rolebinding.UpdatedAt = rbFromDB.UpdatedAt
 	rolebinding.UpdatedBy = rbFromDB.UpdatedBy
 
-	return rolebinding, nil
+	return rolebinding, updates, nil
 }
 
 func (e *engine) GetRoleBindingResource(ctx context.Context, rb types.Resource) (types.Resource, error) {
@@ -0,0 +1,139 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// RoleBindingOp is the kind of change a role-binding operation makes.
+type RoleBindingOp string
+
+const (
+	// RoleBindingOpCreate creates a role-binding for Role on Resource with the given Subjects.
+	RoleBindingOpCreate RoleBindingOp = "create"
+	// RoleBindingOpUpdate replaces the subjects of RoleBinding with the given Subjects.
+	RoleBindingOpUpdate RoleBindingOp = "update"
+	// RoleBindingOpDelete deletes RoleBinding.
+	RoleBindingOpDelete RoleBindingOp = "delete"
+)
+
+// RoleBindingOperation is a single change applied by BatchRoleBindings.
+type RoleBindingOperation struct {
+	Op RoleBindingOp
+
+	// Resource, Role and Manager are used by create operations.
+	Resource types.Resource
+	Role     types.Resource
+	Manager  string
+
+	// RoleBinding is the role-binding changed by update and delete operations.
+	RoleBinding types.Resource
+
+	// Subjects are the role-binding subjects for create and update operations.
+	Subjects []types.RoleBindingSubject
+}
+
+func (e *engine) BatchRoleBindings(ctx context.Context, actor types.Resource, ops []RoleBindingOperation) ([]types.RoleBinding, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.BatchRoleBindings",
+		trace.WithAttributes(
+			attribute.Int("operations", len(ops)),
+		),
+	)
+	defer span.End()
+
+	// a role-binding may only be changed once per batch, as its relationship updates
+	// are computed from the relationships which exist before the batch is applied.
+	changed := make(map[gidx.PrefixedID]struct{}, len(ops))
+
+	for i, op := range ops {
+		switch op.Op {
+		case RoleBindingOpCreate:
+			continue
+		case RoleBindingOpUpdate, RoleBindingOpDelete:
+		default:
+			err := fmt.Errorf("%w: operation %d: unknown operation %q", ErrInvalidArgument, i, op.Op)
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		if _, ok := changed[op.RoleBinding.ID]; ok {
+			err := fmt.Errorf("%w: operation %d: role-binding %s changed more than once", ErrInvalidArgument, i, op.RoleBinding.ID)
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		changed[op.RoleBinding.ID] = struct{}{}
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	// the operations are checked against the grants of the operations before them, as
+	// none of the batch is visible in SpiceDB until it is written.
+	opCtx := contextWithBatchGrants(dbCtx)
+
+	rbs := make([]types.RoleBinding, len(ops))
+	updates := []*pb.RelationshipUpdate{}
+
+	for i, op := range ops {
+		var (
+			rb        types.RoleBinding
+			opUpdates []*pb.RelationshipUpdate
+			opErr     error
+		)
+
+		switch op.Op {
+		case RoleBindingOpCreate:
+			rb, opUpdates, opErr = e.prepareCreateRoleBinding(opCtx, actor, op.Resource, op.Role, op.Manager, op.Subjects)
+		case RoleBindingOpUpdate:
+			rb, opUpdates, opErr = e.prepareUpdateRoleBinding(opCtx, actor, op.RoleBinding, op.Subjects)
+		case RoleBindingOpDelete:
+			rb = types.RoleBinding{ID: op.RoleBinding.ID}
+			opUpdates, opErr = e.prepareDeleteRoleBinding(opCtx, op.RoleBinding)
+		}
+
+		if opErr != nil {
+			err := fmt.Errorf("operation %d: %w", i, opErr)
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return nil, err
+		}
+
+		rbs[i] = rb
+		updates = append(updates, opUpdates...)
+	}
+
+	// all relationship updates are written with a single request, so either all
+	// or none of them are applied.
+	if err := e.commitRoleBindingUpdates(ctx, dbCtx, updates); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return rbs, nil
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestBatchRoleBindings(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	editor, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_editor", []string{"loadbalancer_update"})
+	require.NoError(t, err)
+	editorRes, err := e.NewResourceFromID(editor.ID)
+	require.NoError(t, err)
+
+	toUpdate, err := e.CreateRoleBinding(ctx, actor, root, viewerRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+	toUpdateRes, err := e.NewResourceFromID(toUpdate.ID)
+	require.NoError(t, err)
+
+	toDelete, err := e.CreateRoleBinding(ctx, actor, root, editorRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+	toDeleteRes, err := e.NewResourceFromID(toDelete.ID)
+	require.NoError(t, err)
+
+	notfoundRB, err := e.NewResourceFromIDString("permrbn-notfound")
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[[]RoleBindingOperation, []types.RoleBinding]{
+		{
+			Name: "DuplicateRoleBinding",
+			Input: []RoleBindingOperation{
+				{Op: RoleBindingOpUpdate, RoleBinding: toUpdateRes, Subjects: []types.RoleBindingSubject{{SubjectResource: subj}}},
+				{Op: RoleBindingOpDelete, RoleBinding: toUpdateRes},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidArgument)
+			},
+			Sync: true,
+		},
+		{
+			Name: "RollbackOnFailure",
+			Input: []RoleBindingOperation{
+				{Op: RoleBindingOpCreate, Resource: root, Role: viewerRes, Manager: t.Name(), Subjects: []types.RoleBindingSubject{{SubjectResource: subj}}},
+				{Op: RoleBindingOpDelete, RoleBinding: notfoundRB},
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, storage.ErrRoleBindingNotFound)
+
+				rbs, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
+				require.NoError(t, err)
+				assert.Len(t, rbs, 2)
+			},
+			Sync: true,
+		},
+		{
+			Name: "Success",
+			Input: []RoleBindingOperation{
+				{Op: RoleBindingOpCreate, Resource: root, Role: viewerRes, Manager: t.Name(), Subjects: []types.RoleBindingSubject{{SubjectResource: subj}}},
+				{Op: RoleBindingOpUpdate, RoleBinding: toUpdateRes, Subjects: []types.RoleBindingSubject{{SubjectResource: subj}}},
+				{Op: RoleBindingOpDelete, RoleBinding: toDeleteRes},
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 3)
+
+				assert.Equal(t, viewer.ID, res.Success[0].RoleID)
+				assert.Equal(t, toUpdate.ID, res.Success[1].ID)
+				assert.Equal(t, toDelete.ID, res.Success[2].ID)
+
+				updated, err := e.GetRoleBinding(ctx, toUpdateRes)
+				require.NoError(t, err)
+				require.Len(t, updated.SubjectIDs, 1)
+				assert.Equal(t, subj.ID, updated.SubjectIDs[0])
+
+				rbs, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
+				require.NoError(t, err)
+				assert.Len(t, rbs, 2)
+			},
+			Sync: true,
+		},
+	}
+
+	testFn := func(ctx context.Context, ops []RoleBindingOperation) testingx.TestResult[[]types.RoleBinding] {
+		rbs, err := e.BatchRoleBindings(ctx, actor, ops)
+		return testingx.TestResult[[]types.RoleBinding]{Success: rbs, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
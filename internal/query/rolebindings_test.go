@@ -7,6 +7,7 @@ import (
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/storage"
@@ -105,7 +106,7 @@ func TestCreateRoleBinding(t *testing.T) {
 				assert.Equal(t, role.ID, res.Success.RoleID)
 				assert.Len(t, res.Success.SubjectIDs, 1)
 
-				rb, err := e.ListRoleBindings(ctx, child, nil)
+				rb, _, err := e.ListRoleBindings(ctx, child, nil, RoleBindingFilter{}, ListOptions{})
 				assert.NoError(t, err)
 				assert.Len(t, rb, 1)
 			},
@@ -137,7 +138,7 @@ func TestCreateRoleBinding(t *testing.T) {
 				assert.Equal(t, subj.ID, res.Success.SubjectIDs[0])
 				assert.Equal(t, actor.ID, res.Success.CreatedBy)
 
-				rbs, err := e.ListRoleBindings(ctx, root, nil)
+				rbs, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
 				assert.NoError(t, err)
 				assert.Len(t, rbs, 1)
 			},
@@ -251,11 +252,40 @@ func TestListRoleBindings(t *testing.T) {
 	}
 
 	testFn := func(ctx context.Context, in input) testingx.TestResult[[]types.RoleBinding] {
-		rb, err := e.ListRoleBindings(ctx, in.resource, in.role)
+		rb, _, err := e.ListRoleBindings(ctx, in.resource, in.role, RoleBindingFilter{}, ListOptions{})
 		return testingx.TestResult[[]types.RoleBinding]{Success: rb, Err: err}
 	}
 
 	testingx.RunTests(ctx, t, tc, testFn)
+
+	t.Run("PagesWithFilter", func(t *testing.T) {
+		var (
+			rbs    []types.RoleBinding
+			cursor string
+			pages  int
+		)
+
+		filter := RoleBindingFilter{SubjectID: subj.ID, Action: "loadbalancer_update"}
+
+		for {
+			page, next, err := e.ListRoleBindings(ctx, root, nil, filter, ListOptions{Limit: 1, Cursor: cursor})
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page), 1)
+
+			rbs = append(rbs, page...)
+			pages++
+
+			if next == "" {
+				break
+			}
+
+			cursor = next
+		}
+
+		assert.GreaterOrEqual(t, pages, 2, "each page reads a single grant")
+		require.Len(t, rbs, 1)
+		assert.Equal(t, editor.ID, rbs[0].RoleID)
+	})
 }
 
 func TestGetRoleBinding(t *testing.T) {
@@ -445,7 +475,7 @@ func TestDeleteRoleBinding(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
 				assert.ErrorIs(t, res.Err, storage.ErrRoleBindingNotFound)
 
-				rb, err := e.ListRoleBindings(ctx, root, nil)
+				rb, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
 				assert.NoError(t, err)
 				assert.Len(t, rb, 1)
 			},
@@ -457,7 +487,7 @@ func TestDeleteRoleBinding(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
 				assert.NoError(t, res.Err)
 
-				rb, err := e.ListRoleBindings(ctx, root, nil)
+				rb, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
 				assert.NoError(t, err)
 				assert.Len(t, rb, 0)
 			},
@@ -560,7 +590,7 @@ func TestPermissions(t *testing.T) {
 				assert.NoError(t, err)
 			},
 			CleanupFn: func(ctx context.Context) {
-				rbs, _ := e.ListRoleBindings(ctx, lb1, nil)
+				rbs, _, _ := e.ListRoleBindings(ctx, lb1, nil, RoleBindingFilter{}, ListOptions{})
 				for _, rb := range rbs {
 					rbRes, _ := e.NewResourceFromID(rb.ID)
 					_ = e.DeleteRoleBinding(ctx, rbRes)
@@ -594,7 +624,7 @@ func TestPermissions(t *testing.T) {
 				assert.NoError(t, err)
 			},
 			CleanupFn: func(ctx context.Context) {
-				rbs, _ := e.ListRoleBindings(ctx, child, nil)
+				rbs, _, _ := e.ListRoleBindings(ctx, child, nil, RoleBindingFilter{}, ListOptions{})
 				for _, rb := range rbs {
 					rbRes, _ := e.NewResourceFromID(rb.ID)
 					_ = e.DeleteRoleBinding(ctx, rbRes)
@@ -628,7 +658,7 @@ func TestPermissions(t *testing.T) {
 				assert.NoError(t, err)
 			},
 			CleanupFn: func(ctx context.Context) {
-				rbs, _ := e.ListRoleBindings(ctx, root, nil)
+				rbs, _, _ := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{}, ListOptions{})
 				for _, rb := range rbs {
 					rbRes, _ := e.NewResourceFromID(rb.ID)
 					_ = e.DeleteRoleBinding(ctx, rbRes)
@@ -769,3 +799,160 @@ func TestPermissions(t *testing.T) {
 
 	testingx.RunTests(ctx, t, tc, testFn)
 }
+
+func TestListSubjectRoleBindings(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, namespace),
+	})
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	user1, err := e.NewResourceFromIDString("idntusr-user1")
+	require.NoError(t, err)
+	user2, err := e.NewResourceFromIDString("idntusr-user2")
+	require.NoError(t, err)
+	group1, err := e.NewResourceFromIDString("idntgrp-group1")
+	require.NoError(t, err)
+
+	err = e.CreateRelationships(ctx, []types.Relationship{{
+		Resource: group1,
+		Relation: "member",
+		Subject:  user1,
+	}})
+	require.NoError(t, err)
+
+	direct, err := e.CreateRoleBinding(ctx, actor, root, viewerRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: user1}})
+	require.NoError(t, err)
+
+	viaGroup, err := e.CreateRoleBinding(ctx, actor, child, viewerRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: group1}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[types.Resource, []types.SubjectRoleBinding]{
+		{
+			Name:  "DirectAndGroup",
+			Input: user1,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.SubjectRoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+
+				byID := map[gidx.PrefixedID]types.SubjectRoleBinding{}
+				for _, rb := range res.Success {
+					byID[rb.ID] = rb
+				}
+
+				require.Contains(t, byID, direct.ID)
+				assert.Equal(t, root, byID[direct.ID].Resource)
+				assert.Equal(t, viewer.Name, byID[direct.ID].Role.Name)
+				assert.Empty(t, byID[direct.ID].GroupIDs)
+
+				require.Contains(t, byID, viaGroup.ID)
+				assert.Equal(t, child, byID[viaGroup.ID].Resource)
+				assert.Equal(t, []gidx.PrefixedID{group1.ID}, byID[viaGroup.ID].GroupIDs)
+			},
+		},
+		{
+			Name:  "Group",
+			Input: group1,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.SubjectRoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 1)
+				assert.Equal(t, viaGroup.ID, res.Success[0].ID)
+			},
+		},
+		{
+			Name:  "NoRoleBindings",
+			Input: user2,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.SubjectRoleBinding]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[[]types.SubjectRoleBinding] {
+		rbs, _, err := e.ListSubjectRoleBindings(ctx, in, ListOptions{})
+
+		return testingx.TestResult[[]types.SubjectRoleBinding]{Success: rbs, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestEffectiveActions(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	user1, err := e.NewResourceFromIDString("idntusr-user1")
+	require.NoError(t, err)
+	user2, err := e.NewResourceFromIDString("idntusr-user2")
+	require.NoError(t, err)
+
+	lb1, err := e.NewResourceFromIDString("loadbal-lb1")
+	require.NoError(t, err)
+
+	err = e.CreateRelationships(ctx, []types.Relationship{{
+		Resource: lb1,
+		Relation: "owner",
+		Subject:  root,
+	}})
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, root, viewerRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: user1}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[types.Resource, []string]{
+		{
+			Name:  "BoundSubject",
+			Input: user1,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]string]) {
+				require.NoError(t, res.Err)
+
+				assert.Contains(t, res.Success, "loadbalancer_get")
+				assert.Contains(t, res.Success, "loadbalancer_list")
+				assert.NotContains(t, res.Success, "loadbalancer_update")
+			},
+		},
+		{
+			Name:  "UnboundSubject",
+			Input: user2,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]string]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[[]string] {
+		actions, err := e.EffectiveActions(ctx, in, lb1)
+
+		return testingx.TestResult[[]string]{Success: actions, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
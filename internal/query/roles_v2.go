@@ -18,6 +18,10 @@ import (
 
 // V2 Role and Role Bindings
 
+// roleActionLookupSubjectID is the subject ID used to look up the roles which allow an
+// action. Any ID works, since role actions are granted to all subjects of a type.
+const roleActionLookupSubjectID = "role-action-lookup"
+
 func (e *engine) namespaced(name string) string {
 	return e.namespace + "/" + name
 }
@@ -46,7 +50,22 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
-		return types.Role{}, nil
+		return types.Role{}, err
+	}
+
+	existing, ok, err := e.idempotentRoleV2(dbCtx, actor, owner, roleName)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	if ok {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return existing, nil
 	}
 
 	dbRole, err := e.store.CreateRole(dbCtx, actor.ID, role.ID, roleName, manager, owner.ID)
@@ -58,6 +77,16 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 		return types.Role{}, err
 	}
 
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		if err := e.store.SetRoleIdempotencyKey(dbCtx, actor.ID, role.ID, key); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.Role{}, err
+		}
+	}
+
 	request := &pb.WriteRelationshipsRequest{Updates: roleRels}
 
 	if _, err := e.client.WriteRelationships(ctx, request); err != nil {
@@ -93,7 +122,7 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 	return role, nil
 }
 
-func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types.Role, error) {
+func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource, filter RoleFilter, opts ListOptions) ([]types.Role, string, error) {
 	ctx, span := e.tracer.Start(
 		ctx,
 		"engine.ListRolesV2",
@@ -106,12 +135,52 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 	)
 	defer span.End()
 
+	return e.listRolesV2(ctx, owner, nil, filter, opts)
+}
+
+func (e *engine) ListManagerRolesV2(ctx context.Context, manager string, owner types.Resource, filter RoleFilter, opts ListOptions) ([]types.Role, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"engine.ListManagerRolesV2",
+		trace.WithAttributes(
+			attribute.Stringer("owner", owner.ID),
+			attribute.String("manager", manager),
+		),
+	)
+	defer span.End()
+
+	return e.listRolesV2(ctx, owner, &manager, filter, opts)
+}
+
+// listRolesV2 returns a page of the v2 roles available to the owner which match the filter
+// and optional manager, ordered by ID.
+func (e *engine) listRolesV2(
+	ctx context.Context, owner types.Resource,
+	optionalManager *string, filter RoleFilter, opts ListOptions,
+) ([]types.Role, string, error) {
+	span := trace.SpanFromContext(ctx)
+
 	if _, ok := e.rbac.RoleOwnersSet()[owner.Type]; !ok {
 		err := fmt.Errorf("%w: %s is not a valid role owner", ErrInvalidType, owner.Type)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		return nil, err
+		return nil, "", err
+	}
+
+	if err := e.validateFilterAction(filter.Action); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	storageOpts, err := opts.storageOptions()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
 	}
 
 	lookupClient, err := e.client.LookupSubjects(ctx, &pb.LookupSubjectsRequest{
@@ -128,7 +197,7 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		return nil, err
+		return nil, "", err
 	}
 
 	roleIDs := []gidx.PrefixedID{}
@@ -155,12 +224,24 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 		roleIDs = append(roleIDs, id)
 	}
 
-	storageRoles, err := e.store.BatchGetRoleByID(ctx, roleIDs)
+	if filter.Action != "" {
+		roleIDs, err = e.rolesWithAction(ctx, roleIDs, filter.Action)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, "", err
+		}
+	}
+
+	// LookupSubjects doesn't support cursors, so the available role IDs are filtered and
+	// paged by the store, which orders the page by ID.
+	storageRoles, err := e.store.ListRolesByID(ctx, roleIDs, filter.storageFilter(optionalManager), storageOpts)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		return nil, err
+		return nil, "", err
 	}
 
 	roles := make([]types.Role, len(storageRoles))
@@ -178,97 +259,47 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 		}
 	}
 
-	return roles, nil
-}
+	if len(roles) == 0 {
+		return roles, "", nil
+	}
 
-func (e *engine) ListManagerRolesV2(ctx context.Context, manager string, owner types.Resource) ([]types.Role, error) {
-	ctx, span := e.tracer.Start(
-		ctx,
-		"engine.ListManagerRolesV2",
-		trace.WithAttributes(
-			attribute.Stringer("owner", owner.ID),
-			attribute.String("manager", manager),
-		),
-	)
-	defer span.End()
+	return roles, opts.nextIDCursor(len(roles), roles[len(roles)-1].ID), nil
+}
 
-	if _, ok := e.rbac.RoleOwnersSet()[owner.Type]; !ok {
-		err := fmt.Errorf("%w: %s is not a valid role owner", ErrInvalidType, owner.Type)
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+// rolesWithAction returns the given role IDs which allow the action, either directly or
+// through the roles they include. Role actions are granted to every subject through
+// wildcard relationships, so a single lookup of the action's role permission for any
+// concrete subject returns all roles which allow it, without walking each role's includes.
+func (e *engine) rolesWithAction(ctx context.Context, roleIDs []gidx.PrefixedID, action string) ([]gidx.PrefixedID, error) {
+	if len(e.rbac.RoleSubjectTypes) == 0 || len(roleIDs) == 0 {
+		return []gidx.PrefixedID{}, nil
+	}
 
-		return nil, err
+	subject := &pb.ObjectReference{
+		ObjectType: e.namespaced(e.rbac.RoleSubjectTypes[0]),
+		ObjectId:   roleActionLookupSubjectID,
 	}
 
-	lookupClient, err := e.client.LookupSubjects(ctx, &pb.LookupSubjectsRequest{
-		Consistency: &pb.Consistency{
-			Requirement: &pb.Consistency_FullyConsistent{
-				FullyConsistent: true,
-			},
-		},
-		Resource:          resourceToSpiceDBRef(e.namespace, owner),
-		Permission:        iapl.AvailableRolesList,
-		SubjectObjectType: e.namespaced(e.rbac.RoleResource.Name),
-	})
+	allowed, err := e.lookupResourceIDsForRef(ctx, e.rbac.RoleResource.Name, action+iapl.RolePermissionSuffix, subject)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-
 		return nil, err
 	}
 
-	roleIDs := []gidx.PrefixedID{}
+	allowedSet := make(map[gidx.PrefixedID]struct{}, len(allowed))
 
-	for {
-		lookup, err := lookupClient.Recv()
-		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
-			}
-
-			break
-		}
-
-		id, err := gidx.Parse(lookup.Subject.SubjectObjectId)
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-
-			continue
-		}
-
-		roleIDs = append(roleIDs, id)
-	}
-
-	storageRoles, err := e.store.BatchGetRoleByID(ctx, roleIDs)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-
-		return nil, err
+	for _, id := range allowed {
+		allowedSet[id] = struct{}{}
 	}
 
-	roles := make([]types.Role, 0, len(storageRoles))
+	withAction := make([]gidx.PrefixedID, 0, len(roleIDs))
 
-	for _, r := range storageRoles {
-		if r.Manager != manager {
-			continue
+	for _, id := range roleIDs {
+		if _, ok := allowedSet[id]; ok {
+			withAction = append(withAction, id)
 		}
-
-		roles = append(roles, types.Role{
-			Name:       r.Name,
-			Manager:    r.Manager,
-			ID:         r.ID,
-			ResourceID: r.ResourceID,
-			CreatedBy:  r.CreatedBy,
-			UpdatedBy:  r.UpdatedBy,
-			CreatedAt:  r.CreatedAt,
-			UpdatedAt:  r.UpdatedAt,
-		})
 	}
 
-	return roles, nil
+	return withAction, nil
 }
 
 func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role, error) {
@@ -288,7 +319,7 @@ func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role
 		return types.Role{}, err
 	}
 
-	// 1. Get role actions from spice DB
+	// 1. Get role actions and included roles from spice DB
 
 	actions, err := e.listRoleV2Actions(ctx, types.Role{ID: role.ID})
 	if err != nil {
@@ -298,6 +329,14 @@ func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role
 		return types.Role{}, err
 	}
 
+	includes, err := e.listRoleV2Includes(ctx, role.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
 	// 2. Get role info (name, created_by, etc.) from permissions API DB
 	dbrole, err := e.getStorageRole(ctx, role)
 	if err != nil {
@@ -308,10 +347,11 @@ func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role
 	}
 
 	resp := types.Role{
-		ID:      dbrole.ID,
-		Name:    dbrole.Name,
-		Manager: dbrole.Manager,
-		Actions: actions,
+		ID:       dbrole.ID,
+		Name:     dbrole.Name,
+		Manager:  dbrole.Manager,
+		Actions:  actions,
+		Includes: includes,
 
 		ResourceID: dbrole.ResourceID,
 		CreatedBy:  dbrole.CreatedBy,
@@ -354,6 +394,15 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		return types.Role{}, err
 	}
 
+	if err := checkVersionPrecondition(ctx, role.UpdatedAt); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
 	if newName == "" {
 		newName = role.Name
 	}
@@ -372,6 +421,24 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		return role, nil
 	}
 
+	if err := e.checkRoleV2Escalation(dbCtx, actor, role, addActions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	if err := e.checkRoleUpdateApproval(dbCtx, role, addActions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
 	// 1. update role in permissions-api DB
 	dbRole, err := e.store.UpdateRole(dbCtx, actor.ID, role.ID, newName)
 	if err != nil {
@@ -498,6 +565,33 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		return err
 	}
 
+	// find all the roles which include the role
+	findIncludedByFilter := &pb.RelationshipFilter{
+		ResourceType:     e.namespaced(e.rbac.RoleResource.Name),
+		OptionalRelation: iapl.RoleIncludeRelation,
+		OptionalSubjectFilter: &pb.SubjectFilter{
+			SubjectType:       e.namespaced(e.rbac.RoleResource.Name),
+			OptionalSubjectId: roleResource.ID.String(),
+		},
+	}
+
+	includedBy, err := e.readRelationships(dbCtx, findIncludedByFilter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	// reject delete if role is included by other roles
+	if len(includedBy) > 0 {
+		err := fmt.Errorf("%w: cannot delete role included by other roles", ErrDeleteRoleInUse)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	dbRole, err := e.getStorageRole(dbCtx, roleResource)
 	if err != nil {
 		span.RecordError(err)
@@ -506,6 +600,15 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		return err
 	}
 
+	if err := checkVersionPrecondition(ctx, dbRole.UpdatedAt); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
 	roleOwner, err := e.NewResourceFromID(dbRole.ResourceID)
 	if err != nil {
 		span.RecordError(err)
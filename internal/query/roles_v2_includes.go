@@ -0,0 +1,269 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// UpdateRoleV2Includes replaces the roles included by a V2 role. A role grants its own
+// actions and the actions of every role it includes, directly or transitively. Included
+// roles must be available to the role's owner and must not include the role itself.
+func (e *engine) UpdateRoleV2Includes(ctx context.Context, actor, roleResource types.Resource, includes []types.Resource) (types.Role, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"engine.UpdateRoleV2Includes",
+		trace.WithAttributes(
+			attribute.Stringer("permissions.role_id", roleResource.ID),
+			attribute.Int("permissions.includes", len(includes)),
+		),
+	)
+	defer span.End()
+
+	role, err := e.updateRoleV2Includes(ctx, actor, roleResource, includes)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (e *engine) updateRoleV2Includes(ctx context.Context, actor, roleResource types.Resource, includes []types.Resource) (types.Role, error) {
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return types.Role{}, err
+	}
+
+	if err := e.lockRoleForUpdate(dbCtx, roleResource); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, fmt.Errorf("failed to lock role: %s: %w", roleResource.ID, err)
+	}
+
+	role, err := e.GetRoleV2(dbCtx, roleResource)
+	if err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	if err := checkVersionPrecondition(ctx, role.UpdatedAt); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	if err := e.validateRoleV2Includes(ctx, role, includes); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	current := make([]string, len(role.Includes))
+
+	for i, id := range role.Includes {
+		current[i] = id.String()
+	}
+
+	incoming := make([]string, len(includes))
+	includeIDs := make([]gidx.PrefixedID, len(includes))
+
+	for i, include := range includes {
+		incoming[i] = include.ID.String()
+		includeIDs[i] = include.ID
+	}
+
+	addIncludes, rmIncludes := diff(current, incoming, true)
+
+	// If no changes, return existing role
+	if len(addIncludes) == 0 && len(rmIncludes) == 0 {
+		if err := e.store.CommitContext(dbCtx); err != nil {
+			return types.Role{}, err
+		}
+
+		return role, nil
+	}
+
+	if len(addIncludes) != 0 {
+		addIDs := make([]gidx.PrefixedID, len(addIncludes))
+
+		for i, id := range addIncludes {
+			addIDs[i] = gidx.PrefixedID(id)
+		}
+
+		actions, err := e.roleV2EffectiveActions(dbCtx, addIDs...)
+		if err != nil {
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.Role{}, err
+		}
+
+		if err := e.checkRoleV2Escalation(dbCtx, actor, role, actions); err != nil {
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.Role{}, err
+		}
+
+		if err := e.checkRoleUpdateApproval(dbCtx, role, actions); err != nil {
+			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+			return types.Role{}, err
+		}
+	}
+
+	// 1. record the update in permissions-api DB
+	dbRole, err := e.store.UpdateRole(dbCtx, actor.ID, role.ID, role.Name)
+	if err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	// 2. update include relationships in SpiceDB
+	roleRef := resourceToSpiceDBRef(e.namespace, roleResource)
+	updates := make([]*pb.RelationshipUpdate, 0, len(addIncludes)+len(rmIncludes))
+
+	for _, id := range rmIncludes {
+		updates = append(updates, e.roleV2IncludeRelationshipUpdate(roleRef, id, pb.RelationshipUpdate_OPERATION_DELETE))
+	}
+
+	for _, id := range addIncludes {
+		updates = append(updates, e.roleV2IncludeRelationshipUpdate(roleRef, id, pb.RelationshipUpdate_OPERATION_TOUCH))
+	}
+
+	if _, err := e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: updates}); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+
+		// At this point, SpiceDB changes have already been applied.
+		// Attempting to rollback could result in failures that could result in the same situation.
+		//
+		// TODO: add SpiceDB rollback logic along with rollback failure scenarios.
+
+		return types.Role{}, err
+	}
+
+	role.Includes = includeIDs
+	role.UpdatedBy = dbRole.UpdatedBy
+	role.UpdatedAt = dbRole.UpdatedAt
+
+	return role, nil
+}
+
+// validateRoleV2Includes ensures the included roles are V2 roles available to the role's
+// owner, and that none of them includes the role, directly or transitively.
+func (e *engine) validateRoleV2Includes(ctx context.Context, role types.Role, includes []types.Resource) error {
+	owner, err := e.NewResourceFromID(role.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	for _, include := range includes {
+		if include.Type != e.rbac.RoleResource.Name {
+			return fmt.Errorf("%w: %s is not a valid v2 Role", ErrInvalidArgument, include.ID)
+		}
+
+		if include.ID == role.ID {
+			return fmt.Errorf("%w: role %s includes itself", ErrRoleIncludeCycle, role.ID)
+		}
+
+		if err := e.isRoleBindable(ctx, include, owner); err != nil {
+			return err
+		}
+	}
+
+	// walk the roles reachable from the included roles, looking for the role itself.
+	visited := make(map[gidx.PrefixedID]struct{}, len(includes))
+	queue := make([]gidx.PrefixedID, 0, len(includes))
+
+	for _, include := range includes {
+		queue = append(queue, include.ID)
+	}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if _, ok := visited[id]; ok {
+			continue
+		}
+
+		visited[id] = struct{}{}
+
+		next, err := e.listRoleV2Includes(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, nextID := range next {
+			if nextID == role.ID {
+				return fmt.Errorf("%w: role %s is included by %s", ErrRoleIncludeCycle, role.ID, id)
+			}
+
+			queue = append(queue, nextID)
+		}
+	}
+
+	return nil
+}
+
+// listRoleV2Includes returns the IDs of the roles directly included by a V2 role.
+func (e *engine) listRoleV2Includes(ctx context.Context, roleID gidx.PrefixedID) ([]gidx.PrefixedID, error) {
+	filter := &pb.RelationshipFilter{
+		ResourceType:       e.namespaced(e.rbac.RoleResource.Name),
+		OptionalResourceId: roleID.String(),
+		OptionalRelation:   iapl.RoleIncludeRelation,
+	}
+
+	relationships, err := e.readRelationships(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]gidx.PrefixedID, 0, len(relationships))
+
+	for _, rel := range relationships {
+		id, err := gidx.Parse(rel.Subject.Object.ObjectId)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// roleV2IncludeRelationshipUpdate creates a relationship update between a V2 role and a role it includes,
+// i.e., rolev2:editor#include@rolev2:viewer
+func (e *engine) roleV2IncludeRelationshipUpdate(roleRef *pb.ObjectReference, includeID string, op pb.RelationshipUpdate_Operation) *pb.RelationshipUpdate {
+	return &pb.RelationshipUpdate{
+		Operation: op,
+		Relationship: &pb.Relationship{
+			Resource: roleRef,
+			Relation: iapl.RoleIncludeRelation,
+			Subject: &pb.SubjectReference{
+				Object: &pb.ObjectReference{
+					ObjectType: e.namespaced(e.rbac.RoleResource.Name),
+					ObjectId:   includeID,
+				},
+			},
+		},
+	}
+}
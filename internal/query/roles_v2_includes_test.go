@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestUpdateRoleV2Includes(t *testing.T) {
+	namespace := "testroleincludes"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	other, err := e.NewResourceFromIDString("tnntten-other")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	newRole := func(owner types.Resource, name string, actions ...string) types.Resource {
+		role, err := e.CreateRoleV2(ctx, actor, owner, "", name, actions)
+		require.NoError(t, err)
+
+		res, err := e.NewResourceFromID(role.ID)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	viewer := newRole(root, "viewer", "role_get")
+	editor := newRole(root, "editor", "role_update")
+	admin := newRole(root, "admin", "role_delete")
+	foreign := newRole(other, "foreign", "role_get")
+
+	_, err = e.CreateRoleBinding(ctx, actor, root, editor, "", []types.RoleBindingSubject{{SubjectResource: user}})
+	require.NoError(t, err)
+
+	checkRoleGet := func(ctx context.Context) error {
+		return e.checkPermission(ctx, &pb.CheckPermissionRequest{
+			Consistency: &pb.Consistency{Requirement: &pb.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    resourceToSpiceDBRef(namespace, root),
+			Permission:  "role_get",
+			Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user)},
+		})
+	}
+
+	type input struct {
+		role     types.Resource
+		includes []types.Resource
+	}
+
+	tc := []testingx.TestCase[input, types.Role]{
+		{
+			Name:  "IncludeSelf",
+			Input: input{role: editor, includes: []types.Resource{editor}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, ErrRoleIncludeCycle)
+			},
+		},
+		{
+			Name:  "IncludeUnavailableRole",
+			Input: input{role: editor, includes: []types.Resource{foreign}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, ErrRoleNotFound)
+			},
+		},
+		{
+			Name:  "IncludeNotARole",
+			Input: input{role: editor, includes: []types.Resource{user}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidArgument)
+			},
+		},
+		{
+			Name:  "IncludeGrantsActions",
+			Input: input{role: editor, includes: []types.Resource{viewer}},
+			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
+				require.Error(t, checkRoleGet(ctx))
+
+				return ctx
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []string{"role_update"}, res.Success.Actions)
+				assert.Len(t, res.Success.Includes, 1)
+				assert.Equal(t, viewer.ID, res.Success.Includes[0])
+
+				assert.NoError(t, checkRoleGet(ctx))
+
+				role, err := e.GetRoleV2(ctx, editor)
+				require.NoError(t, err)
+				assert.Len(t, role.Includes, 1)
+
+				// filtering by action matches roles including a role with the action
+				roles, _, err := e.ListRolesV2(ctx, root, RoleFilter{Action: "role_get"}, ListOptions{})
+				require.NoError(t, err)
+
+				roleIDs := make([]string, len(roles))
+				for i, r := range roles {
+					roleIDs[i] = r.ID.String()
+				}
+
+				assert.ElementsMatch(t, []string{viewer.ID.String(), editor.ID.String()}, roleIDs)
+
+				rbs, _, err := e.ListRoleBindings(ctx, root, nil, RoleBindingFilter{Action: "role_get"}, ListOptions{})
+				require.NoError(t, err)
+				require.Len(t, rbs, 1)
+				assert.Equal(t, editor.ID, rbs[0].RoleID)
+			},
+			Sync: true,
+		},
+		{
+			Name:  "IncludeCycle",
+			Input: input{role: viewer, includes: []types.Resource{admin}},
+			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
+				// editor -> viewer is set above, admin -> editor makes viewer -> admin a cycle.
+				_, err := e.UpdateRoleV2Includes(ctx, actor, admin, []types.Resource{editor})
+				require.NoError(t, err)
+
+				return ctx
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, ErrRoleIncludeCycle)
+			},
+			Sync: true,
+		},
+		{
+			Name:  "DeleteIncludedRole",
+			Input: input{role: editor, includes: []types.Resource{viewer}},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				require.NoError(t, res.Err)
+
+				assert.ErrorIs(t, e.DeleteRoleV2(ctx, viewer), ErrDeleteRoleInUse)
+			},
+			Sync: true,
+		},
+		{
+			Name:  "RemoveIncludes",
+			Input: input{role: editor, includes: []types.Resource{}},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success.Includes)
+
+				assert.Error(t, checkRoleGet(ctx))
+			},
+			Sync: true,
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[types.Role] {
+		role, err := e.UpdateRoleV2Includes(ctx, actor, in.role, in.includes)
+
+		return testingx.TestResult[types.Role]{Success: role, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
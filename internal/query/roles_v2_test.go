@@ -3,10 +3,12 @@ package query
 import (
 	"context"
 	"testing"
+	"time"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -278,7 +280,7 @@ func TestListRolesV2(t *testing.T) {
 	}
 
 	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[[]types.Role] {
-		roles, err := e.ListRolesV2(ctx, in)
+		roles, _, err := e.ListRolesV2(ctx, in, RoleFilter{}, ListOptions{})
 		if err != nil {
 			return testingx.TestResult[[]types.Role]{Err: err}
 		}
@@ -289,6 +291,88 @@ func TestListRolesV2(t *testing.T) {
 	testingx.RunTests(ctx, t, tc, testFn)
 }
 
+func TestListRolesV2Filter(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	editor, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_editor", []string{"loadbalancer_list", "loadbalancer_get", "loadbalancer_update"})
+	require.NoError(t, err)
+
+	lead, err := e.CreateRoleV2(ctx, actor, root, t.Name(), "lb_lead", []string{"loadbalancer_list"})
+	require.NoError(t, err)
+
+	editorRes, err := e.NewResourceFromID(editor.ID)
+	require.NoError(t, err)
+
+	leadRes, err := e.NewResourceFromID(lead.ID)
+	require.NoError(t, err)
+
+	_, err = e.UpdateRoleV2Includes(ctx, actor, leadRes, []types.Resource{editorRes})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[RoleFilter, []types.Role]{
+		{
+			Name:  "NamePrefix",
+			Input: RoleFilter{NamePrefix: "lb_v"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 1)
+				assert.Equal(t, viewer.ID, res.Success[0].ID)
+			},
+		},
+		{
+			Name:  "Action",
+			Input: RoleFilter{Action: "loadbalancer_update"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+				assert.ElementsMatch(t, []gidx.PrefixedID{editor.ID, lead.ID}, []gidx.PrefixedID{res.Success[0].ID, res.Success[1].ID})
+			},
+		},
+		{
+			Name:  "InvalidAction",
+			Input: RoleFilter{Action: "not_an_action"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidAction)
+			},
+		},
+		{
+			Name:  "CreatedBy",
+			Input: RoleFilter{CreatedBy: "idntusr-someoneelse"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				assert.Len(t, res.Success, 0)
+			},
+		},
+		{
+			Name:  "CreatedAfter",
+			Input: RoleFilter{CreatedAfter: editor.CreatedAt.Add(time.Hour)},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Role]) {
+				require.NoError(t, res.Err)
+				assert.Len(t, res.Success, 0)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in RoleFilter) testingx.TestResult[[]types.Role] {
+		roles, _, err := e.ListRolesV2(ctx, root, in, ListOptions{})
+
+		return testingx.TestResult[[]types.Role]{Success: roles, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
 func TestUpdateRolesV2(t *testing.T) {
 	namespace := "testroles"
 	ctx := context.Background()
@@ -442,15 +526,15 @@ func TestDeleteRolesV2(t *testing.T) {
 	rbTheOtherChild, err := e.CreateRoleBinding(ctx, actor, theotherchild, roleRes, t.Name(), []types.RoleBindingSubject{{SubjectResource: subj}})
 	require.NoError(t, err)
 
-	rb, err := e.ListRoleBindings(ctx, root, &roleRes)
+	rb, _, err := e.ListRoleBindings(ctx, root, &roleRes, RoleBindingFilter{}, ListOptions{})
 	require.NoError(t, err)
 	require.Len(t, rb, 1)
 
-	rb, err = e.ListRoleBindings(ctx, child, &roleRes)
+	rb, _, err = e.ListRoleBindings(ctx, child, &roleRes, RoleBindingFilter{}, ListOptions{})
 	require.NoError(t, err)
 	require.Len(t, rb, 1)
 
-	rb, err = e.ListRoleBindings(ctx, theotherchild, &roleRes)
+	rb, _, err = e.ListRoleBindings(ctx, theotherchild, &roleRes, RoleBindingFilter{}, ListOptions{})
 	require.NoError(t, err)
 	require.Len(t, rb, 1)
 
@@ -0,0 +1,315 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+type batchGrantsCtxKey struct{}
+
+// batchGrants are the actions granted to subjects on resources by the operations of a batch
+// which are prepared but not yet written to SpiceDB, keyed by resource ID and subject ID.
+type batchGrants map[gidx.PrefixedID]map[gidx.PrefixedID]map[string]struct{}
+
+// contextWithBatchGrants returns a context which records the grants checked for separation
+// of duties, so that later operations of the same batch are checked against them.
+func contextWithBatchGrants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchGrantsCtxKey{}, batchGrants{})
+}
+
+// batchGrantsFromContext returns the grants recorded by the context, or nil if the context
+// does not belong to a batch.
+func batchGrantsFromContext(ctx context.Context) batchGrants {
+	grants, _ := ctx.Value(batchGrantsCtxKey{}).(batchGrants)
+
+	return grants
+}
+
+// add records the actions as granted to the subjects on the resource.
+func (g batchGrants) add(resourceID gidx.PrefixedID, subjects []types.Resource, actions map[string]struct{}) {
+	if g == nil {
+		return
+	}
+
+	bySubject, ok := g[resourceID]
+	if !ok {
+		bySubject = make(map[gidx.PrefixedID]map[string]struct{}, len(subjects))
+		g[resourceID] = bySubject
+	}
+
+	for _, subject := range subjects {
+		held, ok := bySubject[subject.ID]
+		if !ok {
+			held = make(map[string]struct{}, len(actions))
+			bySubject[subject.ID] = held
+		}
+
+		for action := range actions {
+			held[action] = struct{}{}
+		}
+	}
+}
+
+// checkSeparationOfDuties ensures that granting the role's actions to the subjects on the
+// resource does not leave any subject holding actions from more than one set of a separation
+// of duties constraint. Only constraints containing the granted actions are checked. Within a
+// batch, actions granted on the resource by earlier operations count as held.
+func (e *engine) checkSeparationOfDuties(ctx context.Context, resource types.Resource, roleID gidx.PrefixedID, subjects []types.Resource) error {
+	if len(e.rbac.SeparationOfDuties) == 0 || len(subjects) == 0 {
+		return nil
+	}
+
+	granted, err := e.roleV2EffectiveActions(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	grantedSet := make(map[string]struct{}, len(granted))
+
+	for _, action := range granted {
+		grantedSet[action] = struct{}{}
+	}
+
+	pending := batchGrantsFromContext(ctx)
+
+	if err := e.checkSeparationOfDutiesGrant(ctx, resource, grantedSet, subjects, pending); err != nil {
+		return err
+	}
+
+	pending.add(resource.ID, subjects, grantedSet)
+
+	return nil
+}
+
+func (e *engine) checkSeparationOfDutiesGrant(
+	ctx context.Context, resource types.Resource,
+	grantedSet map[string]struct{}, subjects []types.Resource, pending batchGrants,
+) error {
+	constraints := make([]iapl.SeparationOfDutiesConstraint, 0, len(e.rbac.SeparationOfDuties))
+
+	for _, constraint := range e.rbac.SeparationOfDuties {
+		if actions, _ := separationOfDutiesViolation(e.rbac.SeparationOfDutiesSets(constraint), grantedSet); len(actions) != 0 {
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	checkActions := e.separationOfDutiesActions(resource, constraints)
+
+	for _, subject := range subjects {
+		held, err := e.subjectActions(ctx, subject, resource, checkActions)
+		if err != nil {
+			return err
+		}
+
+		for action := range pending[resource.ID][subject.ID] {
+			held[action] = struct{}{}
+		}
+
+		for action := range grantedSet {
+			held[action] = struct{}{}
+		}
+
+		for _, constraint := range constraints {
+			if actions, ok := separationOfDutiesViolation(e.rbac.SeparationOfDutiesSets(constraint), held); ok {
+				return fmt.Errorf(
+					"%w: %s: subject %s would hold %s on %s",
+					ErrSeparationOfDutiesViolation, constraint.Name, subject.ID, strings.Join(actions, ", "), resource.ID,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListSeparationOfDutiesViolations returns the separation of duties constraints violated by
+// the subjects of the role-bindings granted on the resource.
+func (e *engine) ListSeparationOfDutiesViolations(ctx context.Context, resource types.Resource) ([]types.SeparationOfDutiesViolation, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListSeparationOfDutiesViolations",
+		trace.WithAttributes(attribute.Stringer("resource_id", resource.ID)),
+	)
+	defer span.End()
+
+	violations := []types.SeparationOfDutiesViolation{}
+
+	if len(e.rbac.SeparationOfDuties) == 0 {
+		return violations, nil
+	}
+
+	rbs, _, err := e.listRoleBindings(ctx, resource, nil, nil, RoleBindingFilter{}, ListOptions{})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	checkActions := e.separationOfDutiesActions(resource, e.rbac.SeparationOfDuties)
+	seen := make(map[gidx.PrefixedID]struct{})
+
+	for _, rb := range rbs {
+		for _, subjectID := range rb.SubjectIDs {
+			if _, ok := seen[subjectID]; ok {
+				continue
+			}
+
+			seen[subjectID] = struct{}{}
+
+			subject, err := e.NewResourceFromID(subjectID)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return nil, err
+			}
+
+			held, err := e.subjectActions(ctx, subject, resource, checkActions)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return nil, err
+			}
+
+			for _, constraint := range e.rbac.SeparationOfDuties {
+				if actions, ok := separationOfDutiesViolation(e.rbac.SeparationOfDutiesSets(constraint), held); ok {
+					violations = append(violations, types.SeparationOfDutiesViolation{
+						Constraint: constraint.Name,
+						SubjectID:  subjectID,
+						ResourceID: resource.ID,
+						Actions:    actions,
+					})
+				}
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("violations", len(violations)))
+
+	return violations, nil
+}
+
+// separationOfDutiesActions returns the actions of the constraints which are defined for the
+// resource's type.
+func (e *engine) separationOfDutiesActions(resource types.Resource, constraints []iapl.SeparationOfDutiesConstraint) []string {
+	var actions []string
+
+	seen := make(map[string]struct{})
+
+	for _, constraint := range constraints {
+		for _, set := range e.rbac.SeparationOfDutiesSets(constraint) {
+			for _, action := range set {
+				if _, ok := seen[action]; ok {
+					continue
+				}
+
+				seen[action] = struct{}{}
+
+				if e.validateResourceActions(resource, action) == nil {
+					actions = append(actions, action)
+				}
+			}
+		}
+	}
+
+	return actions
+}
+
+// subjectActions returns which of the actions the subject can perform on the resource,
+// using a single fully consistent bulk check. Subjects with a subject relation, such as
+// groups, are checked through that relation.
+func (e *engine) subjectActions(ctx context.Context, subject, resource types.Resource, actions []string) (map[string]struct{}, error) {
+	held := make(map[string]struct{}, len(actions))
+
+	if len(actions) == 0 {
+		return held, nil
+	}
+
+	subjectRef := &pb.SubjectReference{
+		Object:           resourceToSpiceDBRef(e.namespace, subject),
+		OptionalRelation: e.rolebindingSubjectsMap[subject.Type].SubjectRelation,
+	}
+
+	req := &pb.CheckBulkPermissionsRequest{
+		Consistency: &pb.Consistency{
+			Requirement: &pb.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		Items: make([]*pb.CheckBulkPermissionsRequestItem, len(actions)),
+	}
+
+	for i, action := range actions {
+		req.Items[i] = &pb.CheckBulkPermissionsRequestItem{
+			Resource:   resourceToSpiceDBRef(e.namespace, resource),
+			Permission: action,
+			Subject:    subjectRef,
+		}
+	}
+
+	resp, err := e.client.CheckBulkPermissions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range resp.Pairs {
+		if pairErr := pair.GetError(); pairErr != nil {
+			return nil, status.ErrorProto(pairErr)
+		}
+
+		if pair.GetItem().GetPermissionship() == pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+			held[pair.GetRequest().GetPermission()] = struct{}{}
+		}
+	}
+
+	return held, nil
+}
+
+// separationOfDutiesViolation reports whether the held actions include actions from more
+// than one of the sets, returning the held actions of those sets.
+func separationOfDutiesViolation(sets [][]string, held map[string]struct{}) ([]string, bool) {
+	var (
+		actions []string
+		hit     int
+	)
+
+	seen := make(map[string]struct{})
+
+	for _, set := range sets {
+		hitSet := false
+
+		for _, action := range set {
+			if _, ok := held[action]; !ok {
+				continue
+			}
+
+			hitSet = true
+
+			if _, ok := seen[action]; !ok {
+				seen[action] = struct{}{}
+
+				actions = append(actions, action)
+			}
+		}
+
+		if hitSet {
+			hit++
+		}
+	}
+
+	return actions, hit > 1
+}
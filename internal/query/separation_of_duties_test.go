@@ -0,0 +1,178 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func separationOfDutiesTestPolicy() iapl.Policy {
+	doc := DefaultPolicyDocumentV2()
+	doc.RBAC.SeparationOfDuties = []iapl.SeparationOfDutiesConstraint{
+		{
+			Name:       "update_delete",
+			ActionSets: [][]string{{"role_update"}, {"role_delete"}},
+		},
+	}
+
+	p := iapl.NewPolicy(doc)
+
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestSeparationOfDuties(t *testing.T) {
+	namespace := "testseparationofduties"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, separationOfDutiesTestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	other, err := e.NewResourceFromIDString("idntusr-other")
+	require.NoError(t, err)
+
+	newRole := func(name string, actions ...string) types.Resource {
+		role, err := e.CreateRoleV2(ctx, actor, root, "", name, actions)
+		require.NoError(t, err)
+
+		res, err := e.NewResourceFromID(role.ID)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	editor := newRole("editor", "role_get", "role_update")
+	deleter := newRole("deleter", "role_delete")
+	admin := newRole("admin", "role_update", "role_delete")
+
+	subjects := func(subjs ...types.Resource) []types.RoleBindingSubject {
+		out := make([]types.RoleBindingSubject, len(subjs))
+
+		for i, subj := range subjs {
+			out[i] = types.RoleBindingSubject{SubjectResource: subj}
+		}
+
+		return out
+	}
+
+	_, err = e.CreateRoleBinding(ctx, actor, root, editor, "", subjects(user))
+	require.NoError(t, err)
+
+	deleterRB, err := e.CreateRoleBinding(ctx, actor, root, deleter, "", subjects(other))
+	require.NoError(t, err)
+
+	deleterRBResource, err := e.NewResourceFromID(deleterRB.ID)
+	require.NoError(t, err)
+
+	t.Run("CreateRoleBinding", func(t *testing.T) {
+		type input struct {
+			role    types.Resource
+			subject types.Resource
+		}
+
+		tc := []testingx.TestCase[input, types.RoleBinding]{
+			{
+				Name:  "ExclusiveWithExistingBinding",
+				Input: input{role: deleter, subject: user},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.ErrorIs(t, res.Err, ErrSeparationOfDutiesViolation)
+					assert.ErrorIs(t, res.Err, ErrInvalidArgument)
+					assert.ErrorContains(t, res.Err, "update_delete")
+				},
+			},
+			{
+				Name:  "ExclusiveWithinRole",
+				Input: input{role: admin, subject: actor},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.ErrorIs(t, res.Err, ErrSeparationOfDutiesViolation)
+				},
+			},
+			{
+				Name:  "NoConflict",
+				Input: input{role: editor, subject: actor},
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+					assert.NoError(t, res.Err)
+				},
+			},
+		}
+
+		testFn := func(ctx context.Context, in input) testingx.TestResult[types.RoleBinding] {
+			rb, err := e.CreateRoleBinding(ctx, actor, root, in.role, "", subjects(in.subject))
+
+			return testingx.TestResult[types.RoleBinding]{Success: rb, Err: err}
+		}
+
+		testingx.RunTests(ctx, t, tc, testFn)
+	})
+
+	t.Run("UpdateRoleBinding", func(t *testing.T) {
+		_, err := e.UpdateRoleBinding(ctx, actor, deleterRBResource, subjects(other, user))
+		assert.ErrorIs(t, err, ErrSeparationOfDutiesViolation)
+
+		rb, err := e.GetRoleBinding(ctx, deleterRBResource)
+		require.NoError(t, err)
+		assert.Len(t, rb.SubjectIDs, 1)
+	})
+
+	t.Run("BatchRoleBindings", func(t *testing.T) {
+		batchUser, err := e.NewResourceFromIDString("idntusr-batchuser")
+		require.NoError(t, err)
+
+		batchOther, err := e.NewResourceFromIDString("idntusr-batchother")
+		require.NoError(t, err)
+
+		// neither operation conflicts with the bindings which exist before the batch, but
+		// together they grant both sets of the constraint to the same subject.
+		_, err = e.BatchRoleBindings(ctx, actor, []RoleBindingOperation{
+			{Op: RoleBindingOpCreate, Resource: root, Role: editor, Subjects: subjects(batchUser)},
+			{Op: RoleBindingOpCreate, Resource: root, Role: deleter, Subjects: subjects(batchUser)},
+		})
+		assert.ErrorIs(t, err, ErrSeparationOfDutiesViolation)
+		assert.ErrorContains(t, err, "operation 1")
+
+		_, err = e.BatchRoleBindings(ctx, actor, []RoleBindingOperation{
+			{Op: RoleBindingOpCreate, Resource: root, Role: editor, Subjects: subjects(batchUser)},
+			{Op: RoleBindingOpCreate, Resource: root, Role: deleter, Subjects: subjects(batchOther)},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("ListSeparationOfDutiesViolations", func(t *testing.T) {
+		violations, err := e.ListSeparationOfDutiesViolations(ctx, root)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+
+		// bind the conflicting role as if the constraint had been added to the policy afterwards.
+		constraints := e.rbac.SeparationOfDuties
+		e.rbac.SeparationOfDuties = nil
+
+		_, err = e.UpdateRoleBinding(ctx, actor, deleterRBResource, subjects(other, user))
+
+		e.rbac.SeparationOfDuties = constraints
+
+		require.NoError(t, err)
+
+		violations, err = e.ListSeparationOfDutiesViolations(ctx, root)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "update_delete", violations[0].Constraint)
+		assert.Equal(t, user.ID, violations[0].SubjectID)
+		assert.ElementsMatch(t, []string{"role_update", "role_delete"}, violations[0].Actions)
+	})
+}
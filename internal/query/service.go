@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"time"
 
 	"github.com/authzed/authzed-go/v1"
 	"go.infratographer.com/x/gidx"
@@ -33,43 +34,70 @@ type Engine interface {
 	UpdateRole(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error)
 	GetRole(ctx context.Context, roleResource types.Resource) (types.Role, error)
 	GetRoleResource(ctx context.Context, roleResource types.Resource) (types.Resource, error)
-	ListAssignments(ctx context.Context, role types.Role) ([]types.Resource, error)
-	ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
-	ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
-	ListRoles(ctx context.Context, resource types.Resource) ([]types.Role, error)
-	ListManagerRoles(ctx context.Context, manager string, resource types.Resource) ([]types.Role, error)
+	ListAssignments(ctx context.Context, role types.Role, opts ListOptions) ([]types.Resource, string, error)
+	ListRelationshipsFrom(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Relationship, string, error)
+	ListRelationshipsTo(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Relationship, string, error)
+	ListRoles(ctx context.Context, resource types.Resource, opts ListOptions) ([]types.Role, string, error)
+	ListManagerRoles(ctx context.Context, manager string, resource types.Resource, opts ListOptions) ([]types.Role, string, error)
 	DeleteRelationships(ctx context.Context, relationships ...types.Relationship) error
 	DeleteRole(ctx context.Context, roleResource types.Resource) error
 	DeleteResourceRelationships(ctx context.Context, resource types.Resource) error
 	NewResourceFromID(id gidx.PrefixedID) (types.Resource, error)
 	GetResourceType(name string) *types.ResourceType
 	SubjectHasPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) error
+	// EffectiveActions returns the role actions the subject can perform on the resource.
+	EffectiveActions(ctx context.Context, subject, resource types.Resource) ([]string, error)
 
 	// v2 functions, add role bindings support
 
 	// CreateRoleV2 creates a v2 role scoped to the given owner resource with the given actions.
 	CreateRoleV2(ctx context.Context, actor, owner types.Resource, manager, roleName string, actions []string) (types.Role, error)
-	// ListRolesV2 returns all V2 roles owned by the given resource.
-	ListRolesV2(ctx context.Context, owner types.Resource) ([]types.Role, error)
-	// ListManagerRolesV2 returns all V2 roles owned by the given resource with the given manager.
-	ListManagerRolesV2(ctx context.Context, manager string, owner types.Resource) ([]types.Role, error)
+	// ListRolesV2 returns a page of the V2 roles owned by the given resource which match the filter,
+	// along with the next page cursor.
+	ListRolesV2(ctx context.Context, owner types.Resource, filter RoleFilter, opts ListOptions) ([]types.Role, string, error)
+	// ListManagerRolesV2 returns a page of the V2 roles owned by the given resource with the given manager
+	// which match the filter, along with the next page cursor.
+	ListManagerRolesV2(ctx context.Context, manager string, owner types.Resource, filter RoleFilter, opts ListOptions) ([]types.Role, string, error)
 	// GetRoleV2 returns a V2 role
 	GetRoleV2(ctx context.Context, role types.Resource) (types.Role, error)
 	// UpdateRoleV2 updates a V2 role with the given name and actions.
 	UpdateRoleV2(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error)
+	// UpdateRoleV2Includes replaces the roles included by a V2 role, whose actions are also
+	// granted by the role.
+	UpdateRoleV2Includes(ctx context.Context, actor, roleResource types.Resource, includes []types.Resource) (types.Role, error)
 	// DeleteRoleV2 deletes a V2 role.
 	DeleteRoleV2(ctx context.Context, roleResource types.Resource) error
+	// MaterializeBuiltinRoles creates or updates the policy's built-in roles for the owner,
+	// returning the built-in roles of the owner.
+	MaterializeBuiltinRoles(ctx context.Context, owner types.Resource) ([]types.Role, error)
+	// MaterializeAllBuiltinRoles materializes the policy's built-in roles for every known
+	// role owner, returning the number of owners.
+	MaterializeAllBuiltinRoles(ctx context.Context) (int, error)
 
 	// CreateRoleBinding creates all the necessary relationships for a role binding.
 	// role binding here establishes a three-way relationship between a role,
 	// a resource, and the subjects.
 	CreateRoleBinding(ctx context.Context, actor, resource, role types.Resource, manager string, subjects []types.RoleBindingSubject) (types.RoleBinding, error)
-	// ListRoleBindings lists all role-bindings for a resource, an optional Role
-	// can be provided to filter the role-bindings.
-	ListRoleBindings(ctx context.Context, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error)
-	// ListManagerRoleBindings lists all role-bindings for a resource with the given manager,
-	// an optional Role can be provided to filter the role-bindings.
-	ListManagerRoleBindings(ctx context.Context, manager string, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error)
+	// ListRoleBindings lists a page of role-bindings for a resource which match the filter,
+	// along with the next page cursor. An optional Role can be provided to filter the role-bindings.
+	ListRoleBindings(
+		ctx context.Context, resource types.Resource,
+		optionalRole *types.Resource, filter RoleBindingFilter, opts ListOptions,
+	) ([]types.RoleBinding, string, error)
+	// ListManagerRoleBindings lists a page of role-bindings for a resource with the given manager
+	// which match the filter, along with the next page cursor. An optional Role can be provided to
+	// filter the role-bindings.
+	ListManagerRoleBindings(
+		ctx context.Context, manager string, resource types.Resource,
+		optionalRole *types.Resource, filter RoleBindingFilter, opts ListOptions,
+	) ([]types.RoleBinding, string, error)
+	// ListSubjectRoleBindings lists a page of the role-bindings across all resources which include the subject,
+	// directly or through a group, along with the next page cursor.
+	ListSubjectRoleBindings(ctx context.Context, subject types.Resource, opts ListOptions) ([]types.SubjectRoleBinding, string, error)
+	// BatchRoleBindings applies the role-binding operations in a single storage transaction and
+	// SpiceDB write, returning the resulting role-binding of each operation. If any operation
+	// fails, none of the operations are applied.
+	BatchRoleBindings(ctx context.Context, actor types.Resource, ops []RoleBindingOperation) ([]types.RoleBinding, error)
 	// GetRoleBinding fetches a role-binding by its ID.
 	GetRoleBinding(ctx context.Context, rolebinding types.Resource) (types.RoleBinding, error)
 	// UpdateRoleBinding updates the subjects of a role-binding.
@@ -79,6 +107,88 @@ type Engine interface {
 	// GetRoleBindingResource fetches the resource to which a role-binding
 	// belongs
 	GetRoleBindingResource(ctx context.Context, rb types.Resource) (types.Resource, error)
+	// RoleBindingRequiresApproval reports whether role-bindings of the role must be requested
+	// and approved before they are created.
+	RoleBindingRequiresApproval(ctx context.Context, role types.Resource) (bool, error)
+	// CreateRoleBindingRequest records a pending request for a role-binding, which is created
+	// once the request is approved by another subject.
+	CreateRoleBindingRequest(
+		ctx context.Context, actor, resource, role types.Resource, manager string, subjects []types.RoleBindingSubject,
+	) (types.RoleBindingRequest, error)
+	// GetRoleBindingRequest fetches a role-binding request by its ID.
+	GetRoleBindingRequest(ctx context.Context, id gidx.PrefixedID) (types.RoleBindingRequest, error)
+	// ListRoleBindingRequests lists a page of the role-binding requests on a resource with the given status,
+	// along with the next page cursor. An empty status lists requests of any status.
+	ListRoleBindingRequests(
+		ctx context.Context, resource types.Resource, status types.RoleBindingRequestStatus, opts ListOptions,
+	) ([]types.RoleBindingRequest, string, error)
+	// ApproveRoleBindingRequest approves a pending role-binding request, creating its role-binding.
+	ApproveRoleBindingRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.RoleBindingRequest, error)
+	// RejectRoleBindingRequest rejects a pending role-binding request.
+	RejectRoleBindingRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.RoleBindingRequest, error)
+	// CreateAccessRequest records a subject's pending request to be bound to a role on a resource.
+	// Once approved, the role-binding is kept for expiresIn, or until it is deleted if expiresIn is zero.
+	CreateAccessRequest(
+		ctx context.Context, subject, resource, role types.Resource, reason string, expiresIn time.Duration,
+	) (types.AccessRequest, error)
+	// GetAccessRequest fetches an access request by its ID.
+	GetAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+	// ListAccessRequests lists a page of the access requests on a resource with the given status,
+	// along with the next page cursor. An empty status lists requests of any status.
+	ListAccessRequests(
+		ctx context.Context, resource types.Resource, status types.AccessRequestStatus, opts ListOptions,
+	) ([]types.AccessRequest, string, error)
+	// ListSubjectAccessRequests lists a page of the access requests made by a subject with the given status,
+	// along with the next page cursor. An empty status lists requests of any status.
+	ListSubjectAccessRequests(
+		ctx context.Context, subject types.Resource, status types.AccessRequestStatus, opts ListOptions,
+	) ([]types.AccessRequest, string, error)
+	// ApproveAccessRequest approves a pending access request, binding its subject to the role.
+	// A non-zero expiresIn overrides the expiry of the request.
+	ApproveAccessRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID, expiresIn time.Duration) (types.AccessRequest, error)
+	// DenyAccessRequest denies a pending access request.
+	DenyAccessRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.AccessRequest, error)
+	// ExpireAccessRequests deletes the role-bindings of up to limit expired access requests,
+	// returning the number of requests expired. Requests which fail to expire are logged
+	// and skipped.
+	ExpireAccessRequests(ctx context.Context, limit int) (int, error)
+	// CreateAccessReview opens an access review campaign snapshotting the subjects of every
+	// role-binding on the resource and the resources beneath it.
+	CreateAccessReview(ctx context.Context, actor, resource types.Resource, name string) (types.AccessReview, error)
+	// GetAccessReview fetches an access review by its ID.
+	GetAccessReview(ctx context.Context, id gidx.PrefixedID) (types.AccessReview, error)
+	// ListAccessReviews lists a page of the access reviews of a resource with the given status,
+	// along with the next page cursor. An empty status lists reviews of any status.
+	ListAccessReviews(
+		ctx context.Context, resource types.Resource, status types.AccessReviewStatus, opts ListOptions,
+	) ([]types.AccessReview, string, error)
+	// ListAccessReviewItems lists a page of the items of an access review with the given decision,
+	// along with the next page cursor. An empty decision lists items with any decision.
+	ListAccessReviewItems(
+		ctx context.Context, id gidx.PrefixedID, decision types.AccessReviewDecision, opts ListOptions,
+	) ([]types.AccessReviewItem, string, error)
+	// DecideAccessReviewItem records the decision to keep or revoke an item of an open access review.
+	DecideAccessReviewItem(
+		ctx context.Context, actor types.Resource, id, itemID gidx.PrefixedID, decision types.AccessReviewDecision,
+	) (types.AccessReviewItem, error)
+	// CloseAccessReview closes an open access review, removing the subjects of the items
+	// decided to be revoked from their role-bindings.
+	CloseAccessReview(ctx context.Context, actor types.Resource, id gidx.PrefixedID) (types.AccessReview, error)
+	// BreakGlass binds the actor to the emergency role on the resource for the configured duration,
+	// recording the justification in an approved break-glass access request.
+	BreakGlass(ctx context.Context, actor, resource types.Resource, justification string) (types.AccessRequest, error)
+	// FlushRoleBindingUsage writes the buffered usage of role-bindings by their subjects to storage,
+	// returning the number of role-binding and subject pairs written.
+	FlushRoleBindingUsage(ctx context.Context) (int, error)
+	// ListUnusedAccess returns the subjects of the role-bindings on the resource and the resources
+	// beneath it which have not been allowed an action through the role-binding within unusedFor.
+	ListUnusedAccess(ctx context.Context, resource types.Resource, unusedFor time.Duration) ([]types.UnusedAccess, error)
+	// RoleBindingUsageSampleRate returns the fraction of allowed permission checks whose role-bindings
+	// are recorded as used, or 0 if usage is not recorded.
+	RoleBindingUsageSampleRate() float64
+	// ListSeparationOfDutiesViolations returns the separation of duties constraints violated
+	// by the subjects of the role-bindings granted on the resource.
+	ListSeparationOfDutiesViolations(ctx context.Context, resource types.Resource) ([]types.SeparationOfDutiesViolation, error)
 
 	AllActions() []string
 }
@@ -102,6 +212,15 @@ type engine struct {
 	// rbacV2ResourceTypes is a list of resource types that had rbac V2 enabled,
 	// role-binding only works with resource types that are in this list
 	rbacV2ResourceTypes []types.ResourceType
+
+	// escalationGuard rejects changes granting actions which the actor does not hold.
+	escalationGuard bool
+	// approvalActions are the actions which role bindings may only grant once approved.
+	approvalActions map[string]struct{}
+	// breakGlass configures binding subjects to the emergency role.
+	breakGlass BreakGlassConfig
+	// usage buffers the sampled usage of role bindings, it is nil if usage is not recorded.
+	usage *roleBindingUsageBuffer
 }
 
 func (e *engine) cacheSchemaResources() {
@@ -213,6 +332,46 @@ func WithPolicy(policy iapl.Policy) Option {
 			e.rbac = *rbac
 		}
 
+		e.approvalActions = make(map[string]struct{})
+
+		for _, action := range policy.ApprovalActions() {
+			e.approvalActions[action] = struct{}{}
+		}
+
 		e.cacheSchemaResources()
 	}
 }
+
+// WithPrivilegeEscalationGuard rejects role-bindings and role updates which grant actions
+// that the actor does not hold on the resource themselves.
+func WithPrivilegeEscalationGuard(enabled bool) Option {
+	return func(e *engine) {
+		e.escalationGuard = enabled
+	}
+}
+
+// WithBreakGlass enables subjects to bind themselves to the configured emergency role.
+func WithBreakGlass(config BreakGlassConfig) Option {
+	return func(e *engine) {
+		e.breakGlass = config
+	}
+}
+
+// WithRoleBindingUsage records the role bindings through which a sampleRate fraction of the allowed
+// permission checks were allowed. Usage is buffered until it is flushed with FlushRoleBindingUsage.
+func WithRoleBindingUsage(sampleRate float64) Option {
+	return func(e *engine) {
+		if sampleRate > 0 {
+			e.usage = newRoleBindingUsageBuffer(sampleRate)
+		}
+	}
+}
+
+// WithoutRoleBindingApprovals creates role bindings without approval, even if their roles
+// grant actions which require approval. This is intended for operator tooling bootstrapping access.
+// It must follow WithPolicy.
+func WithoutRoleBindingApprovals() Option {
+	return func(e *engine) {
+		e.approvalActions = nil
+	}
+}
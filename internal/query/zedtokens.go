@@ -130,6 +130,7 @@ func (e *engine) determineConsistency(ctx context.Context, resource types.Resour
 
 	switch {
 	case err != nil:
+		zedTokenLookupErrorsTotal.Inc()
 		e.logger.Warnw("error getting ZedToken", "error", err.Error())
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -145,5 +146,7 @@ func (e *engine) determineConsistency(ctx context.Context, resource types.Resour
 		consistencyName = consistencyAtLeastAsFresh
 	}
 
+	consistencyTotal.WithLabelValues(consistencyName).Inc()
+
 	return consistency, consistencyName
 }
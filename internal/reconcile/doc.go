@@ -0,0 +1,2 @@
+// Package reconcile reconciles v2 roles and role-bindings with a declarative document.
+package reconcile
@@ -0,0 +1,167 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.infratographer.com/x/gidx"
+	"gopkg.in/yaml.v3"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+// Document describes the desired v2 roles and role-bindings on resources which
+// are owned by a manager.
+type Document struct {
+	// Manager owns every role and role-binding in the document. Roles and role-bindings
+	// on the listed resources with the same manager which are not in the document are deleted.
+	Manager   string     `yaml:"manager"`
+	Resources []Resource `yaml:"resources"`
+}
+
+// Resource describes the desired roles and role-bindings on a single resource.
+type Resource struct {
+	ID           gidx.PrefixedID `yaml:"id"`
+	Roles        []Role          `yaml:"roles"`
+	RoleBindings []RoleBinding   `yaml:"role_bindings"`
+}
+
+// Role describes a v2 role owned by a resource.
+type Role struct {
+	Name    string   `yaml:"name"`
+	Actions []string `yaml:"actions"`
+}
+
+// RoleBinding describes a role-binding on a resource. The role is either the name
+// of a role declared on the same resource or the ID of any other role.
+type RoleBinding struct {
+	Role     string            `yaml:"role"`
+	RoleID   gidx.PrefixedID   `yaml:"role_id"`
+	Subjects []gidx.PrefixedID `yaml:"subjects"`
+}
+
+// LoadFile reads and validates a document from the given path.
+func LoadFile(path string) (Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Document{}, err
+	}
+
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load reads and validates a document.
+func Load(r io.Reader) (Document, error) {
+	var doc Document
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&doc); err != nil {
+		return Document{}, fmt.Errorf("%w: %s", ErrInvalidDocument, err.Error())
+	}
+
+	if err := doc.Validate(); err != nil {
+		return Document{}, err
+	}
+
+	return doc, nil
+}
+
+// Validate checks the document is well formed.
+func (d Document) Validate() error {
+	if d.Manager == "" {
+		return fmt.Errorf("%w: manager is required", ErrInvalidDocument)
+	}
+
+	if d.Manager == iapl.BuiltinRoleManager {
+		return fmt.Errorf("%w: manager %s is reserved for built-in roles", ErrInvalidDocument, d.Manager)
+	}
+
+	resources := make(map[gidx.PrefixedID]struct{}, len(d.Resources))
+
+	for _, res := range d.Resources {
+		if _, err := gidx.Parse(res.ID.String()); err != nil {
+			return fmt.Errorf("%w: resource %q: %s", ErrInvalidDocument, res.ID, err.Error())
+		}
+
+		if _, ok := resources[res.ID]; ok {
+			return fmt.Errorf("%w: resource %s: listed more than once", ErrInvalidDocument, res.ID)
+		}
+
+		resources[res.ID] = struct{}{}
+
+		if err := res.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r Resource) validate() error {
+	roles := make(map[string]struct{}, len(r.Roles))
+
+	for _, role := range r.Roles {
+		if role.Name == "" {
+			return r.invalidf("role name is required")
+		}
+
+		if len(role.Actions) == 0 {
+			return r.invalidf("role %s: actions are required", role.Name)
+		}
+
+		if _, ok := roles[role.Name]; ok {
+			return r.invalidf("role %s: declared more than once", role.Name)
+		}
+
+		roles[role.Name] = struct{}{}
+	}
+
+	bound := make(map[string]struct{}, len(r.RoleBindings))
+
+	for _, rb := range r.RoleBindings {
+		if (rb.Role == "") == (rb.RoleID == "") {
+			return r.invalidf("role-binding: exactly one of role or role_id is required")
+		}
+
+		ref := rb.Role
+
+		if rb.Role != "" {
+			if _, ok := roles[rb.Role]; !ok {
+				return r.invalidf("role-binding: role %s is not declared on the resource", rb.Role)
+			}
+		} else {
+			if _, err := gidx.Parse(rb.RoleID.String()); err != nil {
+				return r.invalidf("role-binding: role_id %q: %s", rb.RoleID, err.Error())
+			}
+
+			ref = rb.RoleID.String()
+		}
+
+		if _, ok := bound[ref]; ok {
+			return r.invalidf("role-binding: role %s is bound more than once", ref)
+		}
+
+		bound[ref] = struct{}{}
+
+		if len(rb.Subjects) == 0 {
+			return r.invalidf("role-binding: role %s: subjects are required", ref)
+		}
+
+		for _, subj := range rb.Subjects {
+			if _, err := gidx.Parse(subj.String()); err != nil {
+				return r.invalidf("role-binding: role %s: subject %q: %s", ref, subj, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r Resource) invalidf(format string, args ...any) error {
+	return fmt.Errorf("%w: resource %s: %s", ErrInvalidDocument, r.ID, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,137 @@
+package reconcile
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []testingx.TestCase[string, Document]{
+		{
+			Name: "Valid",
+			Input: `
+manager: gitops
+resources:
+  - id: tnntten-root
+    roles:
+      - name: lb_viewer
+        actions: [loadbalancer_get, loadbalancer_list]
+    role_bindings:
+      - role: lb_viewer
+        subjects: [idntusr-abc123, idntgrp-admins]
+      - role_id: permrv2-external
+        subjects: [idntusr-def456]
+`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, "gitops", res.Success.Manager)
+				require.Len(t, res.Success.Resources, 1)
+
+				res0 := res.Success.Resources[0]
+				assert.Equal(t, "tnntten-root", res0.ID.String())
+				assert.Equal(t, []Role{{Name: "lb_viewer", Actions: []string{"loadbalancer_get", "loadbalancer_list"}}}, res0.Roles)
+				require.Len(t, res0.RoleBindings, 2)
+				assert.Equal(t, "permrv2-external", res0.RoleBindings[1].RoleID.String())
+			},
+		},
+		{
+			Name:  "MissingManager",
+			Input: "resources: []",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+		{
+			Name:  "ReservedManager",
+			Input: "manager: policy\nresources: []",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+		{
+			Name:  "UnknownField",
+			Input: "manager: gitops\nowner: someone",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+		{
+			Name: "DuplicateResource",
+			Input: `
+manager: gitops
+resources:
+  - id: tnntten-root
+  - id: tnntten-root
+`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+		{
+			Name: "UndeclaredRole",
+			Input: `
+manager: gitops
+resources:
+  - id: tnntten-root
+    role_bindings:
+      - role: lb_viewer
+        subjects: [idntusr-abc123]
+`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+				assert.ErrorContains(t, res.Err, "lb_viewer is not declared")
+			},
+		},
+		{
+			Name: "RoleAndRoleID",
+			Input: `
+manager: gitops
+resources:
+  - id: tnntten-root
+    roles:
+      - name: lb_viewer
+        actions: [loadbalancer_get]
+    role_bindings:
+      - role: lb_viewer
+        role_id: permrv2-external
+        subjects: [idntusr-abc123]
+`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+		{
+			Name: "RoleBoundTwice",
+			Input: `
+manager: gitops
+resources:
+  - id: tnntten-root
+    role_bindings:
+      - role_id: permrv2-external
+        subjects: [idntusr-abc123]
+      - role_id: permrv2-external
+        subjects: [idntusr-def456]
+`,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Document]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidDocument)
+			},
+		},
+	}
+
+	testFn := func(_ context.Context, input string) testingx.TestResult[Document] {
+		doc, err := Load(strings.NewReader(input))
+
+		return testingx.TestResult[Document]{Success: doc, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
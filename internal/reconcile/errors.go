@@ -0,0 +1,6 @@
+package reconcile
+
+import "errors"
+
+// ErrInvalidDocument is returned when a reconciliation document is invalid.
+var ErrInvalidDocument = errors.New("invalid document")
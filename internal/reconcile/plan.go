@@ -0,0 +1,190 @@
+package reconcile
+
+import (
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// ChangeOp is the kind of change made to a role or role-binding.
+type ChangeOp string
+
+const (
+	// ChangeOpCreate creates a role or role-binding.
+	ChangeOpCreate ChangeOp = "create"
+	// ChangeOpUpdate updates a role's actions or a role-binding's subjects.
+	ChangeOpUpdate ChangeOp = "update"
+	// ChangeOpDelete deletes a role or role-binding.
+	ChangeOpDelete ChangeOp = "delete"
+)
+
+// ChangeKind is the kind of object changed.
+type ChangeKind string
+
+const (
+	// ChangeKindRole is a v2 role.
+	ChangeKindRole ChangeKind = "role"
+	// ChangeKindRoleBinding is a role-binding.
+	ChangeKindRoleBinding ChangeKind = "role-binding"
+)
+
+// Change is a single change made, or planned when dry running, by the reconciler.
+type Change struct {
+	Op         ChangeOp
+	Kind       ChangeKind
+	ResourceID gidx.PrefixedID
+	// ID is the role or role-binding ID. It is empty for objects which have not been created yet.
+	ID gidx.PrefixedID
+	// Name is the role name, or the bound role name or ID for role-bindings.
+	Name string
+}
+
+func (c Change) String() string {
+	if c.ID == "" {
+		return fmt.Sprintf("%s %s %s on %s", c.Op, c.Kind, c.Name, c.ResourceID)
+	}
+
+	return fmt.Sprintf("%s %s %s (%s) on %s", c.Op, c.Kind, c.Name, c.ID, c.ResourceID)
+}
+
+// roleUpdate is an existing role whose actions must be replaced.
+type roleUpdate struct {
+	role    types.Role
+	actions []string
+}
+
+// rolePlan is the set of changes which reconcile the roles on a resource.
+type rolePlan struct {
+	create []Role
+	update []roleUpdate
+	delete []types.Role
+
+	// ids are the IDs of the existing roles which are kept, by name.
+	ids map[string]gidx.PrefixedID
+}
+
+// planRoles compares the desired roles with the existing roles of the same manager.
+// Roles are matched by name. If more than one existing role has the same name, only
+// the first is kept.
+func planRoles(desired []Role, existing []types.Role) rolePlan {
+	plan := rolePlan{
+		ids: make(map[string]gidx.PrefixedID, len(desired)),
+	}
+
+	wanted := make(map[string]Role, len(desired))
+
+	for _, role := range desired {
+		wanted[role.Name] = role
+	}
+
+	for _, role := range existing {
+		want, ok := wanted[role.Name]
+		if _, kept := plan.ids[role.Name]; !ok || kept {
+			plan.delete = append(plan.delete, role)
+
+			continue
+		}
+
+		plan.ids[role.Name] = role.ID
+
+		if !sameSet(role.Actions, want.Actions) {
+			plan.update = append(plan.update, roleUpdate{role: role, actions: want.Actions})
+		}
+	}
+
+	for _, role := range desired {
+		if _, ok := plan.ids[role.Name]; !ok {
+			plan.create = append(plan.create, role)
+		}
+	}
+
+	return plan
+}
+
+// desiredRoleBinding is a role-binding from the document with its role resolved.
+type desiredRoleBinding struct {
+	// roleID is empty when the role has not been created yet.
+	roleID   gidx.PrefixedID
+	roleName string
+	subjects []gidx.PrefixedID
+}
+
+// roleBindingUpdate is an existing role-binding whose subjects must be replaced.
+type roleBindingUpdate struct {
+	roleBinding types.RoleBinding
+	desired     desiredRoleBinding
+}
+
+// roleBindingPlan is the set of changes which reconcile the role-bindings on a resource.
+type roleBindingPlan struct {
+	create []desiredRoleBinding
+	update []roleBindingUpdate
+	delete []types.RoleBinding
+}
+
+// planRoleBindings compares the desired role-bindings with the existing role-bindings of
+// the same manager. Role-bindings are matched by role. If more than one existing role-binding
+// has the same role, only the first is kept.
+func planRoleBindings(desired []desiredRoleBinding, existing []types.RoleBinding) roleBindingPlan {
+	var plan roleBindingPlan
+
+	wanted := make(map[gidx.PrefixedID]desiredRoleBinding, len(desired))
+
+	for _, rb := range desired {
+		if rb.roleID != "" {
+			wanted[rb.roleID] = rb
+		}
+	}
+
+	kept := make(map[gidx.PrefixedID]struct{}, len(existing))
+
+	for _, rb := range existing {
+		want, ok := wanted[rb.RoleID]
+		if _, dup := kept[rb.RoleID]; !ok || dup {
+			plan.delete = append(plan.delete, rb)
+
+			continue
+		}
+
+		kept[rb.RoleID] = struct{}{}
+
+		if !sameSet(rb.SubjectIDs, want.subjects) {
+			plan.update = append(plan.update, roleBindingUpdate{roleBinding: rb, desired: want})
+		}
+	}
+
+	for _, rb := range desired {
+		if _, ok := kept[rb.roleID]; !ok {
+			plan.create = append(plan.create, rb)
+		}
+	}
+
+	return plan
+}
+
+// sameSet reports whether a and b contain the same elements, ignoring order and duplicates.
+func sameSet[T comparable](a, b []T) bool {
+	seen := make(map[T]bool, len(a))
+
+	for _, v := range a {
+		seen[v] = false
+	}
+
+	for _, v := range b {
+		if _, ok := seen[v]; !ok {
+			return false
+		}
+
+		seen[v] = true
+	}
+
+	for _, found := range seen {
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestPlanRoles(t *testing.T) {
+	desired := []Role{
+		{Name: "unchanged", Actions: []string{"loadbalancer_list", "loadbalancer_get"}},
+		{Name: "changed", Actions: []string{"loadbalancer_get"}},
+		{Name: "missing", Actions: []string{"loadbalancer_create"}},
+	}
+
+	existing := []types.Role{
+		{ID: "permrv2-unchanged", Name: "unchanged", Actions: []string{"loadbalancer_get", "loadbalancer_list"}},
+		{ID: "permrv2-changed", Name: "changed", Actions: []string{"loadbalancer_get", "loadbalancer_delete"}},
+		{ID: "permrv2-extra", Name: "extra", Actions: []string{"loadbalancer_get"}},
+		{ID: "permrv2-duplicate", Name: "unchanged", Actions: []string{"loadbalancer_get", "loadbalancer_list"}},
+	}
+
+	plan := planRoles(desired, existing)
+
+	assert.Equal(t, []Role{desired[2]}, plan.create)
+	assert.Equal(t, []roleUpdate{{role: existing[1], actions: desired[1].Actions}}, plan.update)
+	assert.Equal(t, []types.Role{existing[2], existing[3]}, plan.delete)
+	assert.Equal(t, map[string]gidx.PrefixedID{
+		"unchanged": "permrv2-unchanged",
+		"changed":   "permrv2-changed",
+	}, plan.ids)
+}
+
+func TestPlanRoleBindings(t *testing.T) {
+	desired := []desiredRoleBinding{
+		{roleID: "permrv2-unchanged", roleName: "unchanged", subjects: []gidx.PrefixedID{"idntusr-a", "idntusr-b"}},
+		{roleID: "permrv2-changed", roleName: "changed", subjects: []gidx.PrefixedID{"idntusr-a"}},
+		{roleID: "permrv2-missing", roleName: "missing", subjects: []gidx.PrefixedID{"idntusr-a"}},
+		{roleName: "uncreated", subjects: []gidx.PrefixedID{"idntusr-a"}},
+	}
+
+	existing := []types.RoleBinding{
+		{ID: "permrbn-unchanged", RoleID: "permrv2-unchanged", SubjectIDs: []gidx.PrefixedID{"idntusr-b", "idntusr-a"}},
+		{ID: "permrbn-changed", RoleID: "permrv2-changed", SubjectIDs: []gidx.PrefixedID{"idntusr-a", "idntusr-b"}},
+		{ID: "permrbn-extra", RoleID: "permrv2-extra", SubjectIDs: []gidx.PrefixedID{"idntusr-a"}},
+		{ID: "permrbn-duplicate", RoleID: "permrv2-unchanged", SubjectIDs: []gidx.PrefixedID{"idntusr-a", "idntusr-b"}},
+	}
+
+	plan := planRoleBindings(desired, existing)
+
+	assert.Equal(t, []desiredRoleBinding{desired[2], desired[3]}, plan.create)
+	assert.Equal(t, []roleBindingUpdate{{roleBinding: existing[1], desired: desired[1]}}, plan.update)
+	assert.Equal(t, []types.RoleBinding{existing[2], existing[3]}, plan.delete)
+}
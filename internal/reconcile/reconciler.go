@@ -0,0 +1,354 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+var tracer = otel.Tracer("go.infratographer.com/permissions-api/internal/reconcile")
+
+// Reconciler makes the roles and role-bindings of a manager match a document.
+type Reconciler struct {
+	engine query.Engine
+	logger *zap.SugaredLogger
+	dryRun bool
+}
+
+// Option is a functional option for the reconciler.
+type Option func(*Reconciler)
+
+// WithLogger sets the logger for the reconciler.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(r *Reconciler) {
+		r.logger = logger
+	}
+}
+
+// WithDryRun plans the changes without applying them.
+func WithDryRun(dryRun bool) Option {
+	return func(r *Reconciler) {
+		r.dryRun = dryRun
+	}
+}
+
+// New creates a new reconciler using the given engine.
+func New(engine query.Engine, options ...Option) *Reconciler {
+	r := &Reconciler{
+		engine: engine,
+		logger: zap.NewNop().Sugar(),
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	return r
+}
+
+// Apply reconciles each resource in the document in turn. Missing roles and role-bindings
+// are created, changed ones are updated and those with the document's manager which are not
+// in the document are deleted. Resources not listed in the document are left untouched.
+//
+// Role-binding changes on a resource are applied in a single transaction. The changes made
+// before an error are returned along with the error.
+func (r *Reconciler) Apply(ctx context.Context, actor types.Resource, doc Document) ([]Change, error) {
+	ctx, span := tracer.Start(
+		ctx, "reconcile.Apply",
+		trace.WithAttributes(
+			attribute.String("manager", doc.Manager),
+			attribute.Bool("dry_run", r.dryRun),
+		),
+	)
+	defer span.End()
+
+	var changes []Change
+
+	for _, res := range doc.Resources {
+		resChanges, err := r.applyResource(ctx, actor, doc.Manager, res)
+
+		changes = append(changes, resChanges...)
+
+		if err != nil {
+			err = fmt.Errorf("resource %s: %w", res.ID, err)
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+func (r *Reconciler) applyResource(ctx context.Context, actor types.Resource, manager string, res Resource) ([]Change, error) {
+	resource, err := r.engine.NewResourceFromID(res.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	existingRoles, err := r.listRoles(ctx, manager, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	roleNames := make(map[gidx.PrefixedID]string, len(existingRoles))
+
+	for _, role := range existingRoles {
+		roleNames[role.ID] = role.Name
+	}
+
+	rolePlan := planRoles(res.Roles, existingRoles)
+
+	var changes []Change
+
+	for _, role := range rolePlan.create {
+		change := Change{Op: ChangeOpCreate, Kind: ChangeKindRole, ResourceID: res.ID, Name: role.Name}
+
+		if !r.dryRun {
+			created, err := r.engine.CreateRoleV2(ctx, actor, resource, manager, role.Name, role.Actions)
+			if err != nil {
+				return changes, fmt.Errorf("creating role %s: %w", role.Name, err)
+			}
+
+			change.ID = created.ID
+			rolePlan.ids[role.Name] = created.ID
+		}
+
+		changes = append(changes, r.record(change))
+	}
+
+	for _, update := range rolePlan.update {
+		if !r.dryRun {
+			roleRes, err := r.engine.NewResourceFromID(update.role.ID)
+			if err != nil {
+				return changes, err
+			}
+
+			if _, err := r.engine.UpdateRoleV2(ctx, actor, roleRes, "", update.actions); err != nil {
+				return changes, fmt.Errorf("updating role %s: %w", update.role.Name, err)
+			}
+		}
+
+		changes = append(changes, r.record(Change{
+			Op: ChangeOpUpdate, Kind: ChangeKindRole, ResourceID: res.ID, ID: update.role.ID, Name: update.role.Name,
+		}))
+	}
+
+	rbChanges, err := r.applyRoleBindings(ctx, actor, manager, resource, res.RoleBindings, rolePlan.ids, roleNames)
+
+	changes = append(changes, rbChanges...)
+
+	if err != nil {
+		return changes, err
+	}
+
+	// roles are deleted last, as roles which are still bound cannot be deleted.
+	for _, role := range rolePlan.delete {
+		if !r.dryRun {
+			roleRes, err := r.engine.NewResourceFromID(role.ID)
+			if err != nil {
+				return changes, err
+			}
+
+			if err := r.engine.DeleteRoleV2(ctx, roleRes); err != nil {
+				return changes, fmt.Errorf("deleting role %s: %w", role.Name, err)
+			}
+		}
+
+		changes = append(changes, r.record(Change{
+			Op: ChangeOpDelete, Kind: ChangeKindRole, ResourceID: res.ID, ID: role.ID, Name: role.Name,
+		}))
+	}
+
+	return changes, nil
+}
+
+func (r *Reconciler) applyRoleBindings(
+	ctx context.Context, actor types.Resource, manager string, resource types.Resource,
+	roleBindings []RoleBinding, roleIDs map[string]gidx.PrefixedID, roleNames map[gidx.PrefixedID]string,
+) ([]Change, error) {
+	desired := make([]desiredRoleBinding, len(roleBindings))
+
+	for i, rb := range roleBindings {
+		desired[i] = desiredRoleBinding{
+			roleID:   rb.RoleID,
+			roleName: rb.RoleID.String(),
+			subjects: rb.Subjects,
+		}
+
+		if rb.Role != "" {
+			desired[i].roleID = roleIDs[rb.Role]
+			desired[i].roleName = rb.Role
+		}
+	}
+
+	existing, _, err := r.engine.ListManagerRoleBindings(ctx, manager, resource, nil, query.RoleBindingFilter{}, query.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planRoleBindings(desired, existing)
+
+	var (
+		changes []Change
+		ops     []query.RoleBindingOperation
+	)
+
+	for _, rb := range plan.create {
+		changes = append(changes, Change{Op: ChangeOpCreate, Kind: ChangeKindRoleBinding, ResourceID: resource.ID, Name: rb.roleName})
+
+		if r.dryRun {
+			continue
+		}
+
+		op, err := r.roleBindingOperation(query.RoleBindingOpCreate, rb.roleID, rb.subjects)
+		if err != nil {
+			return nil, err
+		}
+
+		op.Resource = resource
+		op.Manager = manager
+
+		ops = append(ops, op)
+	}
+
+	for _, update := range plan.update {
+		changes = append(changes, Change{
+			Op: ChangeOpUpdate, Kind: ChangeKindRoleBinding, ResourceID: resource.ID,
+			ID: update.roleBinding.ID, Name: update.desired.roleName,
+		})
+
+		if r.dryRun {
+			continue
+		}
+
+		op, err := r.roleBindingOperation(query.RoleBindingOpUpdate, update.roleBinding.ID, update.desired.subjects)
+		if err != nil {
+			return nil, err
+		}
+
+		ops = append(ops, op)
+	}
+
+	for _, rb := range plan.delete {
+		name, ok := roleNames[rb.RoleID]
+		if !ok {
+			name = rb.RoleID.String()
+		}
+
+		changes = append(changes, Change{
+			Op: ChangeOpDelete, Kind: ChangeKindRoleBinding, ResourceID: resource.ID, ID: rb.ID, Name: name,
+		})
+
+		if r.dryRun {
+			continue
+		}
+
+		op, err := r.roleBindingOperation(query.RoleBindingOpDelete, rb.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		ops = append(ops, op)
+	}
+
+	if len(ops) != 0 {
+		rbs, err := r.engine.BatchRoleBindings(ctx, actor, ops)
+		if err != nil {
+			return nil, fmt.Errorf("applying role-binding changes: %w", err)
+		}
+
+		// created role-bindings are first in both the changes and the batch results.
+		for i := range plan.create {
+			changes[i].ID = rbs[i].ID
+		}
+	}
+
+	for _, change := range changes {
+		r.record(change)
+	}
+
+	return changes, nil
+}
+
+// roleBindingOperation builds a role-binding operation. The id is the role ID for create
+// operations and the role-binding ID for update and delete operations.
+func (r *Reconciler) roleBindingOperation(op query.RoleBindingOp, id gidx.PrefixedID, subjectIDs []gidx.PrefixedID) (query.RoleBindingOperation, error) {
+	out := query.RoleBindingOperation{Op: op}
+
+	res, err := r.engine.NewResourceFromID(id)
+	if err != nil {
+		return out, err
+	}
+
+	if op == query.RoleBindingOpCreate {
+		out.Role = res
+	} else {
+		out.RoleBinding = res
+	}
+
+	for _, sid := range subjectIDs {
+		subj, err := r.engine.NewResourceFromID(sid)
+		if err != nil {
+			return out, fmt.Errorf("subject %s: %w", sid, err)
+		}
+
+		out.Subjects = append(out.Subjects, types.RoleBindingSubject{SubjectResource: subj})
+	}
+
+	return out, nil
+}
+
+// listRoles returns the manager's roles owned by the resource, including their actions.
+// Roles available to the resource from its ancestors are not returned, as they are reconciled
+// with the ancestor owning them.
+func (r *Reconciler) listRoles(ctx context.Context, manager string, resource types.Resource) ([]types.Role, error) {
+	available, _, err := r.engine.ListManagerRolesV2(ctx, manager, resource, query.RoleFilter{}, query.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]types.Role, 0, len(available))
+
+	for _, role := range available {
+		if role.ResourceID != resource.ID {
+			continue
+		}
+
+		roleRes, err := r.engine.NewResourceFromID(role.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		role, err = r.engine.GetRoleV2(ctx, roleRes)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// record logs the change and returns it.
+func (r *Reconciler) record(change Change) Change {
+	if r.dryRun {
+		r.logger.Infow("planned change", "change", change.String())
+	} else {
+		r.logger.Infow("applied change", "change", change.String())
+	}
+
+	return change
+}
@@ -0,0 +1,126 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// testEngine serves the roles available to resources and records the roles changed.
+type testEngine struct {
+	*mock.Engine
+
+	// roles are the roles available to every resource, including those owned by other resources.
+	roles []types.Role
+
+	created []string
+	updated []gidx.PrefixedID
+	deleted []gidx.PrefixedID
+}
+
+func (e *testEngine) NewResourceFromID(id gidx.PrefixedID) (types.Resource, error) {
+	return types.Resource{Type: id.Prefix(), ID: id}, nil
+}
+
+func (e *testEngine) ListManagerRolesV2(context.Context, string, types.Resource, query.RoleFilter, query.ListOptions) ([]types.Role, string, error) {
+	return append([]types.Role{}, e.roles...), "", nil
+}
+
+func (e *testEngine) GetRoleV2(_ context.Context, role types.Resource) (types.Role, error) {
+	for _, r := range e.roles {
+		if r.ID == role.ID {
+			return r, nil
+		}
+	}
+
+	return types.Role{}, query.ErrRoleNotFound
+}
+
+func (e *testEngine) CreateRoleV2(_ context.Context, _, owner types.Resource, manager, name string, actions []string) (types.Role, error) {
+	e.created = append(e.created, name)
+
+	return types.Role{ID: gidx.MustNewID("permrv2"), Name: name, Manager: manager, ResourceID: owner.ID, Actions: actions}, nil
+}
+
+func (e *testEngine) UpdateRoleV2(_ context.Context, _, role types.Resource, _ string, _ []string) (types.Role, error) {
+	e.updated = append(e.updated, role.ID)
+
+	return types.Role{ID: role.ID}, nil
+}
+
+func (e *testEngine) DeleteRoleV2(_ context.Context, role types.Resource) error {
+	e.deleted = append(e.deleted, role.ID)
+
+	return nil
+}
+
+func (e *testEngine) ListManagerRoleBindings(
+	context.Context, string, types.Resource, *types.Resource, query.RoleBindingFilter, query.ListOptions,
+) ([]types.RoleBinding, string, error) {
+	return []types.RoleBinding{}, "", nil
+}
+
+func (e *testEngine) BatchRoleBindings(context.Context, types.Resource, []query.RoleBindingOperation) ([]types.RoleBinding, error) {
+	return []types.RoleBinding{}, nil
+}
+
+func TestApplyInheritedRoles(t *testing.T) {
+	ctx := context.Background()
+
+	parentViewer := types.Role{
+		ID: "permrv2-parentviewer", Name: "viewer", Manager: "ops", ResourceID: "tnntten-parent",
+		Actions: []string{"loadbalancer_get", "loadbalancer_list"},
+	}
+	parentEditor := types.Role{
+		ID: "permrv2-parenteditor", Name: "editor", Manager: "ops", ResourceID: "tnntten-parent",
+		Actions: []string{"loadbalancer_update"},
+	}
+	childAuditor := types.Role{
+		ID: "permrv2-childauditor", Name: "auditor", Manager: "ops", ResourceID: "tnntten-child",
+		Actions: []string{"loadbalancer_get"},
+	}
+
+	doc := Document{
+		Manager: "ops",
+		Resources: []Resource{
+			{
+				ID: "tnntten-child",
+				Roles: []Role{
+					{Name: "viewer", Actions: []string{"loadbalancer_get"}},
+				},
+			},
+		},
+	}
+
+	actor := types.Resource{Type: "user", ID: "idntusr-actor"}
+
+	t.Run("Plan", func(t *testing.T) {
+		engine := &testEngine{Engine: &mock.Engine{}, roles: []types.Role{parentViewer, parentEditor, childAuditor}}
+
+		changes, err := New(engine, WithDryRun(true)).Apply(ctx, actor, doc)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Change{
+			{Op: ChangeOpCreate, Kind: ChangeKindRole, ResourceID: "tnntten-child", Name: "viewer"},
+			{Op: ChangeOpDelete, Kind: ChangeKindRole, ResourceID: "tnntten-child", ID: childAuditor.ID, Name: "auditor"},
+		}, changes)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		engine := &testEngine{Engine: &mock.Engine{}, roles: []types.Role{parentViewer, parentEditor, childAuditor}}
+
+		_, err := New(engine).Apply(ctx, actor, doc)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"viewer"}, engine.created)
+		assert.Empty(t, engine.updated, "roles owned by the parent must not be updated")
+		assert.Equal(t, []gidx.PrefixedID{childAuditor.ID}, engine.deleted, "roles owned by the parent must not be deleted")
+	})
+}
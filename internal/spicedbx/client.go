@@ -25,7 +25,10 @@ type Config struct {
 
 // NewClient returns a new spicedb/authzed client
 func NewClient(cfg Config, enableTracing bool) (*authzed.Client, error) {
-	clientOpts := []grpc.DialOption{}
+	clientOpts := []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unaryErrorsInterceptor),
+		grpc.WithChainStreamInterceptor(streamErrorsInterceptor),
+	}
 
 	if cfg.Insecure {
 		clientOpts = append(clientOpts,
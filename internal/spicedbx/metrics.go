@@ -0,0 +1,61 @@
+package spicedbx
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var rpcErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "permissions_api",
+	Name:      "spicedb_rpc_errors_total",
+	Help:      "SpiceDB requests which failed by method and gRPC status code.",
+}, []string{"method", "code"})
+
+func observeRPCError(method string, err error) {
+	if err == nil || errors.Is(err, io.EOF) {
+		return
+	}
+
+	rpcErrorsTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+}
+
+// unaryErrorsInterceptor counts the errors returned by unary SpiceDB requests.
+func unaryErrorsInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	observeRPCError(method, err)
+
+	return err
+}
+
+// streamErrorsInterceptor counts the errors returned by streaming SpiceDB requests, both when the
+// stream is opened and while it is received from.
+func streamErrorsInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		observeRPCError(method, err)
+
+		return nil, err
+	}
+
+	return &errorsClientStream{ClientStream: stream, method: method}, nil
+}
+
+type errorsClientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *errorsClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	observeRPCError(s.method, err)
+
+	return err
+}
@@ -0,0 +1,73 @@
+package spicedbx
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rpcErrors returns the number of errors counted for the method and code.
+func rpcErrors(t *testing.T, method, code string) float64 {
+	var m dto.Metric
+
+	require.NoError(t, rpcErrorsTotal.WithLabelValues(method, code).Write(&m))
+
+	return m.GetCounter().GetValue()
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s testClientStream) RecvMsg(any) error {
+	return s.err
+}
+
+func TestUnaryErrorsInterceptor(t *testing.T) {
+	method := "/test.Service/Unary"
+
+	invoke := func(err error) error {
+		return unaryErrorsInterceptor(context.Background(), method, nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				return err
+			},
+		)
+	}
+
+	require.NoError(t, invoke(nil))
+	require.Error(t, invoke(status.Error(codes.Unavailable, "unavailable")))
+	require.Error(t, invoke(status.Error(codes.Unavailable, "unavailable")))
+	require.Error(t, invoke(status.Error(codes.NotFound, "not found")))
+
+	assert.InDelta(t, 2, rpcErrors(t, method, codes.Unavailable.String()), 0)
+	assert.InDelta(t, 1, rpcErrors(t, method, codes.NotFound.String()), 0)
+}
+
+func TestStreamErrorsInterceptor(t *testing.T) {
+	method := "/test.Service/Stream"
+
+	open := func(err error) grpc.ClientStream {
+		stream, openErr := streamErrorsInterceptor(context.Background(), &grpc.StreamDesc{}, nil, method,
+			func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				return testClientStream{err: err}, nil
+			},
+		)
+		require.NoError(t, openErr)
+
+		return stream
+	}
+
+	assert.ErrorIs(t, open(io.EOF).RecvMsg(nil), io.EOF)
+	assert.Error(t, open(status.Error(codes.DeadlineExceeded, "deadline exceeded")).RecvMsg(nil))
+
+	assert.InDelta(t, 1, rpcErrors(t, method, codes.DeadlineExceeded.String()), 0)
+	assert.InDelta(t, 0, rpcErrors(t, method, codes.Unknown.String()), 0)
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// AccessRequestService represents a service for managing subjects' requests for access
+// in the permissions API storage
+type AccessRequestService interface {
+	// GetAccessRequestByID returns an access request by its prefixed ID
+	// an ErrAccessRequestNotFound error is returned if no request is found
+	GetAccessRequestByID(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+
+	// ListResourceAccessRequests returns the access requests for a given resource with the given status,
+	// limited by the provided options. An empty status returns requests of any status.
+	// an empty slice is returned if no requests are found
+	ListResourceAccessRequests(
+		ctx context.Context, resourceID gidx.PrefixedID, status types.AccessRequestStatus, opts ListOptions,
+	) ([]types.AccessRequest, error)
+
+	// ListSubjectAccessRequests returns the access requests made by a given subject with the given status,
+	// limited by the provided options. An empty status returns requests of any status.
+	// an empty slice is returned if no requests are found
+	ListSubjectAccessRequests(
+		ctx context.Context, subjectID gidx.PrefixedID, status types.AccessRequestStatus, opts ListOptions,
+	) ([]types.AccessRequest, error)
+
+	// ListExpiredAccessRequests returns up to limit approved access requests which expired before the given time.
+	ListExpiredAccessRequests(ctx context.Context, before time.Time, limit int) ([]types.AccessRequest, error)
+
+	// CreateAccessRequest creates a new pending access request in the database
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateAccessRequest(ctx context.Context, req types.AccessRequest) (types.AccessRequest, error)
+
+	// UpdateAccessRequestStatus records the review of an access request, along with the
+	// role binding created and when it expires when the request is approved.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	UpdateAccessRequestStatus(
+		ctx context.Context, reviewerID, id gidx.PrefixedID, status types.AccessRequestStatus, rbID gidx.PrefixedID, expiresAt *time.Time,
+	) (types.AccessRequest, error)
+
+	// ExpireAccessRequest marks an approved access request as expired once its role binding is removed.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	ExpireAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+
+	// LockAccessRequestForUpdate locks an access request record to be updated to ensure consistency.
+	// If the request is not found, an ErrAccessRequestNotFound error is returned.
+	LockAccessRequestForUpdate(ctx context.Context, id gidx.PrefixedID) error
+}
+
+// accessRequestColumns are the columns scanned by scanAccessRequest.
+const accessRequestColumns = `id, resource_id, role_id, subject_id, reason, status, expires_in, expires_at,
+	rolebinding_id, break_glass, reviewed_by, created_at, updated_at`
+
+func scanAccessRequest(row rowScanner) (types.AccessRequest, error) {
+	var (
+		req       types.AccessRequest
+		status    string
+		expiresIn int64
+		expiresAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&req.ID,
+		&req.ResourceID,
+		&req.RoleID,
+		&req.SubjectID,
+		&req.Reason,
+		&status,
+		&expiresIn,
+		&expiresAt,
+		&req.RoleBindingID,
+		&req.BreakGlass,
+		&req.ReviewedBy,
+		&req.CreatedAt,
+		&req.UpdatedAt,
+	)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req.Status = types.AccessRequestStatus(status)
+	req.ExpiresIn = time.Duration(expiresIn) * time.Second
+
+	if expiresAt.Valid {
+		req.ExpiresAt = &expiresAt.Time
+	}
+
+	return req, nil
+}
+
+func (e *engine) GetAccessRequestByID(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req, err := scanAccessRequest(db.QueryRowContext(ctx,
+		`SELECT `+accessRequestColumns+` FROM access_requests WHERE id = $1`,
+		id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) ListResourceAccessRequests(
+	ctx context.Context, resourceID gidx.PrefixedID, status types.AccessRequestStatus, opts ListOptions,
+) ([]types.AccessRequest, error) {
+	return e.listAccessRequests(ctx, "resource_id", resourceID, status, opts)
+}
+
+func (e *engine) ListSubjectAccessRequests(
+	ctx context.Context, subjectID gidx.PrefixedID, status types.AccessRequestStatus, opts ListOptions,
+) ([]types.AccessRequest, error) {
+	return e.listAccessRequests(ctx, "subject_id", subjectID, status, opts)
+}
+
+// listAccessRequests lists the access requests whose column matches the ID.
+// column must not be user provided.
+func (e *engine) listAccessRequests(
+	ctx context.Context, column string, id gidx.PrefixedID, status types.AccessRequestStatus, opts ListOptions,
+) ([]types.AccessRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + accessRequestColumns + ` FROM access_requests WHERE ` + column + ` = $1`
+	args := []any{id.String()}
+
+	if status != "" {
+		args = append(args, string(status))
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, id.String())
+	}
+	defer rows.Close() //nolint:errcheck
+
+	reqs := []types.AccessRequest{}
+
+	for rows.Next() {
+		req, err := scanAccessRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, id.String())
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, rows.Err()
+}
+
+func (e *engine) ListExpiredAccessRequests(ctx context.Context, before time.Time, limit int) ([]types.AccessRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+accessRequestColumns+` FROM access_requests
+		WHERE status = $1 AND expires_at IS NOT NULL AND expires_at <= $2
+		ORDER BY expires_at ASC
+		LIMIT $3`,
+		string(types.AccessRequestApproved), before, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	reqs := []types.AccessRequest{}
+
+	for rows.Next() {
+		req, err := scanAccessRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, rows.Err()
+}
+
+func (e *engine) CreateAccessRequest(ctx context.Context, req types.AccessRequest) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	out, err := scanAccessRequest(tx.QueryRowContext(ctx, `
+		INSERT INTO access_requests (id, resource_id, role_id, subject_id, reason, status, expires_in, break_glass, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING `+accessRequestColumns,
+		req.ID.String(), req.ResourceID.String(), req.RoleID.String(), req.SubjectID.String(),
+		req.Reason, string(types.AccessRequestPending), int64(req.ExpiresIn/time.Second), req.BreakGlass, time.Now(),
+	))
+	if err != nil {
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, req.ID.String())
+	}
+
+	return out, nil
+}
+
+func (e *engine) UpdateAccessRequestStatus(
+	ctx context.Context, reviewerID, id gidx.PrefixedID, status types.AccessRequestStatus, rbID gidx.PrefixedID, expiresAt *time.Time,
+) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req, err := scanAccessRequest(tx.QueryRowContext(ctx, `
+		UPDATE access_requests
+		SET status = $1, rolebinding_id = $2, expires_at = $3, reviewed_by = $4, updated_at = now()
+		WHERE id = $5
+		RETURNING `+accessRequestColumns,
+		string(status), rbID.String(), expiresAt, reviewerID.String(), id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) ExpireAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req, err := scanAccessRequest(tx.QueryRowContext(ctx, `
+		UPDATE access_requests
+		SET status = $1, updated_at = now()
+		WHERE id = $2
+		RETURNING `+accessRequestColumns,
+		string(types.AccessRequestExpired), id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) LockAccessRequestForUpdate(ctx context.Context, id gidx.PrefixedID) error {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `SELECT 1 FROM access_requests WHERE id = $1 FOR UPDATE`, id.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestAccessRequests(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	subjectID := gidx.PrefixedID("idntusr-requester")
+	reviewerID := gidx.PrefixedID("idntusr-reviewer")
+	resourceID := gidx.PrefixedID("tnntten-tenant")
+
+	newRequest := func(t *testing.T, expiresIn time.Duration) types.AccessRequest {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		req, err := store.CreateAccessRequest(dbCtx, types.AccessRequest{
+			ID:         gidx.MustNewID("permacr"),
+			ResourceID: resourceID,
+			RoleID:     "permrv2-role",
+			SubjectID:  subjectID,
+			Reason:     t.Name(),
+			ExpiresIn:  expiresIn,
+		})
+		require.NoError(t, err, "no error expected creating access request")
+
+		err = store.CommitContext(dbCtx)
+		require.NoError(t, err, "no error expected committing transaction context")
+
+		return req
+	}
+
+	review := func(t *testing.T, id gidx.PrefixedID, status types.AccessRequestStatus, expiresAt *time.Time) types.AccessRequest {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		require.NoError(t, store.LockAccessRequestForUpdate(dbCtx, id))
+
+		req, err := store.UpdateAccessRequestStatus(dbCtx, reviewerID, id, status, "permrbn-rb", expiresAt)
+		require.NoError(t, err, "no error expected updating access request")
+
+		err = store.CommitContext(dbCtx)
+		require.NoError(t, err, "no error expected committing transaction context")
+
+		return req
+	}
+
+	pending := newRequest(t, time.Hour)
+
+	assert.Equal(t, types.AccessRequestPending, pending.Status)
+	assert.Equal(t, time.Hour, pending.ExpiresIn)
+	assert.Nil(t, pending.ExpiresAt)
+
+	expiresAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+
+	expired := review(t, newRequest(t, time.Minute).ID, types.AccessRequestApproved, &expiresAt)
+	permanent := review(t, newRequest(t, 0).ID, types.AccessRequestApproved, nil)
+
+	assert.Equal(t, reviewerID, expired.ReviewedBy)
+	require.NotNil(t, expired.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*expired.ExpiresAt))
+	assert.Nil(t, permanent.ExpiresAt)
+
+	t.Run("Get", func(t *testing.T) {
+		req, err := store.GetAccessRequestByID(ctx, pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pending.ID, req.ID)
+		assert.Equal(t, pending.Reason, req.Reason)
+
+		_, err = store.GetAccessRequestByID(ctx, "permacr-definitely_not_exists")
+		assert.ErrorIs(t, err, storage.ErrAccessRequestNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		reqs, err := store.ListResourceAccessRequests(ctx, resourceID, "", storage.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, reqs, 3)
+
+		reqs, err = store.ListSubjectAccessRequests(ctx, subjectID, types.AccessRequestPending, storage.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, reqs, 1)
+		assert.Equal(t, pending.ID, reqs[0].ID)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		reqs, err := store.ListExpiredAccessRequests(ctx, time.Now(), 10)
+		require.NoError(t, err)
+		require.Len(t, reqs, 1)
+		assert.Equal(t, expired.ID, reqs[0].ID)
+
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err)
+
+		req, err := store.ExpireAccessRequest(dbCtx, expired.ID)
+		require.NoError(t, err)
+		require.NoError(t, store.CommitContext(dbCtx))
+
+		assert.Equal(t, types.AccessRequestExpired, req.Status)
+
+		reqs, err = store.ListExpiredAccessRequests(ctx, time.Now(), 10)
+		require.NoError(t, err)
+		assert.Empty(t, reqs)
+	})
+}
@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// AccessReviewService represents a service for managing access review campaigns
+// in the permissions API storage
+type AccessReviewService interface {
+	// GetAccessReviewByID returns an access review by its prefixed ID
+	// an ErrAccessReviewNotFound error is returned if no review is found
+	GetAccessReviewByID(ctx context.Context, id gidx.PrefixedID) (types.AccessReview, error)
+
+	// ListResourceAccessReviews returns the access reviews of a given resource with the given status,
+	// limited by the provided options. An empty status returns reviews of any status.
+	// an empty slice is returned if no reviews are found
+	ListResourceAccessReviews(
+		ctx context.Context, resourceID gidx.PrefixedID, status types.AccessReviewStatus, opts ListOptions,
+	) ([]types.AccessReview, error)
+
+	// ListAccessReviewItems returns the items of a given access review with the given decision,
+	// limited by the provided options. An empty decision returns items with any decision.
+	// an empty slice is returned if no items are found
+	ListAccessReviewItems(
+		ctx context.Context, reviewID gidx.PrefixedID, decision types.AccessReviewDecision, opts ListOptions,
+	) ([]types.AccessReviewItem, error)
+
+	// CreateAccessReview creates a new open access review in the database along with its pending items
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateAccessReview(ctx context.Context, review types.AccessReview, items []types.AccessReviewItem) (types.AccessReview, error)
+
+	// UpdateAccessReviewItemDecision records a reviewer's decision on an item of an access review.
+	// If the item is not found in the review, an ErrAccessReviewItemNotFound error is returned.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	UpdateAccessReviewItemDecision(
+		ctx context.Context, reviewerID, reviewID, id gidx.PrefixedID, decision types.AccessReviewDecision,
+	) (types.AccessReviewItem, error)
+
+	// CloseAccessReview marks an access review as closed by the given subject.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CloseAccessReview(ctx context.Context, closerID, id gidx.PrefixedID) (types.AccessReview, error)
+
+	// LockAccessReviewForUpdate locks an access review record to be updated to ensure consistency.
+	// If the review is not found, an ErrAccessReviewNotFound error is returned.
+	LockAccessReviewForUpdate(ctx context.Context, id gidx.PrefixedID) error
+}
+
+// accessReviewColumns are the columns scanned by scanAccessReview.
+const accessReviewColumns = `id, resource_id, name, status, created_by, closed_by, created_at, updated_at, closed_at`
+
+// accessReviewItemColumns are the columns scanned by scanAccessReviewItem.
+const accessReviewItemColumns = `id, review_id, rolebinding_id, resource_id, role_id, subject_id, decision,
+	reviewed_by, updated_at`
+
+func scanAccessReview(row rowScanner) (types.AccessReview, error) {
+	var (
+		review   types.AccessReview
+		status   string
+		closedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&review.ID,
+		&review.ResourceID,
+		&review.Name,
+		&status,
+		&review.CreatedBy,
+		&review.ClosedBy,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&closedAt,
+	)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	review.Status = types.AccessReviewStatus(status)
+
+	if closedAt.Valid {
+		review.ClosedAt = &closedAt.Time
+	}
+
+	return review, nil
+}
+
+func scanAccessReviewItem(row rowScanner) (types.AccessReviewItem, error) {
+	var (
+		item     types.AccessReviewItem
+		decision string
+	)
+
+	err := row.Scan(
+		&item.ID,
+		&item.ReviewID,
+		&item.RoleBindingID,
+		&item.ResourceID,
+		&item.RoleID,
+		&item.SubjectID,
+		&decision,
+		&item.ReviewedBy,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return types.AccessReviewItem{}, err
+	}
+
+	item.Decision = types.AccessReviewDecision(decision)
+
+	return item, nil
+}
+
+func (e *engine) GetAccessReviewByID(ctx context.Context, id gidx.PrefixedID) (types.AccessReview, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	review, err := scanAccessReview(db.QueryRowContext(ctx,
+		`SELECT `+accessReviewColumns+` FROM access_reviews WHERE id = $1`,
+		id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessReview{}, fmt.Errorf("%w: %s", ErrAccessReviewNotFound, id.String())
+		}
+
+		return types.AccessReview{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return review, nil
+}
+
+func (e *engine) ListResourceAccessReviews(
+	ctx context.Context, resourceID gidx.PrefixedID, status types.AccessReviewStatus, opts ListOptions,
+) ([]types.AccessReview, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + accessReviewColumns + ` FROM access_reviews WHERE resource_id = $1`
+	args := []any{resourceID.String()}
+
+	if status != "" {
+		args = append(args, string(status))
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close() //nolint:errcheck
+
+	reviews := []types.AccessReview{}
+
+	for rows.Next() {
+		review, err := scanAccessReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (e *engine) ListAccessReviewItems(
+	ctx context.Context, reviewID gidx.PrefixedID, decision types.AccessReviewDecision, opts ListOptions,
+) ([]types.AccessReviewItem, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + accessReviewItemColumns + ` FROM access_review_items WHERE review_id = $1`
+	args := []any{reviewID.String()}
+
+	if decision != "" {
+		args = append(args, string(decision))
+		q += fmt.Sprintf(" AND decision = $%d", len(args))
+	}
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, reviewID.String())
+	}
+	defer rows.Close() //nolint:errcheck
+
+	items := []types.AccessReviewItem{}
+
+	for rows.Next() {
+		item, err := scanAccessReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, reviewID.String())
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (e *engine) CreateAccessReview(
+	ctx context.Context, review types.AccessReview, items []types.AccessReviewItem,
+) (types.AccessReview, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	now := time.Now()
+
+	out, err := scanAccessReview(tx.QueryRowContext(ctx, `
+		INSERT INTO access_reviews (id, resource_id, name, status, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING `+accessReviewColumns,
+		review.ID.String(), review.ResourceID.String(), review.Name,
+		string(types.AccessReviewOpen), review.CreatedBy.String(), now,
+	))
+	if err != nil {
+		return types.AccessReview{}, fmt.Errorf("%w: %s", err, review.ID.String())
+	}
+
+	for _, item := range items {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO access_review_items (id, review_id, rolebinding_id, resource_id, role_id, subject_id, decision, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			item.ID.String(), review.ID.String(), item.RoleBindingID.String(), item.ResourceID.String(),
+			item.RoleID.String(), item.SubjectID.String(), string(types.AccessReviewPending), now,
+		)
+		if err != nil {
+			return types.AccessReview{}, fmt.Errorf("%w: %s", err, item.ID.String())
+		}
+	}
+
+	return out, nil
+}
+
+func (e *engine) UpdateAccessReviewItemDecision(
+	ctx context.Context, reviewerID, reviewID, id gidx.PrefixedID, decision types.AccessReviewDecision,
+) (types.AccessReviewItem, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessReviewItem{}, err
+	}
+
+	item, err := scanAccessReviewItem(tx.QueryRowContext(ctx, `
+		UPDATE access_review_items
+		SET decision = $1, reviewed_by = $2, updated_at = now()
+		WHERE id = $3 AND review_id = $4
+		RETURNING `+accessReviewItemColumns,
+		string(decision), reviewerID.String(), id.String(), reviewID.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessReviewItem{}, fmt.Errorf("%w: %s", ErrAccessReviewItemNotFound, id.String())
+		}
+
+		return types.AccessReviewItem{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return item, nil
+}
+
+func (e *engine) CloseAccessReview(ctx context.Context, closerID, id gidx.PrefixedID) (types.AccessReview, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessReview{}, err
+	}
+
+	review, err := scanAccessReview(tx.QueryRowContext(ctx, `
+		UPDATE access_reviews
+		SET status = $1, closed_by = $2, closed_at = now(), updated_at = now()
+		WHERE id = $3
+		RETURNING `+accessReviewColumns,
+		string(types.AccessReviewClosed), closerID.String(), id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessReview{}, fmt.Errorf("%w: %s", ErrAccessReviewNotFound, id.String())
+		}
+
+		return types.AccessReview{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return review, nil
+}
+
+func (e *engine) LockAccessReviewForUpdate(ctx context.Context, id gidx.PrefixedID) error {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `SELECT 1 FROM access_reviews WHERE id = $1 FOR UPDATE`, id.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrAccessReviewNotFound, id.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestAccessReviews(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	creatorID := gidx.PrefixedID("idntusr-creator")
+	reviewerID := gidx.PrefixedID("idntusr-reviewer")
+	resourceID := gidx.PrefixedID("tnntten-tenant")
+
+	items := []types.AccessReviewItem{
+		{
+			ID:            gidx.MustNewID("permari"),
+			RoleBindingID: "permrbn-rb",
+			ResourceID:    resourceID,
+			RoleID:        "permrv2-role",
+			SubjectID:     "idntusr-user1",
+		},
+		{
+			ID:            gidx.MustNewID("permari"),
+			RoleBindingID: "permrbn-rb",
+			ResourceID:    resourceID,
+			RoleID:        "permrv2-role",
+			SubjectID:     "idntusr-user2",
+		},
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	review, err := store.CreateAccessReview(dbCtx, types.AccessReview{
+		ID:         gidx.MustNewID("permarv"),
+		ResourceID: resourceID,
+		Name:       t.Name(),
+		CreatedBy:  creatorID,
+	}, items)
+	require.NoError(t, err, "no error expected creating access review")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	assert.Equal(t, types.AccessReviewOpen, review.Status)
+	assert.Equal(t, creatorID, review.CreatedBy)
+	assert.Nil(t, review.ClosedAt)
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := store.GetAccessReviewByID(ctx, review.ID)
+		require.NoError(t, err)
+		assert.Equal(t, review.Name, got.Name)
+
+		_, err = store.GetAccessReviewByID(ctx, "permarv-definitely_not_exists")
+		assert.ErrorIs(t, err, storage.ErrAccessReviewNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		reviews, err := store.ListResourceAccessReviews(ctx, resourceID, types.AccessReviewOpen, storage.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, reviews, 1)
+		assert.Equal(t, review.ID, reviews[0].ID)
+
+		reviewItems, err := store.ListAccessReviewItems(ctx, review.ID, types.AccessReviewPending, storage.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, reviewItems, 2)
+	})
+
+	t.Run("Decide", func(t *testing.T) {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, store.LockAccessReviewForUpdate(dbCtx, review.ID))
+
+		item, err := store.UpdateAccessReviewItemDecision(dbCtx, reviewerID, review.ID, items[0].ID, types.AccessReviewRevoke)
+		require.NoError(t, err)
+
+		_, err = store.UpdateAccessReviewItemDecision(dbCtx, reviewerID, "permarv-other", items[1].ID, types.AccessReviewKeep)
+		assert.ErrorIs(t, err, storage.ErrAccessReviewItemNotFound)
+
+		require.NoError(t, store.RollbackContext(dbCtx))
+
+		dbCtx, err = store.BeginContext(ctx)
+		require.NoError(t, err)
+
+		item, err = store.UpdateAccessReviewItemDecision(dbCtx, reviewerID, review.ID, item.ID, types.AccessReviewRevoke)
+		require.NoError(t, err)
+		require.NoError(t, store.CommitContext(dbCtx))
+
+		assert.Equal(t, types.AccessReviewRevoke, item.Decision)
+		assert.Equal(t, reviewerID, item.ReviewedBy)
+
+		revoked, err := store.ListAccessReviewItems(ctx, review.ID, types.AccessReviewRevoke, storage.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		assert.Equal(t, items[0].SubjectID, revoked[0].SubjectID)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err)
+
+		closed, err := store.CloseAccessReview(dbCtx, reviewerID, review.ID)
+		require.NoError(t, err)
+		require.NoError(t, store.CommitContext(dbCtx))
+
+		assert.Equal(t, types.AccessReviewClosed, closed.Status)
+		assert.Equal(t, reviewerID, closed.ClosedBy)
+		assert.NotNil(t, closed.ClosedAt)
+	})
+}
@@ -28,12 +28,12 @@ func beginTxContext(ctx context.Context, db DB) (context.Context, error) {
 	return out, nil
 }
 
-func getContextTx(ctx context.Context) (*sql.Tx, error) {
+func getContextTx(ctx context.Context) (timedTx, error) {
 	switch v := ctx.Value(txKey).(type) {
 	case *sql.Tx:
-		return v, nil
+		return timedTx{v}, nil
 	case nil:
-		return nil, ErrorMissingContextTx
+		return timedTx{}, ErrorMissingContextTx
 	default:
 		panic("unknown type for context transaction")
 	}
@@ -46,7 +46,7 @@ func getContextDBQuery(ctx context.Context, def DBQuery) (DBQuery, error) {
 	case nil:
 		return tx, nil
 	case ErrorMissingContextTx:
-		return def, nil
+		return timedDBQuery{def}, nil
 	default:
 		return nil, err
 	}
@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS idempotency_key CHARACTER VARYING(255);
+ALTER TABLE rolebindings ADD COLUMN IF NOT EXISTS idempotency_key CHARACTER VARYING(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "roles_created_by_idempotency_key" ON "roles" ("created_by", "idempotency_key");
+CREATE UNIQUE INDEX IF NOT EXISTS "rolebindings_created_by_idempotency_key" ON "rolebindings" ("created_by", "idempotency_key");
+
+-- +goose Down
+
+DROP INDEX IF EXISTS "rolebindings_created_by_idempotency_key";
+DROP INDEX IF EXISTS "roles_created_by_idempotency_key";
+
+ALTER TABLE rolebindings DROP COLUMN IF EXISTS idempotency_key;
+ALTER TABLE roles DROP COLUMN IF EXISTS idempotency_key;
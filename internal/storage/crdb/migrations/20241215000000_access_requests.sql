@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- create "access_requests" table
+CREATE TABLE IF NOT EXISTS "access_requests" (
+  "id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "role_id" character varying NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "reason" character varying NOT NULL DEFAULT '',
+  "status" character varying NOT NULL,
+  "expires_in" bigint NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NULL,
+  "rolebinding_id" character varying NOT NULL DEFAULT '',
+  "reviewed_by" character varying NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "access_requests_resource_id_status" to table: "access_requests"
+CREATE INDEX IF NOT EXISTS "access_requests_resource_id_status" ON "access_requests" ("resource_id", "status");
+-- create index "access_requests_subject_id_status" to table: "access_requests"
+CREATE INDEX IF NOT EXISTS "access_requests_subject_id_status" ON "access_requests" ("subject_id", "status");
+-- create index "access_requests_status_expires_at" to table: "access_requests"
+CREATE INDEX IF NOT EXISTS "access_requests_status_expires_at" ON "access_requests" ("status", "expires_at");
+
+-- +goose Down
+-- reverse: create index "access_requests_status_expires_at" to table: "access_requests"
+DROP INDEX IF EXISTS "access_requests_status_expires_at";
+-- reverse: create index "access_requests_subject_id_status" to table: "access_requests"
+DROP INDEX IF EXISTS "access_requests_subject_id_status";
+-- reverse: create index "access_requests_resource_id_status" to table: "access_requests"
+DROP INDEX IF EXISTS "access_requests_resource_id_status";
+-- reverse: create "access_requests" table
+DROP TABLE IF EXISTS "access_requests";
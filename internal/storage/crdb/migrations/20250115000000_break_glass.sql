@@ -0,0 +1,7 @@
+-- +goose Up
+-- modify "access_requests" table
+ALTER TABLE "access_requests" ADD COLUMN IF NOT EXISTS "break_glass" boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+-- reverse: modify "access_requests" table
+ALTER TABLE "access_requests" DROP COLUMN IF EXISTS "break_glass";
@@ -28,14 +28,33 @@ var (
 
 	// ErrRoleBindingNotFound is returned when no role binding is found when retrieving or deleting a role binding.
 	ErrRoleBindingNotFound = errors.New("role binding not found")
+
+	// ErrRoleBindingRequestNotFound is returned when no role binding request is found when retrieving or updating a request.
+	ErrRoleBindingRequestNotFound = errors.New("role binding request not found")
+
+	// ErrAccessRequestNotFound is returned when no access request is found when retrieving or updating a request.
+	ErrAccessRequestNotFound = errors.New("access request not found")
+
+	// ErrAccessReviewNotFound is returned when no access review is found when retrieving or updating a review.
+	ErrAccessReviewNotFound = errors.New("access review not found")
+
+	// ErrAccessReviewItemNotFound is returned when no access review item is found when updating an item.
+	ErrAccessReviewItemNotFound = errors.New("access review item not found")
+
+	// ErrIdempotencyKeyTaken is returned when the idempotency key provided is already used by the same actor.
+	ErrIdempotencyKeyTaken = errors.New("idempotency key already taken")
 )
 
 const (
 	// Postgres error codes: https://www.postgresql.org/docs/11/errcodes-appendix.html
 	pgErrCodeUniqueViolation = "23505"
 
-	pqIndexRolesPrimaryKey     = "roles_pkey"
-	pqIndexRolesResourceIDName = "roles_resource_id_name"
+	pqIndexRolesPrimaryKey            = "roles_pkey"
+	pqIndexRolesResourceIDName        = "roles_resource_id_name"
+	pqIndexRolesIdempotencyKey        = "roles_created_by_idempotency_key"
+	pqIndexRoleBindingsIdempotencyKey = "rolebindings_created_by_idempotency_key"
+
+	pqIndexRoleBindingRequestsIdempotencyKey = "rolebinding_requests_requested_by_idempotency_key"
 )
 
 // pqIsRoleAlreadyExistsError checks that the provided error is a postgres error.
@@ -61,3 +80,19 @@ func pqIsRoleNameTakenError(err error) bool {
 
 	return false
 }
+
+// pqIsIdempotencyKeyTakenError checks that the provided error is a postgres error.
+// If so, checks if postgres threw a unique_violation error on the roles, rolebindings or rolebinding_requests
+// idempotency key index.
+// If postgres has raised a unique violation error on these indexes it means a record already exists
+// with the same creator and idempotency key combination.
+func pqIsIdempotencyKeyTakenError(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == pgErrCodeUniqueViolation &&
+			(pgErr.ConstraintName == pqIndexRolesIdempotencyKey ||
+				pgErr.ConstraintName == pqIndexRoleBindingsIdempotencyKey ||
+				pgErr.ConstraintName == pqIndexRoleBindingRequestsIdempotencyKey)
+	}
+
+	return false
+}
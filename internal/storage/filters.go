@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// TimeFilter filters records by their creation and update times.
+// Zero times are not filtered on.
+type TimeFilter struct {
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+func (f TimeFilter) apply(query string, args []any) (string, []any) {
+	for _, cond := range []struct {
+		column string
+		op     string
+		value  time.Time
+	}{
+		{"created_at", ">=", f.CreatedAfter},
+		{"created_at", "<", f.CreatedBefore},
+		{"updated_at", ">=", f.UpdatedAfter},
+		{"updated_at", "<", f.UpdatedBefore},
+	} {
+		if cond.value.IsZero() {
+			continue
+		}
+
+		args = append(args, cond.value)
+		query += fmt.Sprintf(" AND %s %s $%d", cond.column, cond.op, len(args))
+	}
+
+	return query, args
+}
+
+// RoleFilter filters the roles returned by role listings.
+// Zero value fields are not filtered on.
+type RoleFilter struct {
+	TimeFilter
+
+	NamePrefix string
+	Manager    *string
+	CreatedBy  gidx.PrefixedID
+}
+
+// apply appends the filter conditions to the provided query, which must end with its WHERE clause,
+// returning the updated query and arguments.
+func (f RoleFilter) apply(query string, args []any) (string, []any) {
+	if f.NamePrefix != "" {
+		args = append(args, likeEscaper.Replace(f.NamePrefix)+"%")
+		query += fmt.Sprintf(" AND name LIKE $%d", len(args))
+	}
+
+	if f.Manager != nil {
+		args = append(args, *f.Manager)
+		query += fmt.Sprintf(" AND manager = $%d", len(args))
+	}
+
+	if f.CreatedBy != "" {
+		args = append(args, f.CreatedBy.String())
+		query += fmt.Sprintf(" AND created_by = $%d", len(args))
+	}
+
+	return f.TimeFilter.apply(query, args)
+}
+
+// RoleBindingFilter filters the role bindings returned by role binding listings.
+// Zero value fields are not filtered on.
+type RoleBindingFilter struct {
+	TimeFilter
+
+	Manager   *string
+	CreatedBy gidx.PrefixedID
+}
+
+// apply appends the filter conditions to the provided query, which must end with its WHERE clause,
+// returning the updated query and arguments.
+func (f RoleBindingFilter) apply(query string, args []any) (string, []any) {
+	if f.Manager != nil {
+		args = append(args, *f.Manager)
+		query += fmt.Sprintf(" AND manager = $%d", len(args))
+	}
+
+	if f.CreatedBy != "" {
+		args = append(args, f.CreatedBy.String())
+		query += fmt.Sprintf(" AND created_by = $%d", len(args))
+	}
+
+	return f.TimeFilter.apply(query, args)
+}
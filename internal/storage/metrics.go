@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "permissions_api",
+	Name:      "storage_query_duration_seconds",
+	Help:      "Latency of database queries by operation.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"operation"})
+
+func observeQuery(operation string, start time.Time) {
+	queryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// timedDBQuery records the latency of the queries run through it.
+type timedDBQuery struct {
+	DBQuery
+}
+
+func (q timedDBQuery) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer observeQuery("query", time.Now())
+
+	return q.DBQuery.QueryContext(ctx, query, args...)
+}
+
+func (q timedDBQuery) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer observeQuery("query_row", time.Now())
+
+	return q.DBQuery.QueryRowContext(ctx, query, args...)
+}
+
+func (q timedDBQuery) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer observeQuery("exec", time.Now())
+
+	return q.DBQuery.ExecContext(ctx, query, args...)
+}
+
+// timedTx is a transaction which records the latency of the queries run in it.
+type timedTx struct {
+	*sql.Tx
+}
+
+func (tx timedTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return timedDBQuery{tx.Tx}.QueryContext(ctx, query, args...)
+}
+
+func (tx timedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return timedDBQuery{tx.Tx}.QueryRowContext(ctx, query, args...)
+}
+
+func (tx timedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return timedDBQuery{tx.Tx}.ExecContext(ctx, query, args...)
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// ListOptions limits the records returned by list queries.
+// Records are ordered by ID so the ID of the last record returned may be used to request the following page.
+type ListOptions struct {
+	// Limit is the maximum number of records returned. A limit of zero returns all records.
+	Limit int
+	// AfterID returns only records with an ID after the provided ID.
+	AfterID gidx.PrefixedID
+}
+
+// apply appends the list options conditions, ordering and limit to the provided query, which must end with
+// its WHERE clause, returning the updated query and arguments.
+func (o ListOptions) apply(query string, args []any) (string, []any) {
+	if o.AfterID != "" {
+		args = append(args, o.AfterID.String())
+		query += fmt.Sprintf(" AND id > $%d", len(args))
+	}
+
+	query += " ORDER BY id ASC"
+
+	if o.Limit > 0 {
+		args = append(args, o.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return query, args
+}
@@ -0,0 +1,27 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- create "rolebinding_requests" table
+CREATE TABLE IF NOT EXISTS "rolebinding_requests" (
+  "id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "role_id" character varying NOT NULL,
+  "subject_ids" character varying NOT NULL,
+  "manager" character varying(128) NOT NULL DEFAULT '',
+  "status" character varying NOT NULL,
+  "rolebinding_id" character varying NOT NULL DEFAULT '',
+  "requested_by" character varying NOT NULL,
+  "reviewed_by" character varying NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "rolebinding_requests_resource_id_status" to table: "rolebinding_requests"
+CREATE INDEX IF NOT EXISTS "rolebinding_requests_resource_id_status" ON "rolebinding_requests" ("resource_id", "status");
+
+-- +goose Down
+-- reverse: create index "rolebinding_requests_resource_id_status" to table: "rolebinding_requests"
+DROP INDEX IF EXISTS "rolebinding_requests_resource_id_status";
+-- reverse: create "rolebinding_requests" table
+DROP TABLE IF EXISTS "rolebinding_requests";
@@ -0,0 +1,46 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- create "access_reviews" table
+CREATE TABLE IF NOT EXISTS "access_reviews" (
+  "id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "name" character varying NOT NULL DEFAULT '',
+  "status" character varying NOT NULL,
+  "created_by" character varying NOT NULL,
+  "closed_by" character varying NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  "closed_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "access_reviews_resource_id_status" to table: "access_reviews"
+CREATE INDEX IF NOT EXISTS "access_reviews_resource_id_status" ON "access_reviews" ("resource_id", "status");
+
+-- create "access_review_items" table
+CREATE TABLE IF NOT EXISTS "access_review_items" (
+  "id" character varying NOT NULL,
+  "review_id" character varying NOT NULL,
+  "rolebinding_id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "role_id" character varying NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "decision" character varying NOT NULL,
+  "reviewed_by" character varying NOT NULL DEFAULT '',
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "access_review_items_review_id_decision" to table: "access_review_items"
+CREATE INDEX IF NOT EXISTS "access_review_items_review_id_decision" ON "access_review_items" ("review_id", "decision");
+
+-- +goose Down
+-- reverse: create index "access_review_items_review_id_decision" to table: "access_review_items"
+DROP INDEX IF EXISTS "access_review_items_review_id_decision";
+-- reverse: create "access_review_items" table
+DROP TABLE IF EXISTS "access_review_items";
+-- reverse: create index "access_reviews_resource_id_status" to table: "access_reviews"
+DROP INDEX IF EXISTS "access_reviews_resource_id_status";
+-- reverse: create "access_reviews" table
+DROP TABLE IF EXISTS "access_reviews";
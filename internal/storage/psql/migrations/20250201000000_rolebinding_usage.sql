@@ -0,0 +1,14 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+-- create "rolebinding_usage" table
+CREATE TABLE IF NOT EXISTS "rolebinding_usage" (
+  "rolebinding_id" character varying NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "last_used_at" timestamptz NOT NULL,
+  PRIMARY KEY ("rolebinding_id", "subject_id")
+);
+
+-- +goose Down
+-- reverse: create "rolebinding_usage" table
+DROP TABLE IF EXISTS "rolebinding_usage";
@@ -0,0 +1,12 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+ALTER TABLE rolebinding_requests ADD COLUMN IF NOT EXISTS idempotency_key CHARACTER VARYING(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "rolebinding_requests_requested_by_idempotency_key" ON "rolebinding_requests" ("requested_by", "idempotency_key");
+
+-- +goose Down
+
+DROP INDEX IF EXISTS "rolebinding_requests_requested_by_idempotency_key";
+
+ALTER TABLE rolebinding_requests DROP COLUMN IF EXISTS idempotency_key;
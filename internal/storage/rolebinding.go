@@ -15,13 +15,18 @@ import (
 // RoleBindingService represents a service for managing role bindings in the
 // permissions API storage
 type RoleBindingService interface {
-	// ListResourceRoleBindings returns all role bindings for a given resource
+	// ListResourceRoleBindings returns the role bindings for a given resource, limited by the provided options
 	// an empty slice is returned if no role bindings are found
-	ListResourceRoleBindings(ctx context.Context, resourceID gidx.PrefixedID) ([]types.RoleBinding, error)
+	ListResourceRoleBindings(ctx context.Context, resourceID gidx.PrefixedID, opts ListOptions) ([]types.RoleBinding, error)
 
-	// ListManagerResourceRoleBindings returns all role bindings for a given resource and manager
+	// ListManagerResourceRoleBindings returns the role bindings for a given resource and manager, limited by the provided options
 	// an empty slice is returned if no role bindings are found
-	ListManagerResourceRoleBindings(ctx context.Context, manager string, resourceID gidx.PrefixedID) ([]types.RoleBinding, error)
+	ListManagerResourceRoleBindings(ctx context.Context, manager string, resourceID gidx.PrefixedID, opts ListOptions) ([]types.RoleBinding, error)
+
+	// ListRoleBindingsByID returns the role bindings with the given IDs which match the filter,
+	// limited by the provided options
+	// an empty slice is returned if no role bindings are found
+	ListRoleBindingsByID(ctx context.Context, ids []gidx.PrefixedID, filter RoleBindingFilter, opts ListOptions) ([]types.RoleBinding, error)
 
 	// GetRoleBindingByID returns a role binding by its prefixed ID
 	// an ErrRoleBindingNotFound error is returned if no role binding is found
@@ -40,7 +45,7 @@ type RoleBindingService interface {
 	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
 	UpdateRoleBinding(ctx context.Context, actorID, rbID gidx.PrefixedID) (types.RoleBinding, error)
 
-	// DeleteRoleBinding deletes a role binding from the database
+	// DeleteRoleBinding deletes a role binding and its recorded usage from the database
 	// This method must be called with a context returned from BeginContext.
 	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
 	DeleteRoleBinding(ctx context.Context, id gidx.PrefixedID) error
@@ -48,6 +53,17 @@ type RoleBindingService interface {
 	// LockRoleBindingForUpdate locks a role binding record to be updated to ensure consistency.
 	// If the role binding is not found, an ErrRoleBindingNotFound error is returned.
 	LockRoleBindingForUpdate(ctx context.Context, id gidx.PrefixedID) error
+
+	// GetRoleBindingByIdempotencyKey returns the role binding created by the actor with the idempotency key
+	// an ErrRoleBindingNotFound error is returned if no role binding is found
+	GetRoleBindingByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (types.RoleBinding, error)
+
+	// SetRoleBindingIdempotencyKey records the idempotency key a role binding was created with.
+	// If the actor has already used the key for another role binding, an ErrIdempotencyKeyTaken error is returned.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	SetRoleBindingIdempotencyKey(ctx context.Context, actorID, rbID gidx.PrefixedID, key string) error
 }
 
 func (e *engine) GetRoleBindingByID(ctx context.Context, id gidx.PrefixedID) (types.RoleBinding, error) {
@@ -82,17 +98,82 @@ func (e *engine) GetRoleBindingByID(ctx context.Context, id gidx.PrefixedID) (ty
 	return roleBinding, nil
 }
 
-func (e *engine) ListResourceRoleBindings(ctx context.Context, resourceID gidx.PrefixedID) ([]types.RoleBinding, error) {
+func (e *engine) GetRoleBindingByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (types.RoleBinding, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	var roleBinding types.RoleBinding
+
+	err = db.QueryRowContext(ctx, `
+		SELECT id, resource_id, manager, created_by, updated_by, created_at, updated_at
+		FROM rolebindings WHERE created_by = $1 AND idempotency_key = $2
+		`, actorID.String(), key,
+	).Scan(
+		&roleBinding.ID,
+		&roleBinding.ResourceID,
+		&roleBinding.Manager,
+		&roleBinding.CreatedBy,
+		&roleBinding.UpdatedBy,
+		&roleBinding.CreatedAt,
+		&roleBinding.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.RoleBinding{}, fmt.Errorf("%w: idempotency key %s", ErrRoleBindingNotFound, key)
+		}
+
+		return types.RoleBinding{}, err
+	}
+
+	return roleBinding, nil
+}
+
+func (e *engine) SetRoleBindingIdempotencyKey(ctx context.Context, actorID, rbID gidx.PrefixedID, key string) error {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE rolebindings SET idempotency_key = $3
+		WHERE id = $1 AND created_by = $2
+		`, rbID.String(), actorID.String(), key,
+	)
+	if err != nil {
+		if pqIsIdempotencyKeyTakenError(err) {
+			return fmt.Errorf("%w: %s", ErrIdempotencyKeyTaken, key)
+		}
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrRoleBindingNotFound, rbID.String())
+	}
+
+	return nil
+}
+
+func (e *engine) ListResourceRoleBindings(ctx context.Context, resourceID gidx.PrefixedID, opts ListOptions) ([]types.RoleBinding, error) {
 	db, err := getContextDBQuery(ctx, e)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := db.QueryContext(ctx, `
+	q, args := opts.apply(`
 		SELECT id, resource_id, manager, created_by, updated_by, created_at, updated_at
-		FROM rolebindings WHERE resource_id = $1 ORDER BY created_at ASC
-		`, resourceID.String(),
+		FROM rolebindings WHERE resource_id = $1`,
+		[]any{resourceID.String()},
 	)
+
+	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
 	}
@@ -122,17 +203,19 @@ func (e *engine) ListResourceRoleBindings(ctx context.Context, resourceID gidx.P
 	return roleBindings, nil
 }
 
-func (e *engine) ListManagerResourceRoleBindings(ctx context.Context, manager string, resourceID gidx.PrefixedID) ([]types.RoleBinding, error) {
+func (e *engine) ListManagerResourceRoleBindings(ctx context.Context, manager string, resourceID gidx.PrefixedID, opts ListOptions) ([]types.RoleBinding, error) {
 	db, err := getContextDBQuery(ctx, e)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := db.QueryContext(ctx, `
+	q, args := opts.apply(`
 		SELECT id, resource_id, manager, created_by, updated_by, created_at, updated_at
-		FROM rolebindings WHERE manager = $1 AND resource_id = $1 ORDER BY created_at ASC
-		`, manager, resourceID.String(),
+		FROM rolebindings WHERE manager = $1 AND resource_id = $2`,
+		[]any{manager, resourceID.String()},
 	)
+
+	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
 	}
@@ -162,6 +245,56 @@ func (e *engine) ListManagerResourceRoleBindings(ctx context.Context, manager st
 	return roleBindings, nil
 }
 
+func (e *engine) ListRoleBindingsByID(ctx context.Context, ids []gidx.PrefixedID, filter RoleBindingFilter, opts ListOptions) ([]types.RoleBinding, error) {
+	if len(ids) == 0 {
+		return []types.RoleBinding{}, nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(ids)
+
+	q, args := filter.apply(fmt.Sprintf(`
+		SELECT id, resource_id, manager, created_by, updated_by, created_at, updated_at
+		FROM rolebindings WHERE id IN (%s)`, inClause),
+		args,
+	)
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	roleBindings := []types.RoleBinding{}
+
+	for rows.Next() {
+		var roleBinding types.RoleBinding
+
+		err = rows.Scan(
+			&roleBinding.ID,
+			&roleBinding.ResourceID,
+			&roleBinding.Manager,
+			&roleBinding.CreatedBy,
+			&roleBinding.UpdatedBy,
+			&roleBinding.CreatedAt,
+			&roleBinding.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		roleBindings = append(roleBindings, roleBinding)
+	}
+
+	return roleBindings, rows.Err()
+}
+
 func (e *engine) CreateRoleBinding(ctx context.Context, actorID, rbID, resourceID gidx.PrefixedID, manager string) (types.RoleBinding, error) {
 	tx, err := getContextTx(ctx)
 	if err != nil {
@@ -248,6 +381,13 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, id gidx.PrefixedID) erro
 		return fmt.Errorf("%w: %s", ErrRoleBindingNotFound, id.String())
 	}
 
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM rolebinding_usage WHERE rolebinding_id = $1
+		`, id.String(),
+	); err != nil {
+		return fmt.Errorf("%w: %s", err, id.String())
+	}
+
 	return nil
 }
 
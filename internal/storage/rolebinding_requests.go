@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// subjectIDsSeparator separates the subject IDs of a role binding request in the subject_ids column.
+const subjectIDsSeparator = ","
+
+// RoleBindingRequestService represents a service for managing role binding requests
+// awaiting approval in the permissions API storage
+type RoleBindingRequestService interface {
+	// GetRoleBindingRequestByID returns a role binding request by its prefixed ID
+	// an ErrRoleBindingRequestNotFound error is returned if no request is found
+	GetRoleBindingRequestByID(ctx context.Context, id gidx.PrefixedID) (types.RoleBindingRequest, error)
+
+	// ListResourceRoleBindingRequests returns the role binding requests for a given resource with the given status,
+	// limited by the provided options. An empty status returns requests of any status.
+	// an empty slice is returned if no requests are found
+	ListResourceRoleBindingRequests(
+		ctx context.Context, resourceID gidx.PrefixedID, status types.RoleBindingRequestStatus, opts ListOptions,
+	) ([]types.RoleBindingRequest, error)
+
+	// CreateRoleBindingRequest creates a new pending role binding request in the database
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateRoleBindingRequest(ctx context.Context, actorID gidx.PrefixedID, req types.RoleBindingRequest) (types.RoleBindingRequest, error)
+
+	// GetRoleBindingRequestByIdempotencyKey returns the role binding request made by the actor with the
+	// idempotency key. An ErrRoleBindingRequestNotFound error is returned if no request is found.
+	GetRoleBindingRequestByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (types.RoleBindingRequest, error)
+
+	// SetRoleBindingRequestIdempotencyKey records the idempotency key a role binding request was made with.
+	// If the actor has already used the key for another request, an ErrIdempotencyKeyTaken error is returned.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	SetRoleBindingRequestIdempotencyKey(ctx context.Context, actorID, id gidx.PrefixedID, key string) error
+
+	// UpdateRoleBindingRequestStatus records the review of a role binding request, along with
+	// the role binding created when the request is approved.
+	//
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	UpdateRoleBindingRequestStatus(
+		ctx context.Context, reviewerID, id gidx.PrefixedID, status types.RoleBindingRequestStatus, rbID gidx.PrefixedID,
+	) (types.RoleBindingRequest, error)
+
+	// LockRoleBindingRequestForUpdate locks a role binding request record to be updated to ensure consistency.
+	// If the request is not found, an ErrRoleBindingRequestNotFound error is returned.
+	LockRoleBindingRequestForUpdate(ctx context.Context, id gidx.PrefixedID) error
+}
+
+// roleBindingRequestColumns are the columns scanned by scanRoleBindingRequest.
+const roleBindingRequestColumns = `id, resource_id, role_id, subject_ids, manager, status, rolebinding_id,
+	requested_by, reviewed_by, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRoleBindingRequest(row rowScanner) (types.RoleBindingRequest, error) {
+	var (
+		req        types.RoleBindingRequest
+		subjectIDs string
+		status     string
+	)
+
+	err := row.Scan(
+		&req.ID,
+		&req.ResourceID,
+		&req.RoleID,
+		&subjectIDs,
+		&req.Manager,
+		&status,
+		&req.RoleBindingID,
+		&req.RequestedBy,
+		&req.ReviewedBy,
+		&req.CreatedAt,
+		&req.UpdatedAt,
+	)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	req.Status = types.RoleBindingRequestStatus(status)
+
+	if subjectIDs != "" {
+		for _, id := range strings.Split(subjectIDs, subjectIDsSeparator) {
+			req.SubjectIDs = append(req.SubjectIDs, gidx.PrefixedID(id))
+		}
+	}
+
+	return req, nil
+}
+
+func (e *engine) GetRoleBindingRequestByID(ctx context.Context, id gidx.PrefixedID) (types.RoleBindingRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, err := scanRoleBindingRequest(db.QueryRowContext(ctx,
+		`SELECT `+roleBindingRequestColumns+` FROM rolebinding_requests WHERE id = $1`,
+		id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.RoleBindingRequest{}, fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, id.String())
+		}
+
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) ListResourceRoleBindingRequests(
+	ctx context.Context, resourceID gidx.PrefixedID, status types.RoleBindingRequestStatus, opts ListOptions,
+) ([]types.RoleBindingRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `SELECT ` + roleBindingRequestColumns + ` FROM rolebinding_requests WHERE resource_id = $1`
+	args := []any{resourceID.String()}
+
+	if status != "" {
+		args = append(args, string(status))
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close() //nolint:errcheck
+
+	reqs := []types.RoleBindingRequest{}
+
+	for rows.Next() {
+		req, err := scanRoleBindingRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, rows.Err()
+}
+
+func (e *engine) CreateRoleBindingRequest(ctx context.Context, actorID gidx.PrefixedID, req types.RoleBindingRequest) (types.RoleBindingRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	subjectIDs := make([]string, len(req.SubjectIDs))
+
+	for i, id := range req.SubjectIDs {
+		subjectIDs[i] = id.String()
+	}
+
+	out, err := scanRoleBindingRequest(tx.QueryRowContext(ctx, `
+		INSERT INTO rolebinding_requests (id, resource_id, role_id, subject_ids, manager, status, requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING `+roleBindingRequestColumns,
+		req.ID.String(), req.ResourceID.String(), req.RoleID.String(), strings.Join(subjectIDs, subjectIDsSeparator),
+		req.Manager, string(types.RoleBindingRequestPending), actorID.String(), time.Now(),
+	))
+	if err != nil {
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: %s", err, req.ID.String())
+	}
+
+	return out, nil
+}
+
+func (e *engine) GetRoleBindingRequestByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (types.RoleBindingRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, err := scanRoleBindingRequest(db.QueryRowContext(ctx,
+		`SELECT `+roleBindingRequestColumns+` FROM rolebinding_requests WHERE requested_by = $1 AND idempotency_key = $2`,
+		actorID.String(), key,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.RoleBindingRequest{}, fmt.Errorf("%w: idempotency key %s", ErrRoleBindingRequestNotFound, key)
+		}
+
+		return types.RoleBindingRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) SetRoleBindingRequestIdempotencyKey(ctx context.Context, actorID, id gidx.PrefixedID, key string) error {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE rolebinding_requests SET idempotency_key = $3
+		WHERE id = $1 AND requested_by = $2
+		`, id.String(), actorID.String(), key,
+	)
+	if err != nil {
+		if pqIsIdempotencyKeyTakenError(err) {
+			return fmt.Errorf("%w: %s", ErrIdempotencyKeyTaken, key)
+		}
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, id.String())
+	}
+
+	return nil
+}
+
+func (e *engine) UpdateRoleBindingRequestStatus(
+	ctx context.Context, reviewerID, id gidx.PrefixedID, status types.RoleBindingRequestStatus, rbID gidx.PrefixedID,
+) (types.RoleBindingRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.RoleBindingRequest{}, err
+	}
+
+	req, err := scanRoleBindingRequest(tx.QueryRowContext(ctx, `
+		UPDATE rolebinding_requests
+		SET status = $1, rolebinding_id = $2, reviewed_by = $3, updated_at = now()
+		WHERE id = $4
+		RETURNING `+roleBindingRequestColumns,
+		string(status), rbID.String(), reviewerID.String(), id.String(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.RoleBindingRequest{}, fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, id.String())
+		}
+
+		return types.RoleBindingRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) LockRoleBindingRequestForUpdate(ctx context.Context, id gidx.PrefixedID) error {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `SELECT 1 FROM rolebinding_requests WHERE id = $1 FOR UPDATE`, id.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrRoleBindingRequestNotFound, id.String())
+	}
+
+	return nil
+}
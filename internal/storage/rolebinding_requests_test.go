@@ -0,0 +1,131 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestRoleBindingRequests(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	reviewerID := gidx.PrefixedID("idntusr-reviewer")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+
+	newRequest := func(t *testing.T) types.RoleBindingRequest {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		req, err := store.CreateRoleBindingRequest(dbCtx, actorID, types.RoleBindingRequest{
+			ID:         gidx.MustNewID("permrbr"),
+			ResourceID: resourceID,
+			RoleID:     "permrv2-role",
+			SubjectIDs: []gidx.PrefixedID{"idntusr-a", "idntusr-b"},
+			Manager:    t.Name(),
+		})
+		require.NoError(t, err, "no error expected creating role binding request")
+
+		err = store.CommitContext(dbCtx)
+		require.NoError(t, err, "no error expected committing transaction context")
+
+		return req
+	}
+
+	pending := newRequest(t)
+	reviewed := newRequest(t)
+
+	assert.Equal(t, types.RoleBindingRequestPending, pending.Status)
+	assert.Equal(t, actorID, pending.RequestedBy)
+	assert.Equal(t, []gidx.PrefixedID{"idntusr-a", "idntusr-b"}, pending.SubjectIDs)
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	require.NoError(t, store.LockRoleBindingRequestForUpdate(dbCtx, reviewed.ID))
+
+	reviewed, err = store.UpdateRoleBindingRequestStatus(dbCtx, reviewerID, reviewed.ID, types.RoleBindingRequestApproved, "permrbn-rb")
+	require.NoError(t, err, "no error expected updating role binding request")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	assert.Equal(t, types.RoleBindingRequestApproved, reviewed.Status)
+	assert.Equal(t, reviewerID, reviewed.ReviewedBy)
+	assert.Equal(t, gidx.PrefixedID("permrbn-rb"), reviewed.RoleBindingID)
+
+	t.Run("Get", func(t *testing.T) {
+		req, err := store.GetRoleBindingRequestByID(ctx, pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pending, req)
+
+		_, err = store.GetRoleBindingRequestByID(ctx, "permrbr-definitely_not_exists")
+		assert.ErrorIs(t, err, storage.ErrRoleBindingRequestNotFound)
+	})
+
+	t.Run("IdempotencyKey", func(t *testing.T) {
+		_, err := store.GetRoleBindingRequestByIdempotencyKey(ctx, actorID, "request-key")
+		assert.ErrorIs(t, err, storage.ErrRoleBindingRequestNotFound)
+
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		require.NoError(t, store.SetRoleBindingRequestIdempotencyKey(dbCtx, actorID, pending.ID, "request-key"))
+		require.NoError(t, store.CommitContext(dbCtx))
+
+		req, err := store.GetRoleBindingRequestByIdempotencyKey(ctx, actorID, "request-key")
+		require.NoError(t, err)
+		assert.Equal(t, pending.ID, req.ID)
+
+		// keys are scoped to the requester
+		_, err = store.GetRoleBindingRequestByIdempotencyKey(ctx, reviewerID, "request-key")
+		assert.ErrorIs(t, err, storage.ErrRoleBindingRequestNotFound)
+
+		dbCtx, err = store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		err = store.SetRoleBindingRequestIdempotencyKey(dbCtx, actorID, reviewed.ID, "request-key")
+		assert.ErrorIs(t, err, storage.ErrIdempotencyKeyTaken)
+		require.NoError(t, store.RollbackContext(dbCtx))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		tc := []testingx.TestCase[types.RoleBindingRequestStatus, []types.RoleBindingRequest]{
+			{
+				Name:  "AllStatuses",
+				Input: "",
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBindingRequest]) {
+					require.NoError(t, res.Err)
+					assert.Len(t, res.Success, 2)
+				},
+			},
+			{
+				Name:  "Pending",
+				Input: types.RoleBindingRequestPending,
+				CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBindingRequest]) {
+					require.NoError(t, res.Err)
+					require.Len(t, res.Success, 1)
+					assert.Equal(t, pending.ID, res.Success[0].ID)
+				},
+			},
+		}
+
+		testfn := func(ctx context.Context, status types.RoleBindingRequestStatus) testingx.TestResult[[]types.RoleBindingRequest] {
+			reqs, err := store.ListResourceRoleBindingRequests(ctx, resourceID, status, storage.ListOptions{})
+
+			return testingx.TestResult[[]types.RoleBindingRequest]{Success: reqs, Err: err}
+		}
+
+		testingx.RunTests(ctx, t, tc, testfn)
+	})
+}
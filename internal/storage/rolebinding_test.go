@@ -120,7 +120,7 @@ func TestListResourceRoleBindings(t *testing.T) {
 	}
 
 	testfn := func(ctx context.Context, input gidx.PrefixedID) testingx.TestResult[[]types.RoleBinding] {
-		rb, err := store.ListResourceRoleBindings(ctx, input)
+		rb, err := store.ListResourceRoleBindings(ctx, input, storage.ListOptions{})
 
 		return testingx.TestResult[[]types.RoleBinding]{Success: rb, Err: err}
 	}
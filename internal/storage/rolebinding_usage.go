@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// RoleBindingUsageService represents a service for recording when role bindings were last used
+// in the permissions API storage
+type RoleBindingUsageService interface {
+	// RecordRoleBindingUsage records when the subjects of role bindings were last allowed an action through them.
+	// Usage older than the recorded usage of the same role binding and subject is ignored.
+	RecordRoleBindingUsage(ctx context.Context, usage []types.RoleBindingUsage) error
+
+	// ListRoleBindingUsage returns the recorded usage of the given role bindings by any subject
+	// an empty slice is returned if no usage is recorded
+	ListRoleBindingUsage(ctx context.Context, roleBindingIDs []gidx.PrefixedID) ([]types.RoleBindingUsage, error)
+}
+
+func (e *engine) RecordRoleBindingUsage(ctx context.Context, usage []types.RoleBindingUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	values := make([]string, len(usage))
+	args := make([]any, 0, len(usage)*3)
+
+	for i, u := range usage {
+		values[i] = fmt.Sprintf("($%d, $%d, $%d)", len(args)+1, len(args)+2, len(args)+3)
+		args = append(args, u.RoleBindingID.String(), u.SubjectID.String(), u.LastUsedAt)
+	}
+
+	q := fmt.Sprintf(`
+		INSERT INTO rolebinding_usage (rolebinding_id, subject_id, last_used_at)
+		VALUES %s
+		ON CONFLICT (rolebinding_id, subject_id)
+		DO UPDATE SET last_used_at = GREATEST(rolebinding_usage.last_used_at, excluded.last_used_at)
+	`, strings.Join(values, ", "))
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *engine) ListRoleBindingUsage(ctx context.Context, roleBindingIDs []gidx.PrefixedID) ([]types.RoleBindingUsage, error) {
+	if len(roleBindingIDs) == 0 {
+		return []types.RoleBindingUsage{}, nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(roleBindingIDs)
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT rolebinding_id, subject_id, last_used_at
+		FROM rolebinding_usage
+		WHERE rolebinding_id IN (%s)
+	`, inClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	usage := []types.RoleBindingUsage{}
+
+	for rows.Next() {
+		var u types.RoleBindingUsage
+
+		if err := rows.Scan(&u.RoleBindingID, &u.SubjectID, &u.LastUsedAt); err != nil {
+			return nil, err
+		}
+
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,66 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestRoleBindingUsage(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-actor")
+	resourceID := gidx.PrefixedID("tnntten-tenant")
+	subjectID := gidx.PrefixedID("idntusr-user1")
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	rb, err := store.CreateRoleBinding(dbCtx, actorID, gidx.MustNewID("permrbn"), resourceID, "")
+	require.NoError(t, err, "no error expected creating role binding")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("Record", func(t *testing.T) {
+		err := store.RecordRoleBindingUsage(ctx, []types.RoleBindingUsage{
+			{RoleBindingID: rb.ID, SubjectID: subjectID, LastUsedAt: usedAt},
+		})
+		require.NoError(t, err)
+
+		// older usage must not replace newer usage
+		err = store.RecordRoleBindingUsage(ctx, []types.RoleBindingUsage{
+			{RoleBindingID: rb.ID, SubjectID: subjectID, LastUsedAt: usedAt.Add(-time.Hour)},
+		})
+		require.NoError(t, err)
+
+		usage, err := store.ListRoleBindingUsage(ctx, []gidx.PrefixedID{rb.ID})
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, subjectID, usage[0].SubjectID)
+		assert.True(t, usedAt.Equal(usage[0].LastUsedAt))
+	})
+
+	t.Run("DeleteRoleBinding", func(t *testing.T) {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, store.DeleteRoleBinding(dbCtx, rb.ID))
+		require.NoError(t, store.CommitContext(dbCtx))
+
+		usage, err := store.ListRoleBindingUsage(ctx, []gidx.PrefixedID{rb.ID})
+		require.NoError(t, err)
+		assert.Empty(t, usage)
+	})
+}
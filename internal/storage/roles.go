@@ -14,13 +14,16 @@ import (
 type RoleService interface {
 	GetRoleByID(ctx context.Context, id gidx.PrefixedID) (Role, error)
 	GetResourceRoleByName(ctx context.Context, resourceID gidx.PrefixedID, name string) (Role, error)
-	ListResourceRoles(ctx context.Context, resourceID gidx.PrefixedID) ([]Role, error)
-	ListManagerResourceRoles(ctx context.Context, manager string, resourceID gidx.PrefixedID) ([]Role, error)
+	ListResourceRoles(ctx context.Context, resourceID gidx.PrefixedID, opts ListOptions) ([]Role, error)
+	ListManagerResourceRoles(ctx context.Context, manager string, resourceID gidx.PrefixedID, opts ListOptions) ([]Role, error)
 	CreateRole(ctx context.Context, actorID gidx.PrefixedID, roleID gidx.PrefixedID, name string, manager string, resourceID gidx.PrefixedID) (Role, error)
 	UpdateRole(ctx context.Context, actorID, roleID gidx.PrefixedID, name string) (Role, error)
 	DeleteRole(ctx context.Context, roleID gidx.PrefixedID) (Role, error)
 	LockRoleForUpdate(ctx context.Context, roleID gidx.PrefixedID) error
 	BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([]Role, error)
+	ListRolesByID(ctx context.Context, ids []gidx.PrefixedID, filter RoleFilter, opts ListOptions) ([]Role, error)
+	GetRoleByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (Role, error)
+	SetRoleIdempotencyKey(ctx context.Context, actorID, roleID gidx.PrefixedID, key string) error
 }
 
 // Role represents a role in the database.
@@ -152,15 +155,15 @@ func (e *engine) GetResourceRoleByName(ctx context.Context, resourceID gidx.Pref
 	return role, nil
 }
 
-// ListResourceRoles retrieves all roles associated with the provided resource ID.
+// ListResourceRoles retrieves the roles associated with the provided resource ID, limited by the provided options.
 // If no roles are found an empty slice is returned.
-func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.PrefixedID) ([]Role, error) {
+func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.PrefixedID, opts ListOptions) ([]Role, error) {
 	db, err := getContextDBQuery(ctx, e)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := db.QueryContext(ctx, `
+	q, args := opts.apply(`
 		SELECT
 			id,
 			name,
@@ -172,10 +175,11 @@ func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.Prefixed
 			updated_at
 		FROM roles
 		WHERE
-			resource_id = $1
-		`,
-		resourceID.String(),
+			resource_id = $1`,
+		[]any{resourceID.String()},
 	)
+
+	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -195,15 +199,16 @@ func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.Prefixed
 	return roles, nil
 }
 
-// ListManagerResourceRoles retrieves all roles associated with the provided resource ID.
+// ListManagerResourceRoles retrieves the roles associated with the provided resource ID and manager,
+// limited by the provided options.
 // If no roles are found an empty slice is returned.
-func (e *engine) ListManagerResourceRoles(ctx context.Context, manager string, resourceID gidx.PrefixedID) ([]Role, error) {
+func (e *engine) ListManagerResourceRoles(ctx context.Context, manager string, resourceID gidx.PrefixedID, opts ListOptions) ([]Role, error) {
 	db, err := getContextDBQuery(ctx, e)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := db.QueryContext(ctx, `
+	q, args := opts.apply(`
 		SELECT
 			id,
 			name,
@@ -216,11 +221,11 @@ func (e *engine) ListManagerResourceRoles(ctx context.Context, manager string, r
 		FROM roles
 		WHERE
 			manager = $1
-			AND resource_id = $2
-		`,
-		manager,
-		resourceID.String(),
+			AND resource_id = $2`,
+		[]any{manager, resourceID.String()},
 	)
+
+	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -285,6 +290,91 @@ func (e *engine) CreateRole(ctx context.Context, actorID, roleID gidx.PrefixedID
 	return role, nil
 }
 
+// GetRoleByIdempotencyKey retrieves the role created by the provided actor with the provided idempotency key.
+// If no role exists an ErrNoRoleFound error is returned.
+func (e *engine) GetRoleByIdempotencyKey(ctx context.Context, actorID gidx.PrefixedID, key string) (Role, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return Role{}, err
+	}
+
+	var role Role
+
+	err = db.QueryRowContext(ctx, `
+		SELECT
+			id,
+			name,
+			manager,
+			resource_id,
+			created_by,
+			updated_by,
+			created_at,
+			updated_at
+		FROM roles
+		WHERE
+			created_by = $1
+			AND idempotency_key = $2
+		`,
+		actorID.String(),
+		key,
+	).Scan(
+		&role.ID,
+		&role.Name,
+		&role.Manager,
+		&role.ResourceID,
+		&role.CreatedBy,
+		&role.UpdatedBy,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Role{}, fmt.Errorf("%w: idempotency key %s", ErrNoRoleFound, key)
+		}
+
+		return Role{}, err
+	}
+
+	return role, nil
+}
+
+// SetRoleIdempotencyKey records the idempotency key the role was created with.
+// If the actor has already used the key for another role an ErrIdempotencyKeyTaken error is returned.
+//
+// This method must be called with a context returned from BeginContext.
+// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+func (e *engine) SetRoleIdempotencyKey(ctx context.Context, actorID, roleID gidx.PrefixedID, key string) error {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE roles
+		SET idempotency_key = $3
+		WHERE id = $1 AND created_by = $2
+		`, roleID.String(), actorID.String(), key,
+	)
+	if err != nil {
+		if pqIsIdempotencyKeyTakenError(err) {
+			return fmt.Errorf("%w: %s", ErrIdempotencyKeyTaken, key)
+		}
+
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrNoRoleFound, roleID.String())
+	}
+
+	return nil
+}
+
 // UpdateRole updates an existing role.
 // If changing the name and the new name results in a duplicate name error, an ErrRoleNameTaken error is returned.
 //
@@ -395,3 +485,51 @@ func (e *engine) BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([
 
 	return roles, nil
 }
+
+// ListRolesByID retrieves the roles with the provided prefixed IDs which match the filter,
+// limited by the provided options.
+// If no roles are found an empty slice is returned.
+func (e *engine) ListRolesByID(ctx context.Context, ids []gidx.PrefixedID, filter RoleFilter, opts ListOptions) ([]Role, error) {
+	if len(ids) == 0 {
+		return []Role{}, nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(ids)
+
+	q, args := filter.apply(fmt.Sprintf(`
+		SELECT
+			id, name, manager, resource_id,
+			created_by, updated_by, created_at, updated_at
+		FROM roles
+		WHERE id IN (%s)`, inClause),
+		args,
+	)
+
+	q, args = opts.apply(q, args)
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close() //nolint:errcheck
+
+	roles := []Role{}
+
+	for rows.Next() {
+		var role Role
+
+		if err := rows.Scan(&role.ID, &role.Name, &role.Manager, &role.ResourceID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
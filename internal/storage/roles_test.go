@@ -133,7 +133,153 @@ func TestListResourceRoles(t *testing.T) {
 	}
 
 	testFn := func(ctx context.Context, input gidx.PrefixedID) testingx.TestResult[[]storage.Role] {
-		roles, err := store.ListResourceRoles(ctx, input)
+		roles, err := store.ListResourceRoles(ctx, input, storage.ListOptions{})
+
+		return testingx.TestResult[[]storage.Role]{
+			Success: roles,
+			Err:     err,
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestListResourceRolesPagination(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	actorID := gidx.PrefixedID("idntusr-abc123")
+	resourceID := gidx.PrefixedID("testten-pag789")
+
+	roleIDs := []gidx.PrefixedID{
+		"permrol-pag001",
+		"permrol-pag002",
+		"permrol-pag003",
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	for _, roleID := range roleIDs {
+		_, err := store.CreateRole(dbCtx, actorID, roleID, roleID.String(), t.Name(), resourceID)
+
+		require.NoError(t, err, "no error expected creating role", roleID)
+	}
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected while committing roles")
+
+	testCases := []testingx.TestCase[storage.ListOptions, []storage.Role]{
+		{
+			Name:  "FirstPage",
+			Input: storage.ListOptions{Limit: 2},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				require.Len(t, res.Success, 2)
+
+				assert.Equal(t, roleIDs[0], res.Success[0].ID)
+				assert.Equal(t, roleIDs[1], res.Success[1].ID)
+			},
+		},
+		{
+			Name:  "NextPage",
+			Input: storage.ListOptions{Limit: 2, AfterID: roleIDs[1]},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				require.Len(t, res.Success, 1)
+
+				assert.Equal(t, roleIDs[2], res.Success[0].ID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input storage.ListOptions) testingx.TestResult[[]storage.Role] {
+		roles, err := store.ListResourceRoles(ctx, resourceID, input)
+
+		return testingx.TestResult[[]storage.Role]{
+			Success: roles,
+			Err:     err,
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestListRolesByID(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	actorID := gidx.PrefixedID("idntusr-abc123")
+	resourceID := gidx.PrefixedID("testten-flt789")
+	manager := "other-manager"
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	viewer, err := store.CreateRole(dbCtx, actorID, "permrol-flt001", "lb_viewer", t.Name(), resourceID)
+	require.NoError(t, err, "no error expected creating role")
+
+	editor, err := store.CreateRole(dbCtx, actorID, "permrol-flt002", "lb_editor", manager, resourceID)
+	require.NoError(t, err, "no error expected creating role")
+
+	underscored, err := store.CreateRole(dbCtx, actorID, "permrol-flt003", "lbxviewer", t.Name(), resourceID)
+	require.NoError(t, err, "no error expected creating role")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected while committing roles")
+
+	ids := []gidx.PrefixedID{viewer.ID, editor.ID, underscored.ID}
+
+	testCases := []testingx.TestCase[storage.RoleFilter, []storage.Role]{
+		{
+			Name:  "NoFilter",
+			Input: storage.RoleFilter{},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				assert.Len(t, res.Success, 3)
+			},
+		},
+		{
+			Name:  "NamePrefixEscaped",
+			Input: storage.RoleFilter{NamePrefix: "lb_"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				require.Len(t, res.Success, 2)
+
+				assert.Equal(t, viewer.ID, res.Success[0].ID)
+				assert.Equal(t, editor.ID, res.Success[1].ID)
+			},
+		},
+		{
+			Name:  "Manager",
+			Input: storage.RoleFilter{Manager: &manager},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				require.Len(t, res.Success, 1)
+
+				assert.Equal(t, editor.ID, res.Success[0].ID)
+			},
+		},
+		{
+			Name: "CreatedBefore",
+			Input: storage.RoleFilter{
+				TimeFilter: storage.TimeFilter{CreatedBefore: viewer.CreatedAt},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]storage.Role]) {
+				require.NoError(t, res.Err, "no error expected while listing roles")
+				assert.Len(t, res.Success, 0)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input storage.RoleFilter) testingx.TestResult[[]storage.Role] {
+		roles, err := store.ListRolesByID(ctx, ids, input, storage.ListOptions{})
 
 		return testingx.TestResult[[]storage.Role]{
 			Success: roles,
@@ -390,3 +536,61 @@ func TestDeleteRole(t *testing.T) {
 	assert.Equal(t, roleID, createdDBRole.ID, "unexpected created role id")
 	assert.Equal(t, roleID, deletedDBRole.ID, "unexpected deleted role id")
 }
+
+func TestRoleIdempotencyKey(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	actorID := gidx.PrefixedID("idntusr-abc123")
+	otherActorID := gidx.PrefixedID("idntusr-def456")
+	resourceID := gidx.PrefixedID("testten-jkl789")
+	roleID := gidx.MustNewID("permrol")
+	otherRoleID := gidx.MustNewID("permrol")
+	key := "create-users-role"
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	_, err = store.CreateRole(dbCtx, actorID, roleID, "users", t.Name(), resourceID)
+	require.NoError(t, err, "no error expected while seeding database role")
+
+	err = store.SetRoleIdempotencyKey(dbCtx, actorID, roleID, key)
+	require.NoError(t, err, "no error expected while setting idempotency key")
+
+	_, err = store.CreateRole(dbCtx, otherActorID, otherRoleID, "admins", t.Name(), resourceID)
+	require.NoError(t, err, "no error expected while seeding database role")
+
+	// idempotency keys are scoped to the creating actor
+	err = store.SetRoleIdempotencyKey(dbCtx, otherActorID, otherRoleID, key)
+	require.NoError(t, err, "no error expected setting the same key for another actor")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected while committing role creation")
+
+	role, err := store.GetRoleByIdempotencyKey(ctx, actorID, key)
+	require.NoError(t, err)
+	assert.Equal(t, roleID, role.ID)
+
+	role, err = store.GetRoleByIdempotencyKey(ctx, otherActorID, key)
+	require.NoError(t, err)
+	assert.Equal(t, otherRoleID, role.ID)
+
+	_, err = store.GetRoleByIdempotencyKey(ctx, actorID, "unknown")
+	assert.ErrorIs(t, err, storage.ErrNoRoleFound)
+
+	dbCtx, err = store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	thirdRoleID := gidx.MustNewID("permrol")
+
+	_, err = store.CreateRole(dbCtx, actorID, thirdRoleID, "viewers", t.Name(), resourceID)
+	require.NoError(t, err, "no error expected while seeding database role")
+
+	err = store.SetRoleIdempotencyKey(dbCtx, actorID, thirdRoleID, key)
+	assert.ErrorIs(t, err, storage.ErrIdempotencyKeyTaken)
+
+	require.NoError(t, store.RollbackContext(dbCtx))
+}
@@ -12,6 +12,10 @@ import (
 type Storage interface {
 	RoleService
 	RoleBindingService
+	RoleBindingRequestService
+	AccessRequestService
+	AccessReviewService
+	RoleBindingUsageService
 	ZedTokenService
 	TransactionManager
 
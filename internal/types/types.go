@@ -13,6 +13,8 @@ type Role struct {
 	Name    string
 	Manager string
 	Actions []string
+	// Includes are the IDs of the roles whose actions are also granted by this role.
+	Includes []gidx.PrefixedID
 
 	ResourceID gidx.PrefixedID
 	CreatedBy  gidx.PrefixedID
@@ -107,3 +109,161 @@ type RoleBinding struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// RoleBindingRequestStatus is the state of a role binding request.
+type RoleBindingRequestStatus string
+
+const (
+	// RoleBindingRequestPending is the status of a request awaiting approval.
+	RoleBindingRequestPending RoleBindingRequestStatus = "pending"
+	// RoleBindingRequestApproved is the status of a request whose role binding was created.
+	RoleBindingRequestApproved RoleBindingRequestStatus = "approved"
+	// RoleBindingRequestRejected is the status of a request which was rejected.
+	RoleBindingRequestRejected RoleBindingRequestStatus = "rejected"
+)
+
+// RoleBindingRequest represents a role binding awaiting approval before it is created.
+type RoleBindingRequest struct {
+	ID         gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	RoleID     gidx.PrefixedID
+	SubjectIDs []gidx.PrefixedID
+	Manager    string
+	Status     RoleBindingRequestStatus
+	// RoleBindingID is the role binding created once the request is approved.
+	RoleBindingID gidx.PrefixedID
+
+	RequestedBy gidx.PrefixedID
+	ReviewedBy  gidx.PrefixedID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// AccessRequestStatus is the state of an access request.
+type AccessRequestStatus string
+
+const (
+	// AccessRequestPending is the status of a request awaiting approval.
+	AccessRequestPending AccessRequestStatus = "pending"
+	// AccessRequestApproved is the status of a request whose role binding was created.
+	AccessRequestApproved AccessRequestStatus = "approved"
+	// AccessRequestDenied is the status of a request which was denied.
+	AccessRequestDenied AccessRequestStatus = "denied"
+	// AccessRequestExpired is the status of an approved request whose role binding was removed once it expired.
+	AccessRequestExpired AccessRequestStatus = "expired"
+)
+
+// AccessRequest represents a subject's request to be bound to a role on a resource.
+type AccessRequest struct {
+	ID         gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	RoleID     gidx.PrefixedID
+	SubjectID  gidx.PrefixedID
+	Reason     string
+	Status     AccessRequestStatus
+	// ExpiresIn is how long the role binding is kept once approved. Zero keeps it until it is deleted.
+	ExpiresIn time.Duration
+	// ExpiresAt is when the role binding is removed, set on approval if the request expires.
+	ExpiresAt *time.Time
+	// RoleBindingID is the role binding created once the request is approved.
+	RoleBindingID gidx.PrefixedID
+	// BreakGlass is set for requests recording a subject binding themselves to the emergency role,
+	// which are approved by the subject as they are made.
+	BreakGlass bool
+
+	ReviewedBy gidx.PrefixedID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AccessReviewStatus is the state of an access review campaign.
+type AccessReviewStatus string
+
+const (
+	// AccessReviewOpen is the status of a campaign whose items are being reviewed.
+	AccessReviewOpen AccessReviewStatus = "open"
+	// AccessReviewClosed is the status of a campaign whose revocations were applied.
+	AccessReviewClosed AccessReviewStatus = "closed"
+)
+
+// AccessReviewDecision is a reviewer's decision on an access review item.
+type AccessReviewDecision string
+
+const (
+	// AccessReviewPending is the decision of an item which has not been reviewed.
+	AccessReviewPending AccessReviewDecision = "pending"
+	// AccessReviewKeep is the decision of an item whose subject keeps the role binding.
+	AccessReviewKeep AccessReviewDecision = "keep"
+	// AccessReviewRevoke is the decision of an item whose subject is removed from the role binding.
+	AccessReviewRevoke AccessReviewDecision = "revoke"
+)
+
+// AccessReview represents a campaign reviewing the role bindings under a resource.
+type AccessReview struct {
+	ID         gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	Name       string
+	Status     AccessReviewStatus
+
+	CreatedBy gidx.PrefixedID
+	ClosedBy  gidx.PrefixedID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ClosedAt  *time.Time
+}
+
+// AccessReviewItem represents a subject of a role binding snapshotted into an access review.
+type AccessReviewItem struct {
+	ID            gidx.PrefixedID
+	ReviewID      gidx.PrefixedID
+	RoleBindingID gidx.PrefixedID
+	ResourceID    gidx.PrefixedID
+	RoleID        gidx.PrefixedID
+	SubjectID     gidx.PrefixedID
+	Decision      AccessReviewDecision
+
+	ReviewedBy gidx.PrefixedID
+	UpdatedAt  time.Time
+}
+
+// RoleBindingUsage represents the last time a subject of a role binding was allowed an action through it.
+type RoleBindingUsage struct {
+	RoleBindingID gidx.PrefixedID
+	SubjectID     gidx.PrefixedID
+	LastUsedAt    time.Time
+}
+
+// UnusedAccess represents a subject of a role binding which has not been allowed an action
+// through it for some time.
+type UnusedAccess struct {
+	RoleBindingID gidx.PrefixedID
+	ResourceID    gidx.PrefixedID
+	RoleID        gidx.PrefixedID
+	SubjectID     gidx.PrefixedID
+	// LastUsedAt is nil if the subject's use of the role binding was never recorded.
+	LastUsedAt *time.Time
+
+	CreatedAt time.Time
+}
+
+// SeparationOfDutiesViolation represents a subject holding actions from more than one of the
+// mutually exclusive sets of a separation of duties constraint on a resource.
+type SeparationOfDutiesViolation struct {
+	Constraint string
+	SubjectID  gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	// Actions are the actions held by the subject from the constraint's sets.
+	Actions []string
+}
+
+// SubjectRoleBinding represents a role binding which includes a subject,
+// along with the bound role and the resource it grants access to.
+type SubjectRoleBinding struct {
+	RoleBinding
+
+	Role     Role
+	Resource Resource
+	// GroupIDs are the groups through which the subject is included in the role binding.
+	// The subject is a direct subject of the role binding if it's listed in SubjectIDs.
+	GroupIDs []gidx.PrefixedID
+}
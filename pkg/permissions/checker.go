@@ -2,8 +2,8 @@ package permissions
 
 import (
 	"context"
+	"errors"
 
-	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/gidx"
 )
 
@@ -11,6 +11,12 @@ var (
 	// CheckerCtxKey is the context key used to set the checker handling function
 	CheckerCtxKey = checkerCtxKey{}
 
+	// BulkCheckerCtxKey is the context key used to set the bulk checker handling function
+	BulkCheckerCtxKey = bulkCheckerCtxKey{}
+
+	// SubjectCheckerCtxKey is the context key used to set the subject checker handling function
+	SubjectCheckerCtxKey = subjectCheckerCtxKey{}
+
 	// DefaultAllowChecker defaults to allow when checker is disabled or skipped
 	DefaultAllowChecker Checker = func(_ context.Context, _ ...AccessRequest) error {
 		return nil
@@ -25,28 +31,55 @@ var (
 // Checker defines the checker function definition
 type Checker func(ctx context.Context, requests ...AccessRequest) error
 
+// BulkChecker defines the bulk checker function definition.
+// Unlike Checker, a BulkChecker returns a decision for every request instead of failing on the first denial.
+type BulkChecker func(ctx context.Context, requests ...AccessRequest) ([]Decision, error)
+
+// SubjectChecker defines the subject checker function definition.
+// A SubjectChecker checks access on behalf of the provided subject instead of the subject of the current request.
+type SubjectChecker func(ctx context.Context, subject gidx.PrefixedID, requests ...AccessRequest) error
+
 // AccessRequest defines the required fields to check permissions access.
 type AccessRequest struct {
 	ResourceID gidx.PrefixedID `json:"resource_id"`
 	Action     string          `json:"action"`
 }
 
+// Decision defines the outcome of a single access request.
+type Decision struct {
+	AccessRequest
+
+	// Allowed is true when the subject is permitted to perform the action on the resource.
+	Allowed bool `json:"allowed"`
+
+	// Error is set when the request could not be evaluated, such as an invalid action for the resource.
+	Error string `json:"error,omitempty"`
+}
+
 type checkerCtxKey struct{}
 
-func setCheckerContext(c echo.Context, checker Checker) {
+type bulkCheckerCtxKey struct{}
+
+type subjectCheckerCtxKey struct{}
+
+func contextWithChecker(ctx context.Context, checker Checker) context.Context {
 	if checker == nil {
 		checker = DefaultDenyChecker
 	}
 
-	req := c.Request().WithContext(
-		context.WithValue(
-			c.Request().Context(),
-			CheckerCtxKey,
-			checker,
-		),
-	)
+	return context.WithValue(ctx, CheckerCtxKey, checker)
+}
 
-	c.SetRequest(req)
+func contextWithBulkChecker(ctx context.Context, checker BulkChecker) context.Context {
+	if checker == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, BulkCheckerCtxKey, checker)
+}
+
+func contextWithSubjectChecker(ctx context.Context, checker SubjectChecker) context.Context {
+	return context.WithValue(ctx, SubjectCheckerCtxKey, checker)
 }
 
 // CheckAccess runs the checker function to check if the provided resource and action are supported.
@@ -73,3 +106,63 @@ func CheckAll(ctx context.Context, requests ...AccessRequest) error {
 
 	return checker(ctx, requests...)
 }
+
+// CheckEach runs the bulk checker function to determine which of the provided resources and actions are permitted.
+// A decision is returned for each request in the same order as requested. A denied request does not result in an error.
+//
+// If no bulk checker is found in the context, each request is evaluated individually with the context checker.
+func CheckEach(ctx context.Context, requests ...AccessRequest) ([]Decision, error) {
+	if bulkChecker, ok := ctx.Value(BulkCheckerCtxKey).(BulkChecker); ok {
+		return bulkChecker(ctx, requests...)
+	}
+
+	checker, ok := ctx.Value(CheckerCtxKey).(Checker)
+	if !ok {
+		return nil, ErrCheckerNotFound
+	}
+
+	decisions := make([]Decision, len(requests))
+
+	for i, request := range requests {
+		decisions[i].AccessRequest = request
+
+		err := checker(ctx, request)
+
+		switch {
+		case err == nil:
+			decisions[i].Allowed = true
+		case errors.Is(err, ErrPermissionDenied):
+			decisions[i].Allowed = false
+		default:
+			return nil, err
+		}
+	}
+
+	return decisions, nil
+}
+
+// CheckAccessFor runs the subject checker function to check if the provided subject may perform the action on the resource.
+// Unlike CheckAccess, the subject is provided explicitly and the check is authenticated with the configured client credentials.
+func CheckAccessFor(ctx context.Context, subject, resource gidx.PrefixedID, action string) error {
+	checker, ok := ctx.Value(SubjectCheckerCtxKey).(SubjectChecker)
+	if !ok {
+		return ErrCheckerNotFound
+	}
+
+	request := AccessRequest{
+		ResourceID: resource,
+		Action:     action,
+	}
+
+	return checker(ctx, subject, request)
+}
+
+// CheckAllFor runs the subject checker function to check if the provided subject is permitted all the provided resources and actions.
+func CheckAllFor(ctx context.Context, subject gidx.PrefixedID, requests ...AccessRequest) error {
+	checker, ok := ctx.Value(SubjectCheckerCtxKey).(SubjectChecker)
+	if !ok {
+		return ErrCheckerNotFound
+	}
+
+	return checker(ctx, subject, requests...)
+}
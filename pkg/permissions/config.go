@@ -1,14 +1,18 @@
 package permissions
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/viperx"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 
 	"go.infratographer.com/permissions-api/pkg/permissions/internal/selecthost"
 )
@@ -28,6 +32,56 @@ type Config struct {
 
 	// Discovery defines the host discovery configuration.
 	Discovery DiscoveryConfig
+
+	// ClientCredentials defines the OAuth2 client credentials used to authenticate
+	// checks made on behalf of an explicit subject, such as with CheckAccessFor.
+	ClientCredentials ClientCredentialsConfig
+}
+
+// ClientCredentialsConfig defines the OAuth2 client credentials configuration.
+type ClientCredentialsConfig struct {
+	// TokenURL is the OAuth2 token endpoint. If not set, client credentials are disabled.
+	TokenURL string
+
+	// ClientID is the OAuth2 client id.
+	ClientID string
+
+	// ClientSecret is the OAuth2 client secret.
+	ClientSecret string
+
+	// Audience sets the audience requested for the access token.
+	Audience string
+
+	// Scopes defines the scopes requested for the access token.
+	Scopes []string
+}
+
+func (c ClientCredentialsConfig) tokenSource() oauth2.TokenSource {
+	if c.TokenURL == "" {
+		return nil
+	}
+
+	config := clientcredentials.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		TokenURL:     c.TokenURL,
+		Scopes:       c.Scopes,
+	}
+
+	if c.Audience != "" {
+		config.EndpointParams = url.Values{
+			"audience": []string{c.Audience},
+		}
+	}
+
+	client := &http.Client{
+		Transport: otelhttp.NewTransport(cleanhttp.DefaultPooledTransport()),
+		Timeout:   defaultClientTimeout,
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+
+	return config.TokenSource(ctx)
 }
 
 func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option) (http.RoundTripper, error) {
@@ -195,4 +249,19 @@ func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
 
 	flags.Bool("permissions-default-allow", false, "grant permission checks when url is not set")
 	viperx.MustBindFlag(v, "permissions.defaultAllow", flags.Lookup("permissions-default-allow"))
+
+	flags.String("permissions-client-token-url", "", "sets the oauth2 token url used to check permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "permissions.clientCredentials.tokenURL", flags.Lookup("permissions-client-token-url"))
+
+	flags.String("permissions-client-id", "", "sets the oauth2 client id used to check permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "permissions.clientCredentials.clientID", flags.Lookup("permissions-client-id"))
+
+	flags.String("permissions-client-secret", "", "sets the oauth2 client secret used to check permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "permissions.clientCredentials.clientSecret", flags.Lookup("permissions-client-secret"))
+
+	flags.String("permissions-client-audience", "", "sets the oauth2 audience requested when checking permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "permissions.clientCredentials.audience", flags.Lookup("permissions-client-audience"))
+
+	flags.StringSlice("permissions-client-scopes", []string{}, "sets the oauth2 scopes requested when checking permissions on behalf of other subjects")
+	viperx.MustBindFlag(v, "permissions.clientCredentials.scopes", flags.Lookup("permissions-client-scopes"))
 }
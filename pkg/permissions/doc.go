@@ -1,4 +1,19 @@
 // Package permissions implements an echo middleware to simplify checking permission
 // checks in downstream handlers by adding a checking function to the context which
 // may later be called to check permissions.
+//
+// Routes may instead declare the access they require with rules, such as
+// Require("loadbalancer_get", FromParam("id")), either passed to [Permissions.Middleware]
+// or registered with [WithRouteRules], so handlers don't need to call CheckAccess themselves.
+//
+// Services not built on echo may use [Permissions.HTTPMiddleware] for net/http handlers,
+// or [Permissions.UnaryServerInterceptor] and [Permissions.StreamServerInterceptor] for
+// gRPC servers, which provide the same context to CheckAccess, CheckAll and
+// CreateAuthRelationships.
+//
+// Outside of a request context, such as in background jobs or event consumers,
+// [Permissions.ContextWithHandler] provides a context which checks permissions on
+// behalf of an explicit subject using the configured OAuth2 client credentials. The
+// client must be one of the server's check delegates, otherwise checks fail with
+// [ErrCheckDelegateNotAllowed].
 package permissions
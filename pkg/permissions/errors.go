@@ -20,15 +20,26 @@ var (
 	// ErrInvalidAuthToken is the error returned when the auth token is not the expected value
 	ErrInvalidAuthToken = echo.ErrBadRequest.WithInternal(fmt.Errorf("%w: invalid auth token", AuthError))
 
+	// ErrNoTokenSource is the error returned when checking access for a subject without client credentials configured
+	ErrNoTokenSource = fmt.Errorf("%w: no token source configured", AuthError)
+
 	// ErrPermissionDenied is the error returned when permission is denied to a call
 	ErrPermissionDenied = echo.ErrUnauthorized.WithInternal(fmt.Errorf("%w: subject doesn't have access", AuthError))
 
+	// ErrCheckDelegateNotAllowed is the error returned when checking access on behalf of a subject
+	// with client credentials which the server doesn't accept as a check delegate. This is a
+	// configuration error rather than a denial of the subject's access.
+	ErrCheckDelegateNotAllowed = fmt.Errorf("%w: client is not a check delegate", Error)
+
 	// ErrBadResponse is the error returned when we receive a bad response from the server
 	ErrBadResponse = fmt.Errorf("%w: bad response from server", Error)
 
 	// ErrCheckerNotFound is the error returned when CheckAccess does not find the appropriate checker context
 	ErrCheckerNotFound = fmt.Errorf("%w: no checker found in context", Error)
 
+	// ErrInvalidRuleResource is returned when a rule is unable to resolve a resource ID from the request.
+	ErrInvalidRuleResource = fmt.Errorf("%w: invalid rule resource", Error)
+
 	// ErrPermissionsMiddlewareMissing is returned when a permissions method has been called but the middleware is missing.
 	ErrPermissionsMiddlewareMissing = fmt.Errorf("%w: permissions middleware missing", Error)
 )
@@ -0,0 +1,80 @@
+package permissions
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCSkipper defines a function to skip the gRPC interceptors for a call.
+type GRPCSkipper func(ctx context.Context, fullMethod string) bool
+
+// DefaultGRPCSkipper never skips the gRPC interceptors.
+func DefaultGRPCSkipper(_ context.Context, _ string) bool {
+	return false
+}
+
+// UnaryServerInterceptor produces a gRPC unary server interceptor to handle authorization checks.
+// The bearer token from the authorization metadata is used for checks, its validation is left to permissions-api.
+func (p *Permissions) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := p.grpcContext(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor produces a gRPC stream server interceptor to handle authorization checks.
+// The bearer token from the authorization metadata is used for checks, its validation is left to permissions-api.
+func (p *Permissions) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := p.grpcContext(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (p *Permissions) grpcContext(ctx context.Context, fullMethod string) (context.Context, error) {
+	skip := !p.enableChecker || p.grpcSkipper(ctx, fullMethod)
+
+	var authHeader string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(echo.HeaderAuthorization); len(values) != 0 {
+			authHeader = values[0]
+		}
+	}
+
+	if !skip && authHeader == "" {
+		return nil, status.Error(codes.Unauthenticated, ErrNoAuthToken.Error())
+	}
+
+	ctx, err := p.requestContext(ctx, skip, "", authHeader)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return ctx, nil
+}
+
+// serverStream wraps a grpc.ServerStream to provide the permissions context.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
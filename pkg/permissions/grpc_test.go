@@ -0,0 +1,167 @@
+package permissions_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	defer srv.Close()
+
+	testCases := []struct {
+		name             string
+		config           permissions.Config
+		options          []permissions.Option
+		authHeader       string
+		expectCode       codes.Code
+		expectCheckError error
+	}{
+		{
+			"no config default deny",
+			permissions.Config{},
+			nil,
+			"",
+			codes.OK,
+			permissions.ErrPermissionDenied,
+		},
+		{
+			"no auth token",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"",
+			codes.Unauthenticated,
+			nil,
+		},
+		{
+			"invalid auth token",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"not-bearer some token",
+			codes.Unauthenticated,
+			nil,
+		},
+		{
+			"skipped",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			[]permissions.Option{
+				permissions.WithGRPCSkipper(func(_ context.Context, fullMethod string) bool { return fullMethod == "/test.Service/Method" }),
+				permissions.WithDefaultChecker(permissions.DefaultAllowChecker),
+			},
+			"",
+			codes.OK,
+			nil,
+		},
+		{
+			"check allowed",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"Bearer good-token",
+			codes.OK,
+			nil,
+		},
+		{
+			"check denied",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"Bearer bad-token",
+			codes.OK,
+			permissions.ErrPermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		perms, err := permissions.New(tc.config, tc.options...)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+
+		if tc.authHeader != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tc.authHeader))
+		}
+
+		checkResult := func(t *testing.T, err, checkErr error) {
+			require.Equal(t, tc.expectCode, status.Code(err), "unexpected status code")
+
+			if tc.expectCode != codes.OK {
+				return
+			}
+
+			if tc.expectCheckError != nil {
+				require.ErrorIs(t, checkErr, tc.expectCheckError, "unexpected error returned by CheckAccess")
+
+				return
+			}
+
+			require.NoError(t, checkErr, "unexpected error from CheckAccess")
+		}
+
+		t.Run(tc.name+" unary", func(t *testing.T) {
+			var checkErr error
+
+			handler := func(ctx context.Context, _ any) (any, error) {
+				checkErr = permissions.CheckAccess(ctx, gidx.PrefixedID("testgid-abc123"), "resource_get")
+
+				return nil, nil
+			}
+
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+			_, err := perms.UnaryServerInterceptor()(ctx, nil, info, handler)
+
+			checkResult(t, err, checkErr)
+		})
+
+		t.Run(tc.name+" stream", func(t *testing.T) {
+			var checkErr error
+
+			handler := func(_ any, ss grpc.ServerStream) error {
+				checkErr = permissions.CheckAccess(ss.Context(), gidx.PrefixedID("testgid-abc123"), "resource_get")
+
+				return nil
+			}
+
+			info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Method"}
+
+			err := perms.StreamServerInterceptor()(nil, &testServerStream{ctx: ctx}, info, handler)
+
+			checkResult(t, err, checkErr)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package permissions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPSkipper defines a function to skip the net/http middleware for a request.
+type HTTPSkipper func(r *http.Request) bool
+
+// DefaultHTTPSkipper never skips the net/http middleware.
+func DefaultHTTPSkipper(_ *http.Request) bool {
+	return false
+}
+
+// HTTPMiddleware produces net/http middleware to handle authorization checks.
+// The bearer token from the request Authorization header is used for checks, its validation is left to permissions-api.
+func (p *Permissions) HTTPMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			skip := !p.enableChecker || p.httpSkipper(r)
+
+			authHeader := r.Header.Get(echo.HeaderAuthorization)
+			if !skip && authHeader == "" {
+				writeHTTPError(w, ErrNoAuthToken)
+
+				return
+			}
+
+			ctx, err := p.requestContext(r.Context(), skip, "", authHeader)
+			if err != nil {
+				writeHTTPError(w, err)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		code = httpErr.Code
+	}
+
+	http.Error(w, http.StatusText(code), code)
+}
@@ -0,0 +1,154 @@
+package permissions_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	defer srv.Close()
+
+	testCases := []struct {
+		name             string
+		config           permissions.Config
+		options          []permissions.Option
+		authHeader       string
+		expectStatusCode int
+		expectCheckError error
+	}{
+		{
+			"no config default deny",
+			permissions.Config{},
+			nil,
+			"",
+			http.StatusOK,
+			permissions.ErrPermissionDenied,
+		},
+		{
+			"no auth token",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"invalid auth token",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"not-bearer some token",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"skipped",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			[]permissions.Option{
+				permissions.WithHTTPSkipper(func(_ *http.Request) bool { return true }),
+				permissions.WithDefaultChecker(permissions.DefaultAllowChecker),
+			},
+			"",
+			http.StatusOK,
+			nil,
+		},
+		{
+			"check allowed",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"Bearer good-token",
+			http.StatusOK,
+			nil,
+		},
+		{
+			"check denied",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			nil,
+			"Bearer bad-token",
+			http.StatusOK,
+			permissions.ErrPermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := permissions.New(tc.config, tc.options...)
+			require.NoError(t, err)
+
+			var (
+				nextCalled bool
+				checkErr   error
+			)
+
+			handler := perms.HTTPMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+
+				checkErr = permissions.CheckAccess(r.Context(), gidx.PrefixedID("testgid-abc123"), "resource_get")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+
+			resp := httptest.NewRecorder()
+
+			handler.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectStatusCode, resp.Code, "unexpected response status code")
+
+			if tc.expectStatusCode != http.StatusOK {
+				require.False(t, nextCalled, "next should not have been called if middleware had an error")
+
+				return
+			}
+
+			require.True(t, nextCalled, "next should have been called if no error was returned")
+
+			if tc.expectCheckError != nil {
+				require.ErrorIs(t, checkErr, tc.expectCheckError, "unexpected error returned by CheckAccess")
+
+				return
+			}
+
+			require.NoError(t, checkErr, "unexpected error from CheckAccess")
+		})
+	}
+
+	t.Run("auth relationship handler", func(t *testing.T) {
+		perms, err := permissions.New(permissions.Config{})
+		require.NoError(t, err)
+
+		handler := perms.HTTPMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			handler, ok := r.Context().Value(permissions.AuthRelationshipRequestHandlerCtxKey).(permissions.AuthRelationshipRequestHandler)
+
+			assert.True(t, ok, "expected auth relationship request handler in context")
+			assert.NotNil(t, handler)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
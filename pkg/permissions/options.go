@@ -8,6 +8,7 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"go.infratographer.com/x/events"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 
 	"go.infratographer.com/permissions-api/pkg/permissions/internal/selecthost"
 )
@@ -81,6 +82,40 @@ func WithSkipper(skipper middleware.Skipper) Option {
 	}
 }
 
+// WithRouteRules registers rules which must be permitted for requests to the route matching the method and path.
+// The path must match the path the route was registered with in echo, for example /loadbalancers/:id.
+func WithRouteRules(method, path string, rules ...Rule) Option {
+	return func(p *Permissions) error {
+		if p.routeRules == nil {
+			p.routeRules = make(map[string][]Rule)
+		}
+
+		key := routeKey(method, path)
+
+		p.routeRules[key] = append(p.routeRules[key], rules...)
+
+		return nil
+	}
+}
+
+// WithHTTPSkipper sets the net/http middleware skipper function
+func WithHTTPSkipper(skipper HTTPSkipper) Option {
+	return func(p *Permissions) error {
+		p.httpSkipper = skipper
+
+		return nil
+	}
+}
+
+// WithGRPCSkipper sets the gRPC interceptors skipper function
+func WithGRPCSkipper(skipper GRPCSkipper) Option {
+	return func(p *Permissions) error {
+		p.grpcSkipper = skipper
+
+		return nil
+	}
+}
+
 // WithDefaultChecker sets the default checker if the middleware is skipped
 func WithDefaultChecker(checker Checker) Option {
 	return func(p *Permissions) error {
@@ -89,3 +124,13 @@ func WithDefaultChecker(checker Checker) Option {
 		return nil
 	}
 }
+
+// WithTokenSource sets the token source used to authenticate checks made on behalf of an explicit subject.
+// This overrides any token source built from the client credentials config.
+func WithTokenSource(tokenSource oauth2.TokenSource) Option {
+	return func(p *Permissions) error {
+		p.tokenSource = tokenSource
+
+		return nil
+	}
+}
@@ -18,10 +18,13 @@ import (
 	"github.com/pkg/errors"
 	"go.infratographer.com/x/echojwtx"
 	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 
 	"go.infratographer.com/permissions-api/pkg/permissions/internal/selecthost"
 )
@@ -29,6 +32,14 @@ import (
 const (
 	bearerPrefix = "Bearer "
 
+	bulkCheckPath = "bulk"
+
+	subjectIDParam = "subject_id"
+
+	// checkDelegateNotAllowedCode is the error code of the server's response when the client
+	// may not check permissions on behalf of other subjects.
+	checkDelegateNotAllowedCode = "check_delegate_not_allowed"
+
 	defaultClientTimeout = 5 * time.Second
 
 	outcomeAllowed = "allowed"
@@ -48,52 +59,91 @@ type Permissions struct {
 	discoveryOpts      []selecthost.Option
 	client             *retryablehttp.Client
 	url                *url.URL
+	bulkURL            *url.URL
 	skipper            middleware.Skipper
+	httpSkipper        HTTPSkipper
+	grpcSkipper        GRPCSkipper
+	routeRules         map[string][]Rule
 	defaultChecker     Checker
+	tokenSource        oauth2.TokenSource
 	ignoreNoResponders bool
 }
 
-// Middleware produces echo middleware to handle authorization checks
-func (p *Permissions) Middleware() echo.MiddlewareFunc {
+// Middleware produces echo middleware to handle authorization checks.
+// Any provided rules, along with rules registered for the matched route with WithRouteRules,
+// must be permitted for the request to proceed. Rules are not checked when the request is skipped,
+// and are checked with the default checker when no permissions-api URL is configured.
+func (p *Permissions) Middleware(rules ...Rule) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			setAuthRelationshipRequestHandler(c, p)
-
-			if !p.enableChecker || p.skipper(c) {
-				setCheckerContext(c, p.defaultChecker)
-
-				return next(c)
-			}
+			skipped := p.skipper(c)
+			skip := !p.enableChecker || skipped
 
 			actor := echojwtx.Actor(c)
-			if actor == "" {
+			if !skip && actor == "" {
 				return ErrNoAuthToken
 			}
 
-			authHeader := strings.TrimSpace(c.Request().Header.Get(echo.HeaderAuthorization))
-
-			if len(authHeader) <= len(bearerPrefix) {
-				return ErrInvalidAuthToken
-			}
-
-			if !strings.EqualFold(authHeader[:len(bearerPrefix)], bearerPrefix) {
-				return ErrInvalidAuthToken
+			ctx, err := p.requestContext(c.Request().Context(), skip, actor, c.Request().Header.Get(echo.HeaderAuthorization))
+			if err != nil {
+				return err
 			}
 
-			token := authHeader[len(bearerPrefix):]
+			c.SetRequest(c.Request().WithContext(ctx))
 
-			setCheckerContext(c, p.checker(c, actor, token))
+			if !skipped {
+				if err := p.checkRules(c, rules); err != nil {
+					return err
+				}
+			}
 
 			return next(c)
 		}
 	}
 }
 
+// ContextWithHandler returns the context with the auth relationship request handler and subject checker defined.
+// This allows CreateAuthRelationships, DeleteAuthRelationships, CheckAccessFor and CheckAllFor to be used
+// outside of a request handled by the middleware, such as in background jobs and event consumers.
+func (p *Permissions) ContextWithHandler(ctx context.Context) context.Context {
+	ctx = contextWithAuthRelationshipRequestHandler(ctx, p)
+
+	return contextWithSubjectChecker(ctx, p.subjectChecker())
+}
+
+// requestContext returns the context with all permissions handlers defined for a request
+// authorized by the provided authorization header.
+// If skip is true, the default checker is used and the authorization header is ignored.
+func (p *Permissions) requestContext(ctx context.Context, skip bool, actor, authHeader string) (context.Context, error) {
+	ctx = p.ContextWithHandler(ctx)
+
+	if skip {
+		return contextWithChecker(ctx, p.defaultChecker), nil
+	}
+
+	authHeader = strings.TrimSpace(authHeader)
+
+	if len(authHeader) <= len(bearerPrefix) {
+		return nil, ErrInvalidAuthToken
+	}
+
+	if !strings.EqualFold(authHeader[:len(bearerPrefix)], bearerPrefix) {
+		return nil, ErrInvalidAuthToken
+	}
+
+	token := authHeader[len(bearerPrefix):]
+
+	ctx = contextWithChecker(ctx, p.checker(actor, token))
+	ctx = contextWithBulkChecker(ctx, p.bulkChecker(actor, token))
+
+	return ctx, nil
+}
+
 type checkPermissionRequest struct {
 	Actions []AccessRequest `json:"actions"`
 }
 
-func (p *Permissions) checker(c echo.Context, actor, _ string) Checker {
+func (p *Permissions) checker(actor, token string) Checker {
 	return func(ctx context.Context, requests ...AccessRequest) error {
 		ctx, span := tracer.Start(ctx, "permissions.checker")
 		defer span.End()
@@ -105,32 +155,174 @@ func (p *Permissions) checker(c echo.Context, actor, _ string) Checker {
 
 		logger := p.logger.With("actor", actor, "requests", len(requests))
 
-		request := checkPermissionRequest{
-			Actions: requests,
+		return p.checkAccess(ctx, logger, p.url, bearerPrefix+token, requests)
+	}
+}
+
+func (p *Permissions) subjectChecker() SubjectChecker {
+	if !p.enableChecker {
+		return func(ctx context.Context, _ gidx.PrefixedID, requests ...AccessRequest) error {
+			return p.defaultChecker(ctx, requests...)
+		}
+	}
+
+	return func(ctx context.Context, subject gidx.PrefixedID, requests ...AccessRequest) error {
+		ctx, span := tracer.Start(ctx, "permissions.subjectChecker")
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("permissions.subject", subject.String()),
+			attribute.Int("permissions.requests", len(requests)),
+		)
+
+		logger := p.logger.With("subject", subject, "requests", len(requests))
+
+		if p.tokenSource == nil {
+			span.SetStatus(codes.Error, ErrNoTokenSource.Error())
+			logger.Errorw("unable to check access for subject", "error", ErrNoTokenSource)
+
+			return ErrNoTokenSource
+		}
+
+		token, err := p.tokenSource.Token()
+		if err != nil {
+			err = errors.WithStack(err)
+
+			span.SetStatus(codes.Error, err.Error())
+			logger.Errorw("failed to get client credentials token", "error", err)
+
+			return err
+		}
+
+		uri := *p.url
+
+		query := uri.Query()
+		query.Set(subjectIDParam, subject.String())
+
+		uri.RawQuery = query.Encode()
+
+		return p.checkAccess(ctx, logger, &uri, token.Type()+" "+token.AccessToken, requests)
+	}
+}
+
+// checkAccess submits the requests to the provided permissions-api allow uri, authenticating with the provided authorization header.
+func (p *Permissions) checkAccess(ctx context.Context, logger *zap.SugaredLogger, uri *url.URL, authHeader string, requests []AccessRequest) error {
+	span := trace.SpanFromContext(ctx)
+
+	request := checkPermissionRequest{
+		Actions: requests,
+	}
+
+	var reqBody bytes.Buffer
+
+	if err := json.NewEncoder(&reqBody).Encode(request); err != nil {
+		err = errors.WithStack(err)
+
+		span.SetStatus(codes.Error, err.Error())
+		logger.Errorw("failed to encode request body", "error", err)
+
+		return err
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, uri.String(), &reqBody)
+	if err != nil {
+		err = errors.WithStack(err)
+
+		span.SetStatus(codes.Error, err.Error())
+		logger.Errorw("failed to create checker request", "error", err)
+
+		return err
+	}
+
+	req.Header.Set(echo.HeaderAuthorization, authHeader)
+	req.Header.Set(echo.HeaderContentType, "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		err = errors.WithStack(err)
+
+		logger.Errorw("failed to make request", "error", err)
+
+		return err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	err = ensureValidServerResponse(resp)
+	if err != nil {
+		body, _ := io.ReadAll(resp.Body) //nolint:errcheck // ignore any errors reading as this is just for logging.
+
+		switch {
+		case errors.Is(err, ErrPermissionDenied):
+			logger.Warnw("unauthorized access to resource")
+			span.AddEvent("permission denied")
+			span.SetAttributes(
+				attribute.String(
+					"permissions.outcome",
+					outcomeDenied,
+				),
+			)
+		case errors.Is(err, ErrCheckDelegateNotAllowed):
+			logger.Errorw("client is not a check delegate of the permissions-api", "error", err, "response.body", string(body))
+			span.SetStatus(codes.Error, errors.WithStack(err).Error())
+		case errors.Is(err, ErrBadResponse):
+			logger.Errorw("bad response from server", "error", err, "response.status_code", resp.StatusCode, "response.body", string(body))
+			span.SetStatus(codes.Error, errors.WithStack(err).Error())
+		}
+
+		return err
+	}
+
+	span.SetAttributes(
+		attribute.String(
+			"permissions.outcome",
+			outcomeAllowed,
+		),
+	)
+	logger.Debug("access granted to resource")
+
+	return nil
+}
+
+func (p *Permissions) bulkChecker(actor, token string) BulkChecker {
+	return func(ctx context.Context, requests ...AccessRequest) ([]Decision, error) {
+		ctx, span := tracer.Start(ctx, "permissions.bulkChecker")
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("permissions.actor", actor),
+			attribute.Int("permissions.requests", len(requests)),
+		)
+
+		logger := p.logger.With("actor", actor, "requests", len(requests))
+
+		// The bulk endpoint expects the requests as the root of the body.
+		if requests == nil {
+			requests = []AccessRequest{}
 		}
 
 		var reqBody bytes.Buffer
 
-		if err := json.NewEncoder(&reqBody).Encode(request); err != nil {
+		if err := json.NewEncoder(&reqBody).Encode(requests); err != nil {
 			err = errors.WithStack(err)
 
 			span.SetStatus(codes.Error, err.Error())
 			logger.Errorw("failed to encode request body", "error", err)
 
-			return err
+			return nil, err
 		}
 
-		req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, p.url.String(), &reqBody)
+		req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, p.bulkURL.String(), &reqBody)
 		if err != nil {
 			err = errors.WithStack(err)
 
 			span.SetStatus(codes.Error, err.Error())
-			logger.Errorw("failed to create checker request", "error", err)
+			logger.Errorw("failed to create bulk checker request", "error", err)
 
-			return err
+			return nil, err
 		}
 
-		req.Header.Set(echo.HeaderAuthorization, c.Request().Header.Get(echo.HeaderAuthorization))
+		req.Header.Set(echo.HeaderAuthorization, bearerPrefix+token)
 		req.Header.Set(echo.HeaderContentType, "application/json")
 
 		resp, err := p.client.Do(req)
@@ -139,42 +331,55 @@ func (p *Permissions) checker(c echo.Context, actor, _ string) Checker {
 
 			logger.Errorw("failed to make request", "error", err)
 
-			return err
+			return nil, err
 		}
 
 		defer resp.Body.Close() //nolint:errcheck
 
-		err = ensureValidServerResponse(resp)
-		if err != nil {
+		if err := ensureValidServerResponse(resp); err != nil {
 			body, _ := io.ReadAll(resp.Body) //nolint:errcheck // ignore any errors reading as this is just for logging.
 
-			switch {
-			case errors.Is(err, ErrPermissionDenied):
-				logger.Warnw("unauthorized access to resource")
-				span.AddEvent("permission denied")
-				span.SetAttributes(
-					attribute.String(
-						"permissions.outcome",
-						outcomeDenied,
-					),
-				)
-			case errors.Is(err, ErrBadResponse):
-				logger.Errorw("bad response from server", "error", err, "response.status_code", resp.StatusCode, "response.body", string(body))
-				span.SetStatus(codes.Error, errors.WithStack(err).Error())
-			}
+			logger.Errorw("bad response from server", "error", err, "response.status_code", resp.StatusCode, "response.body", string(body))
+			span.SetStatus(codes.Error, errors.WithStack(err).Error())
 
-			return err
+			return nil, err
+		}
+
+		var decisions []Decision
+
+		if err := json.NewDecoder(resp.Body).Decode(&decisions); err != nil {
+			err = fmt.Errorf("%w: failed to decode response: %w", ErrBadResponse, err)
+
+			span.SetStatus(codes.Error, err.Error())
+			logger.Errorw("failed to decode response body", "error", err)
+
+			return nil, err
+		}
+
+		if len(decisions) != len(requests) {
+			err = fmt.Errorf("%w: expected %d decisions, got %d", ErrBadResponse, len(requests), len(decisions))
+
+			span.SetStatus(codes.Error, err.Error())
+			logger.Errorw("unexpected number of decisions", "error", err)
+
+			return nil, err
+		}
+
+		var allowed int
+
+		for _, decision := range decisions {
+			if decision.Allowed {
+				allowed++
+			}
 		}
 
 		span.SetAttributes(
-			attribute.String(
-				"permissions.outcome",
-				outcomeAllowed,
-			),
+			attribute.Int("permissions.allowed", allowed),
+			attribute.Int("permissions.denied", len(decisions)-allowed),
 		)
-		logger.Debug("access granted to resource")
+		logger.Debugw("bulk access checked", "allowed", allowed, "denied", len(decisions)-allowed)
 
-		return nil
+		return decisions, nil
 	}
 }
 
@@ -184,6 +389,8 @@ func New(config Config, options ...Option) (*Permissions, error) {
 		config:             config,
 		enableChecker:      config.URL != "",
 		skipper:            middleware.DefaultSkipper,
+		httpSkipper:        DefaultHTTPSkipper,
+		grpcSkipper:        DefaultGRPCSkipper,
 		defaultChecker:     DefaultDenyChecker,
 		ignoreNoResponders: config.IgnoreNoResponders,
 	}
@@ -195,6 +402,7 @@ func New(config Config, options ...Option) (*Permissions, error) {
 		}
 
 		p.url = uri
+		p.bulkURL = uri.JoinPath(bulkCheckPath)
 	}
 
 	if config.URL == "" && config.DefaultAllow {
@@ -211,6 +419,10 @@ func New(config Config, options ...Option) (*Permissions, error) {
 		p.logger = zap.NewNop().Sugar()
 	}
 
+	if p.tokenSource == nil {
+		p.tokenSource = config.ClientCredentials.tokenSource()
+	}
+
 	if p.client == nil {
 		client := &http.Client{
 			Transport: cleanhttp.DefaultPooledTransport(),
@@ -230,6 +442,10 @@ func New(config Config, options ...Option) (*Permissions, error) {
 func ensureValidServerResponse(resp *http.Response) error {
 	if resp.StatusCode >= http.StatusMultiStatus {
 		if resp.StatusCode == http.StatusForbidden {
+			if isCheckDelegateNotAllowed(resp) {
+				return ErrCheckDelegateNotAllowed
+			}
+
 			return ErrPermissionDenied
 		}
 
@@ -238,3 +454,24 @@ func ensureValidServerResponse(resp *http.Response) error {
 
 	return nil
 }
+
+// isCheckDelegateNotAllowed reports whether a forbidden response rejects the client as a check
+// delegate rather than denying access. The body is restored so it may still be logged.
+func isCheckDelegateNotAllowed(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var errResp struct {
+		Code string `json:"code"`
+	}
+
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+
+	return errResp.Code == checkDelegateNotAllowedCode
+}
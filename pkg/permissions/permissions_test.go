@@ -1,12 +1,14 @@
 package permissions_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.infratographer.com/x/echojwtx"
 	"go.infratographer.com/x/gidx"
@@ -260,3 +262,298 @@ func TestPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckEach(t *testing.T) {
+	allowedID := gidx.MustNewID("testgid")
+	deniedID := gidx.MustNewID("testgid")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/allow/bulk" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		var reqBody []struct {
+			ResourceID string `json:"resource_id"`
+			Action     string `json:"action"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		resp := make([]map[string]any, len(reqBody))
+
+		for i, request := range reqBody {
+			resp[i] = map[string]any{
+				"resource_id": request.ResourceID,
+				"action":      request.Action,
+				"allowed":     request.ResourceID == allowedID.String(),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(resp) //nolint:errcheck
+	}))
+
+	defer srv.Close()
+
+	requests := []permissions.AccessRequest{
+		{ResourceID: allowedID, Action: "resource_get"},
+		{ResourceID: deniedID, Action: "resource_get"},
+		{ResourceID: allowedID, Action: "resource_update"},
+	}
+
+	testCases := []struct {
+		name           string
+		config         permissions.Config
+		options        []permissions.Option
+		authHeader     string
+		expectAllowed  []bool
+		expectCheckErr error
+	}{
+		{
+			"no config default deny",
+			permissions.Config{},
+			nil,
+			"",
+			[]bool{false, false, false},
+			nil,
+		},
+		{
+			"no config with default allow",
+			permissions.Config{},
+			[]permissions.Option{permissions.WithDefaultChecker(permissions.DefaultAllowChecker)},
+			"",
+			[]bool{true, true, true},
+			nil,
+		},
+		{
+			"partial results",
+			permissions.Config{
+				URL: srv.URL + "/allow",
+			},
+			nil,
+			"Bearer good-token",
+			[]bool{true, false, true},
+			nil,
+		},
+		{
+			"unauthorized token",
+			permissions.Config{
+				URL: srv.URL + "/allow",
+			},
+			nil,
+			"Bearer bad-token",
+			nil,
+			permissions.ErrBadResponse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := permissions.New(tc.config, tc.options...)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+
+			engine := echo.New()
+
+			ctx := engine.NewContext(req, resp)
+
+			ctx.Set(echojwtx.ActorKey, "idntusr-abc123")
+
+			err = perms.Middleware()(func(_ echo.Context) error { return nil })(ctx)
+			require.NoError(t, err, "unexpected error from middleware")
+
+			decisions, err := permissions.CheckEach(ctx.Request().Context(), requests...)
+
+			if tc.expectCheckErr != nil {
+				require.Error(t, err, "expected error to be returned from permissions CheckEach")
+				require.ErrorIs(t, err, tc.expectCheckErr, "unexpected error returned by CheckEach")
+
+				return
+			}
+
+			require.NoError(t, err, "unexpected error from CheckEach")
+			require.Len(t, decisions, len(requests))
+
+			for i, decision := range decisions {
+				assert.Equal(t, requests[i], decision.AccessRequest, "unexpected request for decision %d", i)
+				assert.Equal(t, tc.expectAllowed[i], decision.Allowed, "unexpected outcome for decision %d", i)
+			}
+		})
+	}
+
+	t.Run("no checker", func(t *testing.T) {
+		_, err := permissions.CheckEach(context.Background(), requests...)
+		require.ErrorIs(t, err, permissions.ErrCheckerNotFound)
+	})
+}
+
+func TestCheckAccessFor(t *testing.T) {
+	subjectID := gidx.PrefixedID("idntusr-abc123")
+	resourceID := gidx.PrefixedID("testgid-abc123")
+
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		clientID, clientSecret, _ := r.BasicAuth()
+		if (clientID != "svc" && clientID != "other") || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+			"access_token": clientID + "-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+
+	defer tokenSrv.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer other-token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+
+			_ = json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+				"message": "subject 'other' may not check permissions on behalf of other subjects",
+				"code":    "check_delegate_not_allowed",
+			})
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer svc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.URL.Query().Get("subject_id") != subjectID.String() {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+	}))
+
+	defer srv.Close()
+
+	testCases := []struct {
+		name           string
+		config         permissions.Config
+		subject        gidx.PrefixedID
+		expectCheckErr error
+	}{
+		{
+			"no config default deny",
+			permissions.Config{},
+			subjectID,
+			permissions.ErrPermissionDenied,
+		},
+		{
+			"no config default allow",
+			permissions.Config{
+				DefaultAllow: true,
+			},
+			subjectID,
+			nil,
+		},
+		{
+			"no client credentials",
+			permissions.Config{
+				URL: srv.URL,
+			},
+			subjectID,
+			permissions.ErrNoTokenSource,
+		},
+		{
+			"check allowed",
+			permissions.Config{
+				URL: srv.URL,
+				ClientCredentials: permissions.ClientCredentialsConfig{
+					TokenURL:     tokenSrv.URL,
+					ClientID:     "svc",
+					ClientSecret: "secret",
+				},
+			},
+			subjectID,
+			nil,
+		},
+		{
+			"check denied",
+			permissions.Config{
+				URL: srv.URL,
+				ClientCredentials: permissions.ClientCredentialsConfig{
+					TokenURL:     tokenSrv.URL,
+					ClientID:     "svc",
+					ClientSecret: "secret",
+				},
+			},
+			"idntusr-def456",
+			permissions.ErrPermissionDenied,
+		},
+		{
+			"client not a check delegate",
+			permissions.Config{
+				URL: srv.URL,
+				ClientCredentials: permissions.ClientCredentialsConfig{
+					TokenURL:     tokenSrv.URL,
+					ClientID:     "other",
+					ClientSecret: "secret",
+				},
+			},
+			subjectID,
+			permissions.ErrCheckDelegateNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := permissions.New(tc.config)
+			require.NoError(t, err)
+
+			ctx := perms.ContextWithHandler(context.Background())
+
+			err = permissions.CheckAccessFor(ctx, tc.subject, resourceID, "resource_get")
+
+			if tc.expectCheckErr != nil {
+				require.Error(t, err, "expected error to be returned from permissions CheckAccessFor")
+				require.ErrorIs(t, err, tc.expectCheckErr, "unexpected error returned by CheckAccessFor")
+
+				return
+			}
+
+			require.NoError(t, err, "unexpected error from CheckAccessFor")
+		})
+	}
+
+	t.Run("no checker", func(t *testing.T) {
+		err := permissions.CheckAccessFor(context.Background(), subjectID, resourceID, "resource_get")
+		require.ErrorIs(t, err, permissions.ErrCheckerNotFound)
+	})
+}
@@ -0,0 +1,118 @@
+package permissionstest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+)
+
+type checkPermissionsRequest struct {
+	Actions []checkAction `json:"actions"`
+}
+
+type checkAction struct {
+	ResourceID string `json:"resource_id"`
+	Action     string `json:"action"`
+}
+
+type checkActionResponse struct {
+	ResourceID string `json:"resource_id"`
+	Action     string `json:"action"`
+	Allowed    bool   `json:"allowed"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s *Server) checkAction(c echo.Context) error {
+	subjectID, err := requestSubject(c)
+	if err != nil {
+		return err
+	}
+
+	action := c.QueryParam("action")
+	if action == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing action query parameter")
+	}
+
+	resourceID, err := parseID(c.QueryParam("resource"))
+	if err != nil {
+		return err
+	}
+
+	if !s.allowed(subjectID, resourceID, action) {
+		return deniedError(subjectID, resourceID, action)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{})
+}
+
+func (s *Server) checkAllActions(c echo.Context) error {
+	subjectID, err := requestSubject(c)
+	if err != nil {
+		return err
+	}
+
+	var reqBody checkPermissionsRequest
+
+	if err := c.Bind(&reqBody); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
+	}
+
+	for _, check := range reqBody.Actions {
+		resourceID, err := parseID(check.ResourceID)
+		if err != nil {
+			return err
+		}
+
+		if !s.allowed(subjectID, resourceID, check.Action) {
+			return deniedError(subjectID, resourceID, check.Action)
+		}
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{})
+}
+
+func (s *Server) bulkCheckActions(c echo.Context) error {
+	subjectID, err := requestSubject(c)
+	if err != nil {
+		return err
+	}
+
+	var reqBody []checkAction
+
+	if err := c.Bind(&reqBody); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
+	}
+
+	responses := make([]checkActionResponse, len(reqBody))
+
+	for i, check := range reqBody {
+		responses[i] = checkActionResponse{
+			ResourceID: check.ResourceID,
+			Action:     check.Action,
+		}
+
+		resourceID, err := gidx.Parse(check.ResourceID)
+		if err != nil {
+			responses[i].Error = "invalid resource id: " + err.Error()
+
+			continue
+		}
+
+		responses[i].Allowed = s.allowed(subjectID, resourceID, check.Action)
+	}
+
+	return c.JSON(http.StatusOK, responses)
+}
+
+func deniedError(subjectID, resourceID gidx.PrefixedID, action string) error {
+	msg := fmt.Sprintf(
+		"subject '%s' does not have permission to perform action '%s' on resource '%s'",
+		subjectID,
+		action,
+		resourceID,
+	)
+
+	return echo.NewHTTPError(http.StatusForbidden, msg)
+}
@@ -0,0 +1,165 @@
+package permissionstest
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+)
+
+const messageIDPrefix = "testmsg"
+
+var _ events.AuthRelationshipPublisher = (*publisher)(nil)
+
+type relationship struct {
+	relation  string
+	subjectID gidx.PrefixedID
+}
+
+// Publisher returns an auth relationship publisher which applies requests to the fake server.
+func (s *Server) Publisher() events.AuthRelationshipPublisher {
+	return &publisher{s}
+}
+
+// FailRelationships causes all following auth relationship requests to respond with the provided errors.
+// Calling without any errors restores successful responses.
+func (s *Server) FailRelationships(errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errors = errs
+}
+
+// Relationships returns the relationships recorded for the resource.
+func (s *Server) Relationships(resourceID gidx.PrefixedID) []events.AuthRelationshipRelation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	relations := make([]events.AuthRelationshipRelation, len(s.relationships[resourceID]))
+
+	for i, rel := range s.relationships[resourceID] {
+		relations[i] = events.AuthRelationshipRelation{
+			Relation:  rel.relation,
+			SubjectID: rel.subjectID,
+		}
+	}
+
+	return relations
+}
+
+func (s *Server) applyRelationshipRequest(request events.AuthRelationshipRequest) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errors) != 0 {
+		return s.errors
+	}
+
+	for _, relation := range request.Relations {
+		rel := relationship{
+			relation:  relation.Relation,
+			subjectID: relation.SubjectID,
+		}
+
+		existing := s.relationships[request.ObjectID]
+
+		switch request.Action {
+		case events.WriteAuthRelationshipAction:
+			if !slices.Contains(existing, rel) {
+				s.relationships[request.ObjectID] = append(existing, rel)
+			}
+		case events.DeleteAuthRelationshipAction:
+			s.relationships[request.ObjectID] = slices.DeleteFunc(existing, func(r relationship) bool {
+				return r == rel
+			})
+		}
+	}
+
+	return nil
+}
+
+type publisher struct {
+	server *Server
+}
+
+// PublishAuthRelationshipRequest implements events.AuthRelationshipPublisher.
+func (p *publisher) PublishAuthRelationshipRequest(_ context.Context, topic string, message events.AuthRelationshipRequest) (events.Message[events.AuthRelationshipResponse], error) {
+	if err := message.Validate(); err != nil {
+		return nil, err
+	}
+
+	response := events.AuthRelationshipResponse{
+		Errors: p.server.applyRelationshipRequest(message),
+	}
+
+	return &responseMessage{
+		id:        gidx.MustNewID(messageIDPrefix).String(),
+		topic:     topic,
+		message:   response,
+		timestamp: time.Now(),
+	}, nil
+}
+
+type responseMessage struct {
+	id        string
+	topic     string
+	message   events.AuthRelationshipResponse
+	timestamp time.Time
+}
+
+// Connection implements events.Message.
+func (m *responseMessage) Connection() events.Connection {
+	return nil
+}
+
+// ID implements events.Message.
+func (m *responseMessage) ID() string {
+	return m.id
+}
+
+// Topic implements events.Message.
+func (m *responseMessage) Topic() string {
+	return m.topic
+}
+
+// Message implements events.Message.
+func (m *responseMessage) Message() events.AuthRelationshipResponse {
+	return m.message
+}
+
+// Ack implements events.Message.
+func (m *responseMessage) Ack() error {
+	return nil
+}
+
+// Nak implements events.Message.
+func (m *responseMessage) Nak(time.Duration) error {
+	return nil
+}
+
+// Term implements events.Message.
+func (m *responseMessage) Term() error {
+	return nil
+}
+
+// Timestamp implements events.Message.
+func (m *responseMessage) Timestamp() time.Time {
+	return m.timestamp
+}
+
+// Deliveries implements events.Message.
+func (m *responseMessage) Deliveries() uint64 {
+	return 1
+}
+
+// Error implements events.Message.
+func (m *responseMessage) Error() error {
+	return nil
+}
+
+// Source implements events.Message.
+func (m *responseMessage) Source() any {
+	return m.message
+}
@@ -0,0 +1,198 @@
+// Package permissionstest implements an in-process fake permissions-api server for use in
+// integration tests of services using the permissions package, without requiring SpiceDB or NATS.
+//
+// Access is granted from an in-memory rule table populated with [Server.Allow]. Auth relationship
+// requests published through [Server.Publisher] are recorded, and a subject allowed an action on a
+// resource is also allowed that action on any resource related to it, similar to permissions
+// inherited from a parent tenant.
+package permissionstest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+)
+
+const (
+	allowPath = "/api/v1/allow"
+
+	subjectIDParam = "subject_id"
+
+	bearerPrefix = "Bearer "
+)
+
+var tokenAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.HS256, jose.HS384, jose.HS512,
+	jose.EdDSA,
+}
+
+type rule struct {
+	subjectID  gidx.PrefixedID
+	resourceID gidx.PrefixedID
+	action     string
+}
+
+// Server is a fake permissions-api server.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.RWMutex
+	rules         map[rule]struct{}
+	relationships map[gidx.PrefixedID][]relationship
+	errors        []error
+}
+
+// NewServer starts a new fake permissions-api server which is closed when the test completes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	srv := &Server{
+		rules:         make(map[rule]struct{}),
+		relationships: make(map[gidx.PrefixedID][]relationship),
+	}
+
+	engine := echo.New()
+
+	engine.HideBanner = true
+	engine.HidePort = true
+
+	engine.GET(allowPath, srv.checkAction)
+	engine.POST(allowPath, srv.checkAllActions)
+	engine.POST(allowPath+"/bulk", srv.bulkCheckActions)
+
+	srv.Server = httptest.NewServer(engine)
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// Config returns a permissions config which checks access against the fake server.
+func (s *Server) Config() permissions.Config {
+	return permissions.Config{
+		URL: s.URL + allowPath,
+	}
+}
+
+// Options returns the permissions options required to use the fake server for auth relationship requests.
+func (s *Server) Options() []permissions.Option {
+	return []permissions.Option{
+		permissions.WithEventsPublisher(s.Publisher()),
+	}
+}
+
+// Allow allows the subject to perform the actions on the resource.
+// If the subject ID is empty, the actions are allowed for all subjects.
+func (s *Server) Allow(subjectID, resourceID gidx.PrefixedID, actions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, action := range actions {
+		s.rules[rule{subjectID, resourceID, action}] = struct{}{}
+	}
+}
+
+// Revoke removes the actions previously allowed for the subject on the resource.
+func (s *Server) Revoke(subjectID, resourceID gidx.PrefixedID, actions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, action := range actions {
+		delete(s.rules, rule{subjectID, resourceID, action})
+	}
+}
+
+// Reset removes all rules, relationships and relationship errors.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = make(map[rule]struct{})
+	s.relationships = make(map[gidx.PrefixedID][]relationship)
+	s.errors = nil
+}
+
+// allowed reports whether the subject may perform the action on the resource,
+// either directly or through any resource the resource is related to.
+func (s *Server) allowed(subjectID, resourceID gidx.PrefixedID, action string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visited := make(map[gidx.PrefixedID]bool)
+	queue := []gidx.PrefixedID{resourceID}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if visited[id] {
+			continue
+		}
+
+		visited[id] = true
+
+		if _, ok := s.rules[rule{subjectID, id, action}]; ok {
+			return true
+		}
+
+		if _, ok := s.rules[rule{"", id, action}]; ok {
+			return true
+		}
+
+		for _, rel := range s.relationships[id] {
+			queue = append(queue, rel.subjectID)
+		}
+	}
+
+	return false
+}
+
+// requestSubject returns the subject being checked, which is the subject_id query parameter if provided,
+// otherwise the subject of the bearer token. Tokens which are not JWTs are used as the subject ID directly.
+func requestSubject(c echo.Context) (gidx.PrefixedID, error) {
+	if subjectID := c.QueryParam(subjectIDParam); subjectID != "" {
+		return parseID(subjectID)
+	}
+
+	authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
+
+	if !strings.HasPrefix(authHeader, bearerPrefix) {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+	}
+
+	token := strings.TrimPrefix(authHeader, bearerPrefix)
+
+	parsed, err := jwt.ParseSigned(token, tokenAlgorithms)
+	if err != nil {
+		return parseID(token)
+	}
+
+	var claims jwt.Claims
+
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "invalid token claims").SetInternal(err)
+	}
+
+	return parseID(claims.Subject)
+}
+
+func parseID(value string) (gidx.PrefixedID, error) {
+	id, err := gidx.Parse(value)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "invalid id").SetInternal(err)
+	}
+
+	return id, nil
+}
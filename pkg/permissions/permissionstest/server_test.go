@@ -0,0 +1,113 @@
+package permissionstest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/permissions-api/pkg/permissions/permissionstest"
+)
+
+func TestServer(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	permsrv := permissionstest.NewServer(t)
+
+	subjectID := gidx.PrefixedID("idntusr-abc123")
+	tenantID := gidx.PrefixedID("tnntten-abc123")
+	lbID := gidx.PrefixedID("loadbal-abc123")
+	otherLBID := gidx.PrefixedID("loadbal-def456")
+
+	permsrv.Allow(subjectID, tenantID, "loadbalancer_get")
+
+	perms, err := permissions.New(permsrv.Config(), permsrv.Options()...)
+	require.NoError(t, err)
+
+	auth, err := echojwtx.NewAuth(context.Background(), echojwtx.AuthConfig{Issuer: authsrv.Issuer})
+	require.NoError(t, err)
+
+	engine := echo.New()
+
+	engine.Use(auth.Middleware(), perms.Middleware())
+
+	engine.GET("/loadbalancers/:id", func(c echo.Context) error {
+		if err := permissions.CheckAccess(c.Request().Context(), gidx.PrefixedID(c.Param("id")), "loadbalancer_get"); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	engine.POST("/loadbalancers/:id", func(c echo.Context) error {
+		relation := events.AuthRelationshipRelation{
+			Relation:  "owner",
+			SubjectID: tenantID,
+		}
+
+		if err := permissions.CreateAuthRelationships(c.Request().Context(), "permissions.loadbalancer", gidx.PrefixedID(c.Param("id")), relation); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusCreated)
+	})
+
+	engine.POST("/bulk", func(c echo.Context) error {
+		decisions, err := permissions.CheckEach(c.Request().Context(),
+			permissions.AccessRequest{ResourceID: lbID, Action: "loadbalancer_get"},
+			permissions.AccessRequest{ResourceID: otherLBID, Action: "loadbalancer_get"},
+			permissions.AccessRequest{ResourceID: tenantID, Action: "loadbalancer_get"},
+		)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, decisions)
+	})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+authsrv.TSignSubject(t, subjectID.String()))
+
+		resp := httptest.NewRecorder()
+
+		engine.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	// Access is denied until the load balancer is related to the tenant.
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/loadbalancers/"+lbID.String()).Code)
+
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/loadbalancers/"+lbID.String()).Code)
+
+	assert.Equal(t, []events.AuthRelationshipRelation{{Relation: "owner", SubjectID: tenantID}}, permsrv.Relationships(lbID))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/loadbalancers/"+lbID.String()).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/loadbalancers/"+otherLBID.String()).Code)
+
+	resp := do(http.MethodPost, "/bulk")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[
+		{"resource_id": "loadbal-abc123", "action": "loadbalancer_get", "allowed": true},
+		{"resource_id": "loadbal-def456", "action": "loadbalancer_get", "allowed": false},
+		{"resource_id": "tnntten-abc123", "action": "loadbalancer_get", "allowed": true}
+	]`, resp.Body.String())
+
+	permsrv.FailRelationships(events.ErrProviderNotConfigured)
+
+	assert.Equal(t, http.StatusInternalServerError, do(http.MethodPost, "/loadbalancers/"+otherLBID.String()).Code)
+
+	permsrv.Reset()
+
+	assert.Empty(t, permsrv.Relationships(lbID))
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/loadbalancers/"+lbID.String()).Code)
+}
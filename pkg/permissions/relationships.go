@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 
-	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel/attribute"
@@ -20,16 +19,8 @@ var (
 
 type authRelationshipRequestHandlerCtxKey struct{}
 
-func setAuthRelationshipRequestHandler(c echo.Context, requestHandler AuthRelationshipRequestHandler) {
-	req := c.Request().WithContext(
-		context.WithValue(
-			c.Request().Context(),
-			AuthRelationshipRequestHandlerCtxKey,
-			requestHandler,
-		),
-	)
-
-	c.SetRequest(req)
+func contextWithAuthRelationshipRequestHandler(ctx context.Context, requestHandler AuthRelationshipRequestHandler) context.Context {
+	return context.WithValue(ctx, AuthRelationshipRequestHandlerCtxKey, requestHandler)
 }
 
 // AuthRelationshipRequestHandler defines the required methods to create or update an auth relationship.
@@ -0,0 +1,89 @@
+package permissions
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+)
+
+// ResourceFunc resolves the resource ID a rule is checked against from the request.
+type ResourceFunc func(c echo.Context) (gidx.PrefixedID, error)
+
+// Rule defines an action which must be permitted on a resource for a request to proceed.
+type Rule struct {
+	Action   string
+	Resource ResourceFunc
+}
+
+// Require returns a rule requiring the action to be permitted on the resource resolved from the request.
+func Require(action string, resource ResourceFunc) Rule {
+	return Rule{
+		Action:   action,
+		Resource: resource,
+	}
+}
+
+// FromParam resolves the resource ID from the named path parameter.
+func FromParam(name string) ResourceFunc {
+	return func(c echo.Context) (gidx.PrefixedID, error) {
+		return parseRuleResource("path parameter", name, c.Param(name))
+	}
+}
+
+// FromQuery resolves the resource ID from the named query parameter.
+func FromQuery(name string) ResourceFunc {
+	return func(c echo.Context) (gidx.PrefixedID, error) {
+		return parseRuleResource("query parameter", name, c.QueryParam(name))
+	}
+}
+
+// FromID always resolves to the provided resource ID.
+func FromID(id gidx.PrefixedID) ResourceFunc {
+	return func(_ echo.Context) (gidx.PrefixedID, error) {
+		return id, nil
+	}
+}
+
+func parseRuleResource(source, name, value string) (gidx.PrefixedID, error) {
+	if value == "" {
+		return "", fmt.Errorf("%w: %s %s is empty", ErrInvalidRuleResource, source, name)
+	}
+
+	id, err := gidx.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %s: %w", ErrInvalidRuleResource, source, name, err)
+	}
+
+	return id, nil
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// checkRules checks all rules registered for the matched route along with the provided rules.
+func (p *Permissions) checkRules(c echo.Context, rules []Rule) error {
+	rules = slices.Concat(p.routeRules[routeKey(c.Request().Method, c.Path())], rules)
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	requests := make([]AccessRequest, len(rules))
+
+	for i, rule := range rules {
+		resourceID, err := rule.Resource(c)
+		if err != nil {
+			return echo.ErrBadRequest.WithInternal(err)
+		}
+
+		requests[i] = AccessRequest{
+			ResourceID: resourceID,
+			Action:     rule.Action,
+		}
+	}
+
+	return CheckAll(c.Request().Context(), requests...)
+}
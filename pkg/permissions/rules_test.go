@@ -0,0 +1,298 @@
+package permissions_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+)
+
+func TestMiddlewareRules(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			Actions []struct {
+				ResourceID string `json:"resource_id"`
+				Action     string `json:"action"`
+			} `json:"actions"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		for _, action := range reqBody.Actions {
+			if action.ResourceID != "loadbal-allowed" || action.Action != "loadbalancer_get" {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+		}
+	}))
+
+	defer srv.Close()
+
+	testCases := []struct {
+		name             string
+		options          []permissions.Option
+		rules            []permissions.Rule
+		path             string
+		expectStatusCode int
+	}{
+		{
+			"no rules",
+			nil,
+			nil,
+			"/loadbalancers/loadbal-denied",
+			http.StatusOK,
+		},
+		{
+			"middleware rule allowed",
+			nil,
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			"/loadbalancers/loadbal-allowed",
+			http.StatusOK,
+		},
+		{
+			"middleware rule denied",
+			nil,
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			"/loadbalancers/loadbal-denied",
+			http.StatusUnauthorized,
+		},
+		{
+			"route rule allowed",
+			[]permissions.Option{
+				permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", permissions.Require("loadbalancer_get", permissions.FromParam("id"))),
+			},
+			nil,
+			"/loadbalancers/loadbal-allowed",
+			http.StatusOK,
+		},
+		{
+			"route rule denied",
+			[]permissions.Option{
+				permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", permissions.Require("loadbalancer_get", permissions.FromParam("id"))),
+			},
+			nil,
+			"/loadbalancers/loadbal-denied",
+			http.StatusUnauthorized,
+		},
+		{
+			"route rule other method",
+			[]permissions.Option{
+				permissions.WithRouteRules(http.MethodDelete, "/loadbalancers/:id", permissions.Require("loadbalancer_delete", permissions.FromParam("id"))),
+			},
+			nil,
+			"/loadbalancers/loadbal-denied",
+			http.StatusOK,
+		},
+		{
+			"invalid resource",
+			nil,
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			"/loadbalancers/badid",
+			http.StatusBadRequest,
+		},
+		{
+			"query resource",
+			nil,
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromQuery("resource"))},
+			"/loadbalancers/loadbal-denied?resource=loadbal-allowed",
+			http.StatusOK,
+		},
+		{
+			"skipped",
+			[]permissions.Option{
+				permissions.WithSkipper(func(_ echo.Context) bool { return true }),
+			},
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			"/loadbalancers/loadbal-denied",
+			http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := permissions.New(permissions.Config{URL: srv.URL}, tc.options...)
+			require.NoError(t, err)
+
+			engine := echo.New()
+
+			engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set(echojwtx.ActorKey, "idntusr-abc123")
+
+					return next(c)
+				}
+			})
+
+			var nextCalled bool
+
+			handler := func(c echo.Context) error {
+				nextCalled = true
+
+				return c.NoContent(http.StatusOK)
+			}
+
+			engine.GET("/loadbalancers/:id", handler, perms.Middleware(tc.rules...))
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer some-token")
+
+			resp := httptest.NewRecorder()
+
+			engine.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectStatusCode, resp.Code, "unexpected response status code")
+			assert.Equal(t, tc.expectStatusCode == http.StatusOK, nextCalled, "unexpected handler call")
+		})
+	}
+}
+
+func TestMiddlewareRulesConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody struct {
+			Actions []struct {
+				ResourceID string `json:"resource_id"`
+				Action     string `json:"action"`
+			} `json:"actions"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		// each request must check exactly the route rules and its own middleware rule
+		if len(reqBody.Actions) != 4 {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		for _, action := range reqBody.Actions {
+			if action.ResourceID != reqBody.Actions[0].ResourceID {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+		}
+	}))
+
+	defer srv.Close()
+
+	// rules registered separately leave spare capacity in the route's rules
+	perms, err := permissions.New(permissions.Config{URL: srv.URL},
+		permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", permissions.Require("loadbalancer_get", permissions.FromParam("id"))),
+		permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", permissions.Require("loadbalancer_list", permissions.FromParam("id"))),
+		permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", permissions.Require("loadbalancer_status", permissions.FromParam("id"))),
+	)
+	require.NoError(t, err)
+
+	engine := echo.New()
+
+	engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(echojwtx.ActorKey, "idntusr-abc123")
+
+			return next(c)
+		}
+	})
+
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	engine.GET("/loadbalancers/:id", handler, perms.Middleware(permissions.Require("loadbalancer_update", permissions.FromParam("id"))))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/loadbalancers/loadbal-%d", i), nil)
+			req.Header.Set("Authorization", "Bearer some-token")
+
+			resp := httptest.NewRecorder()
+
+			engine.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code, "unexpected response status code")
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestMiddlewareRulesCheckerDisabled(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           permissions.Config
+		rules            []permissions.Rule
+		expectStatusCode int
+	}{
+		{
+			"no rules",
+			permissions.Config{},
+			nil,
+			http.StatusOK,
+		},
+		{
+			"default deny",
+			permissions.Config{},
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			http.StatusUnauthorized,
+		},
+		{
+			"default allow",
+			permissions.Config{DefaultAllow: true},
+			[]permissions.Rule{permissions.Require("loadbalancer_get", permissions.FromParam("id"))},
+			http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := permissions.New(tc.config,
+				permissions.WithRouteRules(http.MethodGet, "/loadbalancers/:id", tc.rules...),
+			)
+			require.NoError(t, err)
+
+			engine := echo.New()
+
+			var nextCalled bool
+
+			handler := func(c echo.Context) error {
+				nextCalled = true
+
+				return c.NoContent(http.StatusOK)
+			}
+
+			engine.GET("/loadbalancers/:id", handler, perms.Middleware())
+
+			req := httptest.NewRequest(http.MethodGet, "/loadbalancers/loadbal-abc123", nil)
+
+			resp := httptest.NewRecorder()
+
+			engine.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectStatusCode, resp.Code, "unexpected response status code")
+			assert.Equal(t, tc.expectStatusCode == http.StatusOK, nextCalled, "unexpected handler call")
+		})
+	}
+}